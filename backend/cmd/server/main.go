@@ -1,7 +1,150 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"mathprereq/internel/api/handlers"
+	"mathprereq/internel/container"
+	"mathprereq/internel/core/config"
+	"mathprereq/internel/server"
+	"mathprereq/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// shutdownTimeout bounds draining in-flight requests and then the container's background work
+const shutdownTimeout = 30 * time.Second
 
 func main() {
-	fmt.Println("Hello")
+	diagnose := flag.Bool("diagnose", false, "check every dependency, print a pass/fail report and exit")
+	flag.Parse()
+
+	if *diagnose {
+		os.Exit(runDiagnostics())
+	}
+
+	os.Exit(runServer())
+}
+
+// runServer serves the API until SIGINT or SIGTERM, then shuts the HTTP server and the
+// container down gracefully. It returns the process exit code.
+func runServer() int {
+	if err := logger.Initialize(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defer logger.Sync()
+	log := logger.MustGetLogger()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Error("Failed to load config", zap.Error(err))
+		return 1
+	}
+
+	c, err := container.NewContainer(cfg)
+	if err != nil {
+		log.Error("Failed to initialize container", zap.Error(err))
+		return 1
+	}
+
+	if cfg.Server.Environment == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	h := handlers.NewHandler(c.QueryService(), c.GetResourceScraper(), log)
+	handlers.RegisterRoutes(router, h, cfg.Server.AdminToken, cfg.Scraper.ReportRateLimit,
+		cfg.Server.RequestTimeout, cfg.Server.RouteTimeouts)
+
+	srv := server.NewServer(router, strconv.Itoa(cfg.Server.Port), log)
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := srv.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	exitCode := 0
+	select {
+	case <-signalCtx.Done():
+		log.Info("Shutdown signal received")
+	case err := <-serveErr:
+		log.Error("Server stopped unexpectedly", zap.Error(err))
+		exitCode = 1
+	}
+
+	// Stop taking requests first, then drain the container's background work
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("HTTP server shutdown failed", zap.Error(err))
+		exitCode = 1
+	}
+	if err := c.Shutdown(shutdownCtx); err != nil {
+		log.Error("Container shutdown failed", zap.Error(err))
+		exitCode = 1
+	}
+	return exitCode
+}
+
+// runDiagnostics prints the dependency report and returns the process exit code
+func runDiagnostics() int {
+	if err := logger.Initialize(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defer logger.Sync()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		return 1
+	}
+
+	c, err := container.NewContainer(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FAIL  startup: %v\n", err)
+		return 1
+	}
+
+	// Shutdown gets its own deadline so it still runs once the diagnostics run out of time
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := c.Shutdown(shutdownCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "shutdown: %v\n", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	report, err := c.RunDiagnostics(ctx)
+	if report != nil {
+		report.Print(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diagnostics interrupted: %v\n", err)
+		return 1
+	}
+	if !report.Passed {
+		return 1
+	}
+	return 0
 }
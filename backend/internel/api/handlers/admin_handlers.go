@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mathprereq/internel/domain/repositories"
+	"mathprereq/internel/types"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GenerateConceptDescriptions handles POST /admin/concepts/descriptions?limit=
+func (h *Handler) GenerateConceptDescriptions(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		h.respondError(c, http.StatusBadRequest, "limit must be a positive integer")
+		return
+	}
+
+	h.logger.Info("Concept description generation triggered",
+		zap.String("client_ip", c.ClientIP()),
+		zap.Int("limit", limit))
+
+	result, err := h.queryService.GenerateMissingConceptDescriptions(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.Error("Concept description generation failed", zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to generate concept descriptions")
+		return
+	}
+
+	h.respondSuccess(c, result)
+}
+
+// RebuildVectorStoreRequest optionally carries content to re-ingest after the wipe
+type RebuildVectorStoreRequest struct {
+	Content []types.VectorContent `json:"content" binding:"dive"`
+}
+
+// RebuildVectorStore handles POST /admin/vectorstore/rebuild?confirm=true
+func (h *Handler) RebuildVectorStore(c *gin.Context) {
+	if c.Query("confirm") != "true" {
+		h.respondError(c, http.StatusBadRequest, "rebuilding deletes all vector data; pass confirm=true to proceed")
+		return
+	}
+
+	var req RebuildVectorStoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.respondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	triggeredBy := c.ClientIP()
+	if operator := c.GetHeader("X-Operator"); operator != "" {
+		triggeredBy = operator + " (" + triggeredBy + ")"
+	}
+
+	result, err := h.queryService.RebuildVectorStore(c.Request.Context(), req.Content, triggeredBy)
+	if err != nil {
+		h.logger.Error("Vector store rebuild failed",
+			zap.String("triggered_by", triggeredBy),
+			zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to rebuild vector store")
+		return
+	}
+
+	h.respondSuccess(c, result)
+}
+
+// GetUsageSummary handles GET /admin/usage?since=2024-01-01 or ?days=30
+func (h *Handler) GetUsageSummary(c *gin.Context) {
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			parsed, err = time.Parse(time.DateOnly, raw)
+		}
+		if err != nil {
+			h.respondError(c, http.StatusBadRequest, "since must be an RFC3339 timestamp or YYYY-MM-DD date")
+			return
+		}
+		since = parsed
+	} else {
+		days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+		if err != nil || days <= 0 {
+			h.respondError(c, http.StatusBadRequest, "days must be a positive integer")
+			return
+		}
+		since = time.Now().UTC().AddDate(0, 0, -days)
+	}
+
+	summary, err := h.queryService.GetUsageSummary(c.Request.Context(), since)
+	if err != nil {
+		h.logger.Error("Usage summary failed", zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to get usage summary")
+		return
+	}
+
+	h.respondSuccess(c, summary)
+}
+
+// InvalidateCachesRequest optionally scopes invalidation to one concept
+type InvalidateCachesRequest struct {
+	ConceptID string `json:"concept_id"`
+}
+
+// InvalidateCaches handles POST /admin/cache/invalidate, for use after the graph is
+// edited outside the API
+func (h *Handler) InvalidateCaches(c *gin.Context) {
+	var req InvalidateCachesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.respondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.ConceptID == "" {
+		req.ConceptID = c.Query("concept_id")
+	}
+
+	triggeredBy := c.ClientIP()
+	if operator := c.GetHeader("X-Operator"); operator != "" {
+		triggeredBy = operator + " (" + triggeredBy + ")"
+	}
+
+	result, err := h.queryService.InvalidateCaches(c.Request.Context(), strings.TrimSpace(req.ConceptID), triggeredBy)
+	if err != nil {
+		h.logger.Error("Cache invalidation failed",
+			zap.String("triggered_by", triggeredBy),
+			zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to invalidate caches")
+		return
+	}
+
+	h.respondSuccess(c, result)
+}
+
+// GetRecentFailures handles GET /admin/failures?limit=, newest failures first
+func (h *Handler) GetRecentFailures(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 200 {
+		h.respondError(c, http.StatusBadRequest, "limit must be between 1 and 200")
+		return
+	}
+
+	failures, err := h.queryService.GetRecentFailures(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to list recent query failures", zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to list recent failures")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"failures": failures, "count": len(failures)})
+}
+
+// GetPrerequisiteSuggestions handles GET /admin/prerequisite-suggestions?limit=, listing
+// LLM-suggested prerequisites of concepts the graph had none for, most frequent first
+func (h *Handler) GetPrerequisiteSuggestions(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 500 {
+		h.respondError(c, http.StatusBadRequest, "limit must be between 1 and 500")
+		return
+	}
+
+	suggestions, err := h.queryService.GetPrerequisiteSuggestions(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to list prerequisite suggestions", zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to list prerequisite suggestions")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"suggestions": suggestions, "count": len(suggestions)})
+}
+
+// GraphQueryRequest carries a read-only Cypher query and its parameters
+type GraphQueryRequest struct {
+	Cypher string                 `json:"cypher" binding:"required"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// RunGraphQuery handles POST /admin/graph/query. Besides the admin token, the graph
+// client must have diagnostic queries enabled, and only read-only queries are run.
+func (h *Handler) RunGraphQuery(c *gin.Context) {
+	var req GraphQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	triggeredBy := c.ClientIP()
+	if operator := c.GetHeader("X-Operator"); operator != "" {
+		triggeredBy = operator + " (" + triggeredBy + ")"
+	}
+
+	rows, err := h.queryService.RunGraphDiagnosticQuery(c.Request.Context(), req.Cypher, req.Params, triggeredBy)
+	switch {
+	case errors.Is(err, repositories.ErrDiagnosticsDisabled):
+		h.respondError(c, http.StatusForbidden, err.Error())
+		return
+	case errors.Is(err, repositories.ErrWriteQuery), errors.Is(err, repositories.ErrInvalidQuery):
+		// Neo4j's statement error tells operators how to fix their query
+		h.respondError(c, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, context.DeadlineExceeded):
+		h.logger.Warn("Graph diagnostic query timed out", zap.Error(err))
+		h.respondError(c, http.StatusGatewayTimeout, "graph query timed out")
+		return
+	case err != nil:
+		h.logger.Error("Graph diagnostic query failed", zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "graph query failed")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"rows": rows, "count": len(rows)})
+}
+
+// GetVectorCoverageGaps handles GET /admin/vectorstore/gaps
+func (h *Handler) GetVectorCoverageGaps(c *gin.Context) {
+	gaps, err := h.queryService.GetVectorCoverageGaps(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Vector coverage gap detection failed", zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to detect vector coverage gaps")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"gaps": gaps, "count": len(gaps)})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mathprereq/internel/domain/repositories"
+	domainServices "mathprereq/internel/domain/services"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// graphQueryService is a query service whose diagnostic queries fail with err
+type graphQueryService struct {
+	domainServices.QueryService
+	err error
+}
+
+func (s *graphQueryService) RunGraphDiagnosticQuery(ctx context.Context, cypher string, params map[string]interface{}, triggeredBy string) ([]map[string]interface{}, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []map[string]interface{}{{"n": 1}}, nil
+}
+
+func TestRunGraphQueryStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "success", wantStatus: http.StatusOK},
+		{name: "disabled", err: repositories.ErrDiagnosticsDisabled, wantStatus: http.StatusForbidden},
+		{
+			name:        "write query",
+			err:         fmt.Errorf("%w: CREATE is not allowed", repositories.ErrWriteQuery),
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "CREATE is not allowed",
+		},
+		{
+			name:        "syntax error",
+			err:         fmt.Errorf("%w: Invalid input 'RETRUN'", repositories.ErrInvalidQuery),
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "Invalid input 'RETRUN'",
+		},
+		{
+			name:        "diagnostic timeout",
+			err:         fmt.Errorf("diagnostic query failed: %w", context.DeadlineExceeded),
+			wantStatus:  http.StatusGatewayTimeout,
+			wantMessage: "graph query timed out",
+		},
+		{
+			name:        "driver outage is not leaked",
+			err:         errors.New("diagnostic query failed: ConnectivityError: dial tcp 10.0.0.5:7687: connection refused"),
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "graph query failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&graphQueryService{err: tt.err}, nil, zap.NewNop())
+			router := gin.New()
+			router.POST("/graph/query", h.RunGraphQuery)
+
+			req := httptest.NewRequest(http.MethodPost, "/graph/query", strings.NewReader(`{"cypher": "MATCH (n) RETURN n"}`))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantMessage) {
+				t.Errorf("body = %s, want it to mention %q", rec.Body, tt.wantMessage)
+			}
+			if strings.Contains(rec.Body.String(), "10.0.0.5") {
+				t.Errorf("body leaks the driver error: %s", rec.Body)
+			}
+		})
+	}
+}
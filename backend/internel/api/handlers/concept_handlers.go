@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mathprereq/internel/domain/repositories"
+	"mathprereq/internel/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetConceptRelationship handles GET /concepts/relationship?from=&to=
+func (h *Handler) GetConceptRelationship(c *gin.Context) {
+	from := strings.TrimSpace(c.Query("from"))
+	to := strings.TrimSpace(c.Query("to"))
+	if from == "" || to == "" {
+		h.respondError(c, http.StatusBadRequest, "both 'from' and 'to' query parameters are required")
+		return
+	}
+
+	result, err := h.queryService.CheckPrerequisiteRelationship(c.Request.Context(), from, to)
+	if err != nil {
+		if errors.Is(err, repositories.ErrConceptNotFound) {
+			h.respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("Failed to check concept relationship",
+			zap.String("from", from),
+			zap.String("to", to),
+			zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to check concept relationship")
+		return
+	}
+
+	h.respondSuccess(c, result)
+}
+
+// SearchConcepts handles GET /concepts/search?q=&limit=
+func (h *Handler) SearchConcepts(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		h.respondError(c, http.StatusBadRequest, "'q' query parameter is required")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 50 {
+		h.respondError(c, http.StatusBadRequest, "limit must be between 1 and 50")
+		return
+	}
+
+	results, err := h.queryService.SearchConcepts(c.Request.Context(), query, limit)
+	if err != nil {
+		h.logger.Error("Concept search failed",
+			zap.String("query", query),
+			zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to search concepts")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"query": query, "results": results, "count": len(results)})
+}
+
+// GetPathGraph handles GET /path/graph?concepts=a,b (the parameter may also be repeated)
+func (h *Handler) GetPathGraph(c *gin.Context) {
+	var concepts []string
+	for _, value := range c.QueryArray("concepts") {
+		for _, concept := range strings.Split(value, ",") {
+			if concept = strings.TrimSpace(concept); concept != "" {
+				concepts = append(concepts, concept)
+			}
+		}
+	}
+	if len(concepts) == 0 {
+		h.respondError(c, http.StatusBadRequest, "'concepts' query parameter is required")
+		return
+	}
+
+	graph, err := h.queryService.GetPathGraph(c.Request.Context(), concepts)
+	if err != nil {
+		h.logger.Error("Failed to build path graph",
+			zap.Strings("concepts", concepts),
+			zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to build path graph")
+		return
+	}
+
+	h.respondSuccess(c, graph)
+}
+
+// GetConceptDetail handles GET /concepts/:id
+func (h *Handler) GetConceptDetail(c *gin.Context) {
+	conceptID := strings.TrimSpace(c.Param("id"))
+
+	detail, err := h.queryService.GetConceptDetail(c.Request.Context(), conceptID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrConceptNotFound) {
+			h.respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("Failed to get concept detail",
+			zap.String("concept_id", conceptID),
+			zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to get concept detail")
+		return
+	}
+
+	// A degraded detail is a stand-in for the graph's: never let clients revalidate it
+	if detail.Degraded {
+		c.Header("Cache-Control", "no-store")
+		h.respondSuccess(c, detail)
+		return
+	}
+
+	h.respondCacheable(c, detail, conceptDetailVersion(detail), detail.Concept.UpdatedAt)
+}
+
+// GetNextConcepts handles GET /concepts/:id/next?limit=
+func (h *Handler) GetNextConcepts(c *gin.Context) {
+	conceptID := strings.TrimSpace(c.Param("id"))
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		h.respondError(c, http.StatusBadRequest, "limit must be a positive integer")
+		return
+	}
+
+	concepts, err := h.queryService.GetNextConcepts(c.Request.Context(), conceptID, limit)
+	if err != nil {
+		if errors.Is(err, repositories.ErrConceptNotFound) {
+			h.respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("Failed to get next concepts",
+			zap.String("concept_id", conceptID),
+			zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to get next concepts")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"concept_id": conceptID, "next": concepts})
+}
+
+// GetCoStudiedConcepts handles GET /concepts/:id/co-studied?limit=. The segment is a
+// concept name as identified in queries (gin needs the wildcard named like its siblings).
+func (h *Handler) GetCoStudiedConcepts(c *gin.Context) {
+	conceptName := strings.TrimSpace(c.Param("id"))
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		h.respondError(c, http.StatusBadRequest, "limit must be between 1 and 100")
+		return
+	}
+
+	concepts, err := h.queryService.GetCoStudiedConcepts(c.Request.Context(), conceptName, limit)
+	if err != nil {
+		h.logger.Error("Failed to get co-studied concepts",
+			zap.String("concept", conceptName),
+			zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to get co-studied concepts")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"concept": conceptName, "co_studied": concepts, "count": len(concepts)})
+}
+
+// LearningGapRequest names a concept to learn and the concepts the learner already knows
+type LearningGapRequest struct {
+	TargetConcept string   `json:"target_concept" binding:"required"`
+	KnownConcepts []string `json:"known_concepts"`
+}
+
+// GetLearningGap handles POST /learning-gap
+func (h *Handler) GetLearningGap(c *gin.Context) {
+	var req LearningGapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	target := strings.TrimSpace(req.TargetConcept)
+	if target == "" {
+		h.respondError(c, http.StatusBadRequest, "'target_concept' is required")
+		return
+	}
+	known := make([]string, 0, len(req.KnownConcepts))
+	for _, concept := range req.KnownConcepts {
+		if concept = strings.TrimSpace(concept); concept != "" {
+			known = append(known, concept)
+		}
+	}
+
+	missing, err := h.queryService.GetMissingPrerequisites(c.Request.Context(), target, known)
+	if err != nil {
+		if errors.Is(err, repositories.ErrConceptNotFound) {
+			h.respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("Failed to compute learning gap",
+			zap.String("target", target),
+			zap.Strings("known", known),
+			zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to compute learning gap")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"target_concept": target, "known_concepts": known, "missing_prerequisites": missing})
+}
+
+// maxStudyGuideResources bounds the resources attached to each study guide step
+const maxStudyGuideResources = 10
+
+// GetStudyGuide handles GET /study-guide?concept=&resources_per_concept=
+func (h *Handler) GetStudyGuide(c *gin.Context) {
+	concept := strings.TrimSpace(c.Query("concept"))
+	if concept == "" {
+		h.respondError(c, http.StatusBadRequest, "'concept' query parameter is required")
+		return
+	}
+
+	perConcept, err := strconv.Atoi(c.DefaultQuery("resources_per_concept", "3"))
+	if err != nil || perConcept < 0 || perConcept > maxStudyGuideResources {
+		h.respondError(c, http.StatusBadRequest,
+			fmt.Sprintf("resources_per_concept must be between 0 and %d", maxStudyGuideResources))
+		return
+	}
+
+	guide, err := h.queryService.GetStudyGuide(c.Request.Context(), concept, perConcept)
+	if err != nil {
+		if errors.Is(err, repositories.ErrConceptNotFound) {
+			h.respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("Failed to build study guide", zap.String("concept", concept), zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to build study guide")
+		return
+	}
+
+	h.respondSuccess(c, guide)
+}
+
+// conceptDetailVersion captures the graph content of a concept detail. Timestamps are
+// left out: prerequisite edge writes bump updated_at, which Last-Modified reports.
+func conceptDetailVersion(detail *types.ConceptDetailResult) []string {
+	version := []string{detail.Concept.ID, detail.Concept.Name, detail.Concept.Description, detail.DetailedExplanation}
+	related := func(prefix string, concepts []types.Concept) {
+		entries := make([]string, 0, len(concepts))
+		for _, concept := range concepts {
+			entries = append(entries, prefix+concept.ID+"\x00"+concept.Name+"\x00"+concept.Description)
+		}
+		sort.Strings(entries)
+		version = append(version, entries...)
+	}
+	related("prereq:", detail.Prerequisites)
+	related("next:", detail.LeadsTo)
+	return version
+}
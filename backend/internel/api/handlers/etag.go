@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// computeETag returns a strong ETag for the JSON encoding of version, which should only
+// hold data that changes when the content does
+func computeETag(version interface{}) (string, error) {
+	encoded, err := json.Marshal(version)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag. Weak
+// validators match their strong counterparts, as allowed for GET requests.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// respondCacheable sends data with an ETag derived from version and answers 304 Not
+// Modified when the client already holds it. A zero lastModified omits Last-Modified.
+func (h *Handler) respondCacheable(c *gin.Context, data, version interface{}, lastModified time.Time) {
+	etag, err := computeETag(version)
+	if err != nil {
+		h.respondSuccess(c, data)
+		return
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	h.respondSuccess(c, data)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	scraper "mathprereq/internel/data/webscraper"
+	domainServices "mathprereq/internel/domain/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Handler serves the HTTP API on top of the domain services
+type Handler struct {
+	queryService    domainServices.QueryService
+	resourceScraper *scraper.EducationalWebScraper
+	logger          *zap.Logger
+}
+
+// APIResponse is the envelope returned by every endpoint
+type APIResponse struct {
+	Success   bool        `json:"success"`
+	Message   string      `json:"message,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	RequestID string      `json:"request_id"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+func NewHandler(queryService domainServices.QueryService, resourceScraper *scraper.EducationalWebScraper, logger *zap.Logger) *Handler {
+	return &Handler{
+		queryService:    queryService,
+		resourceScraper: resourceScraper,
+		logger:          logger,
+	}
+}
+
+// requestIDKey is the gin context key the request ID is kept under once assigned
+const requestIDKey = "request_id"
+
+// requestID returns the caller supplied request ID, or one generated on first use and
+// reused for the rest of the request
+func requestID(c *gin.Context) string {
+	if id := c.GetString(requestIDKey); id != "" {
+		return id
+	}
+	id := c.GetHeader("X-Request-ID")
+	if id == "" {
+		id = uuid.New().String()
+	}
+	c.Set(requestIDKey, id)
+	return id
+}
+
+func (h *Handler) respondSuccess(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusOK, APIResponse{
+		Success:   true,
+		Data:      data,
+		RequestID: requestID(c),
+		Timestamp: time.Now(),
+	})
+}
+
+func (h *Handler) respondError(c *gin.Context, status int, message string) {
+	// A failure caused by the route timeout cancelling downstream calls is a timeout
+	if status >= http.StatusInternalServerError && requestTimedOut(c) {
+		status, message = http.StatusGatewayTimeout, "request timed out"
+	}
+	c.JSON(status, APIResponse{
+		Success:   false,
+		Error:     message,
+		RequestID: requestID(c),
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// RequireAdminToken guards admin endpoints with a shared bearer token.
+// When no token is configured the admin endpoints are disabled entirely.
+func RequireAdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if provided == "" {
+			provided = c.GetHeader("X-Admin-Token")
+		}
+
+		if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, APIResponse{
+				Success:   false,
+				Error:     "admin authorization required",
+				RequestID: requestID(c),
+				Timestamp: time.Now(),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// clientLimiterIdleTTL is how long an idle client's limiter is kept before eviction
+const clientLimiterIdleTTL = time.Hour
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimitPerClient allows each client IP perHour requests per hour, with bursts of
+// up to burst requests. A non-positive perHour disables the limit.
+func RateLimitPerClient(perHour, burst int) gin.HandlerFunc {
+	if perHour <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+
+	var (
+		mu        sync.Mutex
+		clients   = make(map[string]*clientLimiter)
+		lastSweep = time.Now()
+		limit     = rate.Every(time.Hour / time.Duration(perHour))
+	)
+
+	return func(c *gin.Context) {
+		now := time.Now()
+
+		mu.Lock()
+		if now.Sub(lastSweep) > clientLimiterIdleTTL {
+			for ip, cl := range clients {
+				if now.Sub(cl.lastSeen) > clientLimiterIdleTTL {
+					delete(clients, ip)
+				}
+			}
+			lastSweep = now
+		}
+		cl, ok := clients[c.ClientIP()]
+		if !ok {
+			cl = &clientLimiter{limiter: rate.NewLimiter(limit, burst)}
+			clients[c.ClientIP()] = cl
+		}
+		cl.lastSeen = now
+		allowed := cl.limiter.Allow()
+		mu.Unlock()
+
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, APIResponse{
+				Success:   false,
+				Error:     "rate limit exceeded, try again later",
+				RequestID: requestID(c),
+				Timestamp: now,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// DefaultRequestTimeout bounds the handlers of routes without a configured timeout
+const DefaultRequestTimeout = 30 * time.Second
+
+// DefaultRouteTimeouts are the timeouts of routes that legitimately run long, keyed by
+// "METHOD /route/pattern" as registered (e.g. "GET /api/v1/concepts/:id")
+var DefaultRouteTimeouts = map[string]time.Duration{
+	"POST /api/v1/query":                                2 * time.Minute,
+	"POST /api/v1/learning-gap":                         time.Minute,
+	"GET /api/v1/study-guide":                           time.Minute,
+	"POST /api/v1/admin/concepts/descriptions":          10 * time.Minute,
+	"POST /api/v1/admin/vectorstore/rebuild":            10 * time.Minute,
+	"POST /api/v1/admin/resources/backfill-concept-ids": 10 * time.Minute,
+	"POST /api/v1/admin/resources/rescore":              10 * time.Minute,
+}
+
+// RequestTimeout cancels a handler's request context once its route's timeout passes:
+// timeouts["METHOD /pattern"] (over DefaultRouteTimeouts), else defaultTimeout. Exempt
+// routes, such as streaming endpoints, and non-positive timeouts run unbounded. A
+// handler that times out without writing a response gets 504.
+func RequestTimeout(defaultTimeout time.Duration, timeouts map[string]time.Duration, exempt ...string) gin.HandlerFunc {
+	if defaultTimeout <= 0 {
+		defaultTimeout = DefaultRequestTimeout
+	}
+
+	routeTimeouts := make(map[string]time.Duration, len(DefaultRouteTimeouts)+len(timeouts)+len(exempt))
+	for route, timeout := range DefaultRouteTimeouts {
+		routeTimeouts[route] = timeout
+	}
+	for route, timeout := range timeouts {
+		routeTimeouts[route] = timeout
+	}
+	for _, route := range exempt {
+		routeTimeouts[route] = 0
+	}
+
+	return func(c *gin.Context) {
+		timeout, ok := routeTimeouts[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			timeout = defaultTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, APIResponse{
+				Success:   false,
+				Error:     "request timed out",
+				RequestID: requestID(c),
+				Timestamp: time.Now(),
+			})
+		}
+	}
+}
+
+// requestTimedOut reports whether the request context hit its RequestTimeout deadline
+func requestTimedOut(c *gin.Context) bool {
+	return errors.Is(c.Request.Context().Err(), context.DeadlineExceeded)
+}
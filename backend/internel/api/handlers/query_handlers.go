@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	domainServices "mathprereq/internel/domain/services"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ProcessQuery handles POST /query
+func (h *Handler) ProcessQuery(c *gin.Context) {
+	var req domainServices.QueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	req.Question = strings.TrimSpace(req.Question)
+	if len(req.Question) < 3 {
+		h.respondError(c, http.StatusBadRequest, "question must be at least 3 characters")
+		return
+	}
+
+	if req.RequestID == "" {
+		req.RequestID = requestID(c)
+	} else {
+		c.Set(requestIDKey, req.RequestID)
+	}
+	if req.SessionID == "" {
+		req.SessionID = c.GetHeader("X-Session-ID")
+	}
+	req.UserAgent = c.Request.UserAgent()
+	req.IPAddress = c.ClientIP()
+
+	result, err := h.queryService.ProcessQuery(c.Request.Context(), &req)
+	if errors.Is(err, domainServices.ErrInvalidAudienceLevel) || errors.Is(err, domainServices.ErrInvalidOutputFormat) ||
+		errors.Is(err, domainServices.ErrInvalidMaxTokens) {
+		h.respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("Query processing failed",
+			zap.String("request_id", req.RequestID),
+			zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to process query")
+		return
+	}
+
+	h.respondSuccess(c, result)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	domainServices "mathprereq/internel/domain/services"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// recordingQueryService records the request ID of the query it is asked to process
+type recordingQueryService struct {
+	domainServices.QueryService
+	err       error
+	requestID string
+}
+
+func (s *recordingQueryService) ProcessQuery(ctx context.Context, req *domainServices.QueryRequest) (*domainServices.QueryResult, error) {
+	s.requestID = req.RequestID
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domainServices.QueryResult{}, nil
+}
+
+func TestProcessQueryRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		header string
+		body   string
+		err    error
+		want   string
+	}{
+		{name: "generated on success", body: `{"question": "what is a derivative"}`},
+		{name: "generated on failure", body: `{"question": "what is a derivative"}`, err: errors.New("llm down")},
+		{name: "from header", header: "req-header", body: `{"question": "what is a derivative"}`, want: "req-header"},
+		{
+			name: "from body",
+			body: `{"question": "what is a derivative", "request_id": "req-body"}`,
+			err:  errors.New("llm down"),
+			want: "req-body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &recordingQueryService{err: tt.err}
+			h := NewHandler(service, nil, zap.NewNop())
+			router := gin.New()
+			router.POST("/query", h.ProcessQuery)
+
+			req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set("X-Request-ID", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			var resp APIResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response %s: %v", rec.Body, err)
+			}
+			if resp.RequestID == "" || resp.RequestID != service.requestID {
+				t.Errorf("response request_id = %q, service saw %q; want the same non-empty ID", resp.RequestID, service.requestID)
+			}
+			if tt.want != "" && resp.RequestID != tt.want {
+				t.Errorf("request_id = %q, want %q", resp.RequestID, tt.want)
+			}
+		})
+	}
+}
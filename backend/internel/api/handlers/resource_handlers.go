@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	scraper "mathprereq/internel/data/webscraper"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// UpdateResourceRequest identifies a resource by URL and carries the fields to change
+type UpdateResourceRequest struct {
+	URL string `json:"url" binding:"required"`
+	scraper.ResourceUpdate
+}
+
+// UpdateResource handles PATCH /resources
+func (h *Handler) UpdateResource(c *gin.Context) {
+	if h.resourceScraper == nil {
+		h.respondError(c, http.StatusServiceUnavailable, "resource scraper not available")
+		return
+	}
+
+	var req UpdateResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	err := h.resourceScraper.UpdateResource(c.Request.Context(), strings.TrimSpace(req.URL), req.ResourceUpdate)
+	switch {
+	case errors.Is(err, scraper.ErrInvalidResourceUpdate):
+		h.respondError(c, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, scraper.ErrResourceNotFound):
+		h.respondError(c, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		h.logger.Error("Failed to update resource", zap.String("url", req.URL), zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to update resource")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"url": req.URL, "updated": true})
+}
+
+// ExportResources handles GET /admin/resources/export?format=json|csv with optional
+// concept_id, source_domain, resource_type and difficulty_level filters
+func (h *Handler) ExportResources(c *gin.Context) {
+	if h.resourceScraper == nil {
+		h.respondError(c, http.StatusServiceUnavailable, "resource scraper not available")
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", scraper.ExportFormatJSON))
+	var contentType, extension string
+	switch format {
+	case scraper.ExportFormatJSON:
+		contentType, extension = "application/x-ndjson", "ndjson"
+	case scraper.ExportFormatCSV:
+		contentType, extension = "text/csv; charset=utf-8", "csv"
+	default:
+		h.respondError(c, http.StatusBadRequest, "format must be json or csv")
+		return
+	}
+
+	filter := scraper.ResourceQueryFilter{
+		ConceptID:       c.Query("concept_id"),
+		SourceDomain:    c.Query("source_domain"),
+		ResourceType:    c.Query("resource_type"),
+		DifficultyLevel: c.Query("difficulty_level"),
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "attachment; filename=resources."+extension)
+	c.Status(http.StatusOK)
+
+	// Headers are already sent once streaming starts, so failures can only be logged
+	if err := h.resourceScraper.ExportResources(c.Request.Context(), filter, c.Writer, format); err != nil {
+		h.logger.Error("Resource export failed",
+			zap.String("format", format),
+			zap.Error(err))
+	}
+}
+
+// GetDifficultyDistribution handles GET /admin/resources/difficulty?concept_id=
+func (h *Handler) GetDifficultyDistribution(c *gin.Context) {
+	if h.resourceScraper == nil {
+		h.respondError(c, http.StatusServiceUnavailable, "resource scraper not available")
+		return
+	}
+
+	conceptID := strings.TrimSpace(c.Query("concept_id"))
+	distribution, err := h.resourceScraper.GetDifficultyDistribution(c.Request.Context(), conceptID)
+	if err != nil {
+		h.logger.Error("Failed to get difficulty distribution",
+			zap.String("concept_id", conceptID),
+			zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to get difficulty distribution")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{
+		"concept_id":   conceptID,
+		"distribution": distribution,
+	})
+}
+
+// ScrapeResources handles GET /admin/resources/scrape?concepts=a,b and streams
+// ScrapeProgress events as server-sent events until scraping finishes
+func (h *Handler) ScrapeResources(c *gin.Context) {
+	if h.resourceScraper == nil {
+		h.respondError(c, http.StatusServiceUnavailable, "resource scraper not available")
+		return
+	}
+
+	var concepts []string
+	for _, value := range c.QueryArray("concepts") {
+		for _, concept := range strings.Split(value, ",") {
+			if concept = strings.TrimSpace(concept); concept != "" {
+				concepts = append(concepts, concept)
+			}
+		}
+	}
+	if len(concepts) == 0 {
+		h.respondError(c, http.StatusBadRequest, "'concepts' query parameter is required")
+		return
+	}
+
+	// Cancelling on return unblocks the scraper if the client disconnects mid-stream
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	progress := make(chan scraper.ScrapeProgress)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- h.resourceScraper.ScrapeResourcesForConceptsWithProgress(ctx, concepts, progress)
+	}()
+
+	c.Stream(func(w io.Writer) bool {
+		event, ok := <-progress
+		if !ok {
+			return false
+		}
+		c.SSEvent("progress", event)
+		return true
+	})
+
+	if err := <-errCh; err != nil {
+		h.logger.Warn("Streaming scrape ended with error", zap.Error(err))
+		c.SSEvent("error", gin.H{"error": err.Error()})
+		return
+	}
+	c.SSEvent("complete", gin.H{"concepts": len(concepts)})
+}
+
+// ReportResourceRequest flags a resource as broken or wrong
+type ReportResourceRequest struct {
+	URL    string `json:"url" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ReportResource handles POST /resources/report
+func (h *Handler) ReportResource(c *gin.Context) {
+	if h.resourceScraper == nil {
+		h.respondError(c, http.StatusServiceUnavailable, "resource scraper not available")
+		return
+	}
+
+	var req ReportResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	err := h.resourceScraper.ReportResource(c.Request.Context(), strings.TrimSpace(req.URL), req.Reason)
+	switch {
+	case errors.Is(err, scraper.ErrInvalidResourceReport):
+		h.respondError(c, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, scraper.ErrResourceNotFound):
+		h.respondError(c, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		h.logger.Error("Failed to report resource", zap.String("url", req.URL), zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to report resource")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"url": req.URL, "reported": true})
+}
+
+// GetConceptResources handles GET /concepts/:id/resources?limit=&verified_only=
+func (h *Handler) GetConceptResources(c *gin.Context) {
+	if h.resourceScraper == nil {
+		h.respondError(c, http.StatusServiceUnavailable, "resource scraper not available")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		h.respondError(c, http.StatusBadRequest, "limit must be a positive integer")
+		return
+	}
+
+	verifiedOnly, err := strconv.ParseBool(c.DefaultQuery("verified_only", "false"))
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "verified_only must be true or false")
+		return
+	}
+
+	ctx := c.Request.Context()
+	conceptID := h.resourceScraper.ResolveConceptID(ctx, strings.TrimSpace(c.Param("id")))
+
+	resources, err := h.resourceScraper.GetResourcesForConcept(ctx, conceptID, limit, verifiedOnly)
+	if err != nil {
+		h.logger.Error("Failed to get concept resources",
+			zap.String("concept_id", conceptID),
+			zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to get concept resources")
+		return
+	}
+	if resources == nil {
+		resources = []scraper.EducationalResource{}
+	}
+
+	// Curator edits and reports do not touch scraped_at, so the ETag hashes the
+	// resources themselves while Last-Modified reports the newest scrape
+	var lastModified time.Time
+	for _, resource := range resources {
+		if resource.ScrapedAt.After(lastModified) {
+			lastModified = resource.ScrapedAt
+		}
+	}
+
+	data := gin.H{"concept_id": conceptID, "resources": resources}
+	h.respondCacheable(c, data, data, lastModified)
+}
+
+// maxResourceCountConcepts bounds the concept IDs accepted by one counts request
+const maxResourceCountConcepts = 200
+
+// GetResourceCounts handles GET /resources/counts?concepts=a,b and returns the number
+// of stored resources per concept ID, without the resource documents
+func (h *Handler) GetResourceCounts(c *gin.Context) {
+	if h.resourceScraper == nil {
+		h.respondError(c, http.StatusServiceUnavailable, "resource scraper not available")
+		return
+	}
+
+	var conceptIDs []string
+	seen := make(map[string]bool)
+	for _, value := range c.QueryArray("concepts") {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" && !seen[id] {
+				seen[id] = true
+				conceptIDs = append(conceptIDs, id)
+			}
+		}
+	}
+	if len(conceptIDs) == 0 {
+		h.respondError(c, http.StatusBadRequest, "'concepts' query parameter is required")
+		return
+	}
+	if len(conceptIDs) > maxResourceCountConcepts {
+		h.respondError(c, http.StatusBadRequest,
+			fmt.Sprintf("at most %d concepts can be counted per request", maxResourceCountConcepts))
+		return
+	}
+
+	counts, err := h.resourceScraper.GetResourceCounts(c.Request.Context(), conceptIDs)
+	if err != nil {
+		h.logger.Error("Failed to count resources", zap.Int("concepts", len(conceptIDs)), zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to count resources")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"counts": counts})
+}
+
+// BackfillConceptIDs handles POST /admin/resources/backfill-concept-ids
+func (h *Handler) BackfillConceptIDs(c *gin.Context) {
+	if h.resourceScraper == nil {
+		h.respondError(c, http.StatusServiceUnavailable, "resource scraper not available")
+		return
+	}
+
+	updated, err := h.resourceScraper.BackfillConceptIDs(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Concept ID backfill failed", zap.Int64("updated", updated), zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "concept ID backfill failed")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"updated": updated})
+}
+
+// RescoreResources handles POST /admin/resources/rescore, recomputing stored quality
+// scores after the scoring weights changed
+func (h *Handler) RescoreResources(c *gin.Context) {
+	if h.resourceScraper == nil {
+		h.respondError(c, http.StatusServiceUnavailable, "resource scraper not available")
+		return
+	}
+
+	updated, err := h.resourceScraper.RescoreAllResources(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Resource rescore failed", zap.Int64("updated", updated), zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "resource rescore failed")
+		return
+	}
+
+	h.respondSuccess(c, gin.H{"updated": updated})
+}
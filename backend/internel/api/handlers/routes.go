@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// streamingRoutes write their response as it is produced, so they run without a timeout
+var streamingRoutes = []string{
+	"GET /api/v1/admin/resources/export",
+	"GET /api/v1/admin/resources/scrape",
+}
+
+// RegisterRoutes mounts all API endpoints under /api/v1 and the Prometheus scrape endpoint.
+// reportsPerHour limits resource reports per client. Handlers are cancelled after
+// routeTimeouts["METHOD /pattern"] or requestTimeout (see RequestTimeout).
+func RegisterRoutes(router *gin.Engine, h *Handler, adminToken string, reportsPerHour int, requestTimeout time.Duration, routeTimeouts map[string]time.Duration) {
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	v1 := router.Group("/api/v1", RequestTimeout(requestTimeout, routeTimeouts, streamingRoutes...))
+
+	v1.POST("/query", h.ProcessQuery)
+	v1.GET("/stats", h.GetStats)
+	v1.GET("/path/graph", h.GetPathGraph)
+	v1.POST("/learning-gap", h.GetLearningGap)
+	v1.GET("/study-guide", h.GetStudyGuide)
+
+	concepts := v1.Group("/concepts")
+	{
+		concepts.GET("/relationship", h.GetConceptRelationship)
+		concepts.GET("/search", h.SearchConcepts)
+		concepts.GET("/:id", h.GetConceptDetail)
+		concepts.GET("/:id/resources", h.GetConceptResources)
+		concepts.GET("/:id/next", h.GetNextConcepts)
+		concepts.GET("/:id/co-studied", h.GetCoStudiedConcepts)
+	}
+
+	admin := v1.Group("/admin", RequireAdminToken(adminToken))
+	{
+		admin.POST("/concepts/descriptions", h.GenerateConceptDescriptions)
+		admin.POST("/vectorstore/rebuild", h.RebuildVectorStore)
+		admin.GET("/vectorstore/gaps", h.GetVectorCoverageGaps)
+		admin.GET("/resources/export", h.ExportResources)
+		admin.GET("/resources/difficulty", h.GetDifficultyDistribution)
+		admin.GET("/resources/scrape", h.ScrapeResources)
+		admin.POST("/resources/backfill-concept-ids", h.BackfillConceptIDs)
+		admin.POST("/resources/rescore", h.RescoreResources)
+		admin.GET("/usage", h.GetUsageSummary)
+		admin.GET("/failures", h.GetRecentFailures)
+		admin.GET("/prerequisite-suggestions", h.GetPrerequisiteSuggestions)
+		admin.POST("/cache/invalidate", h.InvalidateCaches)
+		admin.POST("/graph/query", h.RunGraphQuery)
+	}
+
+	v1.GET("/resources/counts", h.GetResourceCounts)
+	v1.PATCH("/resources", RequireAdminToken(adminToken), h.UpdateResource)
+	v1.POST("/resources/report", RateLimitPerClient(reportsPerHour, 3), h.ReportResource)
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetStats handles GET /stats; a partial report is still returned with 200
+func (h *Handler) GetStats(c *gin.Context) {
+	report, err := h.queryService.GetStatsReport(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to build stats report", zap.Error(err))
+		h.respondError(c, http.StatusInternalServerError, "failed to build stats report")
+		return
+	}
+
+	h.respondSuccess(c, report)
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"mathprereq/internel/core/config"
+	"mathprereq/internel/domain/entities"
+	"mathprereq/internel/domain/repositories"
+	"mathprereq/internel/types"
+
+	"go.uber.org/zap"
+)
+
+// failingConceptRepo is a concept repository whose detail lookup always fails
+type failingConceptRepo struct {
+	repositories.ConceptRepository
+	err error
+}
+
+func (r *failingConceptRepo) GetConceptDetail(ctx context.Context, conceptID string) (*types.ConceptDetailResult, error) {
+	return nil, r.err
+}
+
+// pathQueryRepo is a query repository serving FindByPathConceptID from fixed queries
+type pathQueryRepo struct {
+	repositories.QueryRepository
+	queries []*entities.Query
+	gotID   string
+}
+
+func (r *pathQueryRepo) FindByPathConceptID(ctx context.Context, conceptID string, limit int) ([]*entities.Query, error) {
+	r.gotID = conceptID
+	if len(r.queries) > limit {
+		return r.queries[:limit], nil
+	}
+	return r.queries, nil
+}
+
+// cachedPathQuery is a cached query whose path has the given prerequisites followed by
+// its target, the last ID
+func cachedPathQuery(explanation string, ids ...string) *entities.Query {
+	query := &entities.Query{Response: entities.QueryResponse{Explanation: explanation}}
+	for i, id := range ids {
+		role := types.ConceptRolePrerequisite
+		if i == len(ids)-1 {
+			role = types.ConceptRoleTarget
+		}
+		query.PrerequisitePath = append(query.PrerequisitePath, types.Concept{ID: id, Name: id, Type: role})
+	}
+	return query
+}
+
+// multiTargetQuery is a cached query about derivatives and chain_rule, where derivatives
+// is both a target and a prerequisite of chain_rule and integrals a target only
+func multiTargetQuery() *entities.Query {
+	query := &entities.Query{Response: entities.QueryResponse{Explanation: "multi"}}
+	query.PrerequisitePath = []types.Concept{
+		{ID: "limits", Type: types.ConceptRolePrerequisite, Roles: []string{types.ConceptRolePrerequisite}},
+		{ID: "integrals", Type: types.ConceptRoleTarget, Roles: []string{types.ConceptRoleTarget}},
+		{ID: "derivatives", Type: types.ConceptRoleTarget, Roles: []string{types.ConceptRoleTarget, types.ConceptRolePrerequisite}},
+		{ID: "chain_rule", Type: types.ConceptRoleTarget, Roles: []string{types.ConceptRoleTarget}},
+	}
+	return query
+}
+
+func TestGetConceptDetailFallback(t *testing.T) {
+	graphDown := errors.New("neo4j unavailable")
+
+	tests := []struct {
+		name        string
+		graphErr    error
+		queries     []*entities.Query
+		wantErr     bool
+		wantPrereqs []string
+		wantText    string
+		wantRoles   []string
+	}{
+		{
+			name:        "newest query contains the concept",
+			graphErr:    graphDown,
+			queries:     []*entities.Query{cachedPathQuery("newest", "limits", "derivatives", "chain_rule")},
+			wantPrereqs: []string{"limits", "derivatives"},
+			wantText:    "newest",
+			wantRoles:   []string{types.ConceptRoleNext},
+		},
+		{
+			name:        "other targets are not prerequisites",
+			graphErr:    graphDown,
+			queries:     []*entities.Query{multiTargetQuery()},
+			wantPrereqs: []string{"limits", "derivatives"},
+			wantText:    "multi",
+			wantRoles:   []string{types.ConceptRoleNext},
+		},
+		{
+			name:     "older query used when the newest path lacks the concept",
+			graphErr: graphDown,
+			queries: []*entities.Query{
+				cachedPathQuery("newest", "limits", "Chain Rule"),
+				cachedPathQuery("older", "functions", "chain_rule"),
+			},
+			wantPrereqs: []string{"functions"},
+			wantText:    "older",
+			wantRoles:   []string{types.ConceptRoleNext},
+		},
+		{
+			name:     "no cached query",
+			graphErr: graphDown,
+			wantErr:  true,
+		},
+		{
+			name:     "not found is not degraded",
+			graphErr: repositories.ErrConceptNotFound,
+			queries:  []*entities.Query{cachedPathQuery("newest", "chain_rule")},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryRepo := &pathQueryRepo{queries: tt.queries}
+			s := &queryService{
+				config:      config.QueryConfig{ConceptDetailFallbackEnabled: true},
+				conceptRepo: &failingConceptRepo{err: tt.graphErr},
+				queryRepo:   queryRepo,
+				logger:      zap.NewNop(),
+			}
+
+			detail, err := s.GetConceptDetail(context.Background(), "chain_rule")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", detail)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if queryRepo.gotID != "chain_rule" {
+				t.Errorf("looked up %q, want the concept ID", queryRepo.gotID)
+			}
+			if !detail.Degraded || detail.Concept.ID != "chain_rule" || detail.DetailedExplanation != tt.wantText {
+				t.Errorf("detail = %+v", detail)
+			}
+			if detail.Concept.HasRole(types.ConceptRoleTarget) || !reflect.DeepEqual(detail.Concept.Roles, tt.wantRoles) {
+				t.Errorf("subject roles = %v, want %v", detail.Concept.Roles, tt.wantRoles)
+			}
+			var prereqs []string
+			for _, concept := range detail.Prerequisites {
+				prereqs = append(prereqs, concept.ID)
+			}
+			if len(prereqs) != len(tt.wantPrereqs) {
+				t.Fatalf("prerequisites = %v, want %v", prereqs, tt.wantPrereqs)
+			}
+			for i := range prereqs {
+				if prereqs[i] != tt.wantPrereqs[i] {
+					t.Errorf("prerequisites = %v, want %v", prereqs, tt.wantPrereqs)
+				}
+			}
+		})
+	}
+}
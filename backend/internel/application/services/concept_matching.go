@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"mathprereq/internel/domain/services"
+	"mathprereq/internel/types"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// minSuggestionScore is the lowest similarity a concept needs to be offered as a suggestion
+const minSuggestionScore = 0.3
+
+// knownConceptsTTL is how long the concept list used for validation is reused
+const knownConceptsTTL = 5 * time.Minute
+
+type conceptMatch struct {
+	name  string
+	score float64
+}
+
+// resolveConceptName matches a requested concept name against the graph. It returns the
+// canonical name for an exact or close fuzzy match, or an UnknownConceptError listing
+// the closest known concepts. If the graph cannot be read the name is used as given.
+func (s *queryService) resolveConceptName(ctx context.Context, conceptName string) (string, error) {
+	concepts, err := s.listKnownConcepts(ctx)
+	if err != nil {
+		s.logger.Warn("Concept validation skipped, graph unavailable",
+			zap.String("concept", conceptName),
+			zap.Error(err))
+		return conceptName, nil
+	}
+
+	normalized := normalizeConceptName(conceptName)
+	matches := make([]conceptMatch, 0, len(concepts))
+	for _, concept := range concepts {
+		name := normalizeConceptName(concept.Name)
+		if name == normalized || normalizeConceptName(concept.ID) == normalized {
+			return concept.Name, nil
+		}
+		score := max(nameSimilarity(normalized, name), nameSimilarity(normalized, normalizeConceptName(concept.ID)))
+		matches = append(matches, conceptMatch{name: concept.Name, score: score})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	if len(matches) > 0 && matches[0].score >= s.config.ConceptFuzzyThreshold {
+		s.logger.Info("Concept name resolved by fuzzy match",
+			zap.String("requested", conceptName),
+			zap.String("canonical", matches[0].name),
+			zap.Float64("score", matches[0].score))
+		return matches[0].name, nil
+	}
+
+	suggestions := []string{}
+	for _, m := range matches {
+		if m.score < minSuggestionScore || len(suggestions) >= s.config.ConceptSuggestionLimit {
+			break
+		}
+		suggestions = append(suggestions, m.name)
+	}
+
+	return "", &services.UnknownConceptError{Concept: conceptName, Suggestions: suggestions}
+}
+
+// listKnownConcepts returns the graph's concepts, read at most once per knownConceptsTTL
+func (s *queryService) listKnownConcepts(ctx context.Context) ([]types.Concept, error) {
+	s.knownConceptsMu.Lock()
+	defer s.knownConceptsMu.Unlock()
+
+	if s.knownConcepts != nil && time.Since(s.knownConceptsAt) < knownConceptsTTL {
+		return s.knownConcepts, nil
+	}
+
+	concepts, err := s.conceptRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.knownConcepts = concepts
+	s.knownConceptsAt = time.Now()
+	return concepts, nil
+}
+
+func normalizeConceptName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer("_", " ", "-", " ").Replace(name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// nameSimilarity returns 1 minus the Levenshtein distance normalized by the longer length
+func nameSimilarity(a, b string) float64 {
+	longest := max(utf8.RuneCountInString(a), utf8.RuneCountInString(b))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(min(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"mathprereq/internel/types"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// Weights blending name and semantic similarity into a concept search score
+const (
+	searchGraphWeight  = 0.6
+	searchVectorWeight = 0.4
+
+	// minSearchNameScore is the lowest fuzzy name similarity counted as a graph match
+	minSearchNameScore = 0.6
+)
+
+// SearchConcepts finds concepts by name in the graph and by semantic similarity of the
+// query to vector store content, whose chunks are tagged with a concept. Both kinds of
+// match are merged per concept and ranked by a blended score; concepts found only in
+// the vector store are returned with InGraph false. Either source failing leaves the
+// other's results.
+func (s *queryService) SearchConcepts(ctx context.Context, query string, limit int) ([]types.ConceptSearchResult, error) {
+	normalized := normalizeConceptName(query)
+	if normalized == "" {
+		return []types.ConceptSearchResult{}, nil
+	}
+
+	var concepts []types.Concept
+	var vectorResults []types.VectorResult
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		if concepts, err = s.conceptRepo.GetAll(gCtx); err != nil {
+			s.logger.Warn("Concept search without graph matches", zap.Error(err))
+		}
+		return nil
+	})
+	g.Go(func() error {
+		var err error
+		if vectorResults, _, err = s.searchWithRetry(gCtx, query, limit*3); err != nil {
+			s.logger.Warn("Concept search without vector matches", zap.Error(err))
+		}
+		return nil
+	})
+	_ = g.Wait()
+
+	results := make(map[string]*types.ConceptSearchResult)
+	for _, concept := range concepts {
+		key := normalizeConceptName(concept.Name)
+		score := max(nameMatchScore(normalized, key), nameMatchScore(normalized, normalizeConceptName(concept.ID)))
+		results[key] = &types.ConceptSearchResult{Concept: concept, GraphScore: score, InGraph: true}
+		if id := normalizeConceptName(concept.ID); id != key {
+			results[id] = results[key]
+		}
+	}
+
+	for _, vr := range vectorResults {
+		key := normalizeConceptName(vr.Concept)
+		if key == "" {
+			continue
+		}
+		result, ok := results[key]
+		if !ok {
+			result = &types.ConceptSearchResult{Concept: types.Concept{Name: strings.TrimSpace(vr.Concept)}}
+			results[key] = result
+		}
+		result.VectorScore = max(result.VectorScore, vr.Score)
+	}
+
+	seen := make(map[*types.ConceptSearchResult]bool)
+	matches := []types.ConceptSearchResult{}
+	for _, result := range results {
+		if seen[result] {
+			continue
+		}
+		seen[result] = true
+		if result.GraphScore < minSearchNameScore {
+			result.GraphScore = 0
+		}
+		if result.GraphScore == 0 && result.VectorScore == 0 {
+			continue
+		}
+		result.Score = searchGraphWeight*result.GraphScore + searchVectorWeight*result.VectorScore
+		matches = append(matches, *result)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Concept.Name < matches[j].Concept.Name
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
+
+// nameMatchScore scores how well a normalized query matches a normalized concept name:
+// exact, prefix and word matches rank above fuzzy similarity
+func nameMatchScore(query, name string) float64 {
+	switch {
+	case name == "":
+		return 0
+	case query == name:
+		return 1
+	case strings.HasPrefix(name, query):
+		return 0.9
+	case strings.Contains(" "+name+" ", " "+query+" "):
+		return 0.8
+	case strings.Contains(name, query):
+		return 0.7
+	}
+	return nameSimilarity(query, name)
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"mathprereq/internel/types"
+)
+
+func contents(results []types.VectorResult) []string {
+	var out []string
+	for _, vr := range results {
+		out = append(out, vr.Content)
+	}
+	return out
+}
+
+func TestMergeRoundRobinKeepsEveryConceptAfterRerank(t *testing.T) {
+	// Limits batches: the derivative chunk scores highest but is off-topic
+	limits := []types.VectorResult{
+		{Content: "d1", Concept: "derivative", Score: 0.95},
+		{Content: "l1", Concept: "limits", Score: 0.80},
+		{Content: "l2", Concept: "limits", Score: 0.70},
+	}
+	series := []types.VectorResult{
+		{Content: "s1", Concept: "series", Score: 0.60},
+		{Content: "s2", Concept: "series", Score: 0.55},
+	}
+	concepts := []string{"limits", "series"}
+
+	tests := []struct {
+		name  string
+		boost float64
+		limit int
+		want  []string
+	}{
+		{"no boost", 0, 3, []string{"d1", "s1", "l1"}},
+		{"boost within batch", 0.3, 3, []string{"l1", "s1", "l2"}},
+		{"limit one", 0.3, 1, []string{"l1"}},
+		{"limit above candidates", 0.3, 10, []string{"l1", "s1", "l2", "s2", "d1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := [][]types.VectorResult{limits, series}
+			if tt.boost > 0 {
+				batches = [][]types.VectorResult{
+					rerankByConcept(limits, concepts, tt.boost),
+					rerankByConcept(series, concepts, tt.boost),
+				}
+			}
+			got := contents(mergeRoundRobin(batches, tt.limit))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeRoundRobinSkipsDuplicates(t *testing.T) {
+	batches := [][]types.VectorResult{
+		{{Content: "a"}, {Content: "b"}},
+		{{Content: "a"}, {Content: "c"}},
+	}
+	got := contents(mergeRoundRobin(batches, 10))
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("merged = %v, want %v", got, want)
+	}
+}
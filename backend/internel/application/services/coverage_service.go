@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mathprereq/internel/types"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// coverageBatchSize is the number of concepts counted per CountByConcept call
+	coverageBatchSize = 50
+	// coverageConcurrency bounds the CountByConcept calls in flight
+	coverageConcurrency = 4
+)
+
+// GetVectorCoverageGaps lists the names of graph concepts that have no chunks in the
+// vector store. A chunk covers a concept when its concept property matches the concept's
+// name or ID, ignoring case.
+func (s *queryService) GetVectorCoverageGaps(ctx context.Context) ([]string, error) {
+	concepts, err := s.conceptRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load graph concepts: %w", err)
+	}
+
+	var (
+		mu      sync.Mutex
+		covered = make(map[string]bool)
+	)
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(coverageConcurrency)
+
+	for start := 0; start < len(concepts); start += coverageBatchSize {
+		batch := concepts[start:min(start+coverageBatchSize, len(concepts))]
+		g.Go(func() error {
+			counts, err := s.vectorRepo.CountByConcept(gCtx, coverageKeys(batch))
+			if err != nil {
+				return fmt.Errorf("failed to count chunks by concept: %w", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for key, count := range counts {
+				if count > 0 {
+					covered[key] = true
+				}
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	gaps := []string{}
+	for _, concept := range concepts {
+		if !covered[strings.ToLower(concept.Name)] && !covered[strings.ToLower(concept.ID)] {
+			gaps = append(gaps, concept.Name)
+		}
+	}
+	sort.Strings(gaps)
+
+	s.logger.Info("Computed vector coverage gaps",
+		zap.Int("graph_concepts", len(concepts)),
+		zap.Int("gaps", len(gaps)))
+
+	return gaps, nil
+}
+
+// coverageKeys returns the values a chunk's concept property may hold for the concepts:
+// each name and ID as stored and lowercased
+func coverageKeys(concepts []types.Concept) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, concept := range concepts {
+		for _, key := range []string{concept.Name, strings.ToLower(concept.Name), concept.ID, strings.ToLower(concept.ID)} {
+			if key != "" && !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"mathprereq/internel/types"
+	"strings"
+)
+
+// parseExplanationBlocks splits an explanation into text and LaTeX math blocks, block
+// math delimited by $$...$$ and inline math by $...$, with \$ as a literal dollar.
+// Inline math follows the usual Markdown rules so prices are not mistaken for math: the
+// opening $ is followed by a non-space and the closing $ preceded by one and not
+// followed by a digit. It reports false when a delimiter is left unclosed; a lone $
+// before a digit is read as currency and does not count. The unclosed remainder is kept
+// as text.
+func parseExplanationBlocks(text string) ([]types.ExplanationBlock, bool) {
+	blocks := []types.ExplanationBlock{}
+	balanced := true
+
+	var pending strings.Builder
+	flushText := func() {
+		if pending.Len() > 0 {
+			blocks = append(blocks, types.ExplanationBlock{Type: types.BlockText, Content: pending.String()})
+			pending.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		switch {
+		case strings.HasPrefix(text[i:], `\$`):
+			pending.WriteByte('$')
+			i += 2
+
+		case strings.HasPrefix(text[i:], "$$"):
+			end := closingDelimiter(text, i+2, "$$")
+			if end < 0 {
+				balanced = false
+				pending.WriteString(text[i:])
+				i = len(text)
+				continue
+			}
+			flushText()
+			blocks = append(blocks, types.ExplanationBlock{
+				Type:    types.BlockMath,
+				Content: strings.TrimSpace(text[i+2 : end]),
+				Display: true,
+			})
+			i = end + 2
+
+		case text[i] == '$':
+			end := closingInlineDollar(text, i+1)
+			if end < 0 {
+				if !startsWithDigit(text[i+1:]) {
+					balanced = false
+				}
+				pending.WriteByte('$')
+				i++
+				continue
+			}
+			flushText()
+			blocks = append(blocks, types.ExplanationBlock{Type: types.BlockMath, Content: text[i+1 : end]})
+			i = end + 1
+
+		default:
+			pending.WriteByte(text[i])
+			i++
+		}
+	}
+	flushText()
+
+	return blocks, balanced
+}
+
+// closingDelimiter finds the next unescaped delimiter at or after start
+func closingDelimiter(text string, start int, delimiter string) int {
+	for i := start; i+len(delimiter) <= len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(text[i:], delimiter) {
+			return i
+		}
+	}
+	return -1
+}
+
+// closingInlineDollar finds the $ closing inline math opened just before start, or -1.
+// Inline math does not span a blank line.
+func closingInlineDollar(text string, start int) int {
+	if start >= len(text) || isSpace(text[start]) || text[start] == '$' {
+		return -1
+	}
+	for i := start; i < len(text); i++ {
+		switch {
+		case text[i] == '\\':
+			i++
+		case strings.HasPrefix(text[i:], "\n\n"):
+			return -1
+		case text[i] == '$':
+			if i > start && isSpace(text[i-1]) {
+				continue
+			}
+			if startsWithDigit(text[i+1:]) {
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
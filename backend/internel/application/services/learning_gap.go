@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mathprereq/internel/domain/repositories"
+	"mathprereq/internel/types"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// GetMissingPrerequisites returns the prerequisites of targetConcept the learner still
+// needs, in an order where every concept comes after its own prerequisites. Knowing a
+// concept implies knowing everything it depends on, so the known concepts and all of
+// their prerequisites are left out, as is the target itself. Known concept names that
+// do not resolve in the graph are ignored.
+func (s *queryService) GetMissingPrerequisites(ctx context.Context, targetConcept string, knownConcepts []string) ([]types.Concept, error) {
+	targetIDs, err := s.conceptRepo.ResolveIDs(ctx, []string{targetConcept})
+	if err != nil {
+		return nil, err
+	}
+	targetID, ok := targetIDs[targetConcept]
+	if !ok || targetID == "" {
+		return nil, fmt.Errorf("%w: %s", repositories.ErrConceptNotFound, targetConcept)
+	}
+
+	graph, err := s.conceptRepo.GetPathGraph(ctx, []string{targetID})
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	if len(knownConcepts) > 0 {
+		knownIDs, err := s.conceptRepo.ResolveIDs(ctx, knownConcepts)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range knownIDs {
+			if id != "" {
+				known[id] = true
+			}
+		}
+	}
+
+	// Every prerequisite of a concept in the target's subgraph is in the subgraph too,
+	// so the known set can be closed over its edges alone
+	prerequisitesOf := make(map[string][]string)
+	for _, edge := range graph.Edges {
+		prerequisitesOf[edge.Target] = append(prerequisitesOf[edge.Target], edge.Source)
+	}
+	pending := make([]string, 0, len(known))
+	for id := range known {
+		pending = append(pending, id)
+	}
+	for len(pending) > 0 {
+		id := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, prereq := range prerequisitesOf[id] {
+			if !known[prereq] {
+				known[prereq] = true
+				pending = append(pending, prereq)
+			}
+		}
+	}
+
+	var missing []types.Concept
+	for _, concept := range graph.Nodes {
+		if concept.ID != targetID && !known[concept.ID] {
+			missing = append(missing, concept)
+		}
+	}
+	missing = topologicalOrder(missing, graph.Edges)
+
+	s.logger.Info("Computed learning gap",
+		zap.String("target", targetConcept),
+		zap.Int("known", len(knownConcepts)),
+		zap.Int("missing", len(missing)))
+
+	return missing, nil
+}
+
+// topologicalOrder sorts concepts so each one follows its prerequisites, breaking ties
+// by name. Edges to concepts outside the set are ignored; concepts left on a cycle are
+// appended by name.
+func topologicalOrder(concepts []types.Concept, edges []types.ConceptEdge) []types.Concept {
+	byID := make(map[string]types.Concept, len(concepts))
+	for _, concept := range concepts {
+		byID[concept.ID] = concept
+	}
+
+	inDegree := make(map[string]int, len(concepts))
+	dependents := make(map[string][]string)
+	for _, edge := range edges {
+		_, sourceIn := byID[edge.Source]
+		_, targetIn := byID[edge.Target]
+		if sourceIn && targetIn {
+			inDegree[edge.Target]++
+			dependents[edge.Source] = append(dependents[edge.Source], edge.Target)
+		}
+	}
+
+	byName := func(ids []string) {
+		sort.Slice(ids, func(i, j int) bool {
+			if byID[ids[i]].Name != byID[ids[j]].Name {
+				return byID[ids[i]].Name < byID[ids[j]].Name
+			}
+			return ids[i] < ids[j]
+		})
+	}
+
+	var ready []string
+	for id := range byID {
+		if inDegree[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+	byName(ready)
+
+	ordered := make([]types.Concept, 0, len(concepts))
+	placed := make(map[string]bool, len(concepts))
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, byID[id])
+		placed[id] = true
+
+		for _, next := range dependents[id] {
+			if inDegree[next]--; inDegree[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+		byName(ready)
+	}
+
+	if len(ordered) < len(byID) {
+		var cyclic []string
+		for id := range byID {
+			if !placed[id] {
+				cyclic = append(cyclic, id)
+			}
+		}
+		byName(cyclic)
+		for _, id := range cyclic {
+			ordered = append(ordered, byID[id])
+		}
+	}
+
+	return ordered
+}
@@ -17,13 +17,64 @@ func (a *LLMAdapter) IdentifyConcepts(ctx context.Context, query string) ([]stri
 	return a.client.IdentifyConcepts(ctx, query)
 }
 
-func (a *LLMAdapter) GenerateExplanation(ctx context.Context, req ExplanationRequest) (string, error) {
+func (a *LLMAdapter) IdentifyConceptsDetailed(ctx context.Context, query string) ([]IdentifiedConcept, error) {
+	concepts, err := a.client.IdentifyConceptsDetailed(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	identified := make([]IdentifiedConcept, len(concepts))
+	for i, concept := range concepts {
+		identified[i] = IdentifiedConcept{
+			Name:       concept.Name,
+			Normalized: concept.Normalized,
+			ID:         concept.ID,
+			Resolved:   concept.Resolved,
+		}
+	}
+	return identified, nil
+}
+
+func (a *LLMAdapter) IdentifyConceptsExplicit(ctx context.Context, query string, minConcepts int) ([]string, error) {
+	return a.client.IdentifyConceptsExplicit(ctx, query, minConcepts)
+}
+
+func (a *LLMAdapter) GenerateExplanation(ctx context.Context, req ExplanationRequest) (*ExplanationResult, error) {
 	llmReq := llm.ExplanationRequest{
 		Query:            req.Query,
 		PrerequisitePath: req.PrerequisitePath,
 		ContextChunks:    req.ContextChunks,
+		AudienceLevel:    req.AudienceLevel,
+		OutputFormat:     req.OutputFormat,
+		LowCoverage:      req.LowCoverage,
+		MaxTokens:        req.MaxTokens,
+
+		SuggestedPrerequisites: req.SuggestedPrerequisites,
 	}
-	return a.client.GenerateExplanation(ctx, llmReq)
+	result, err := a.client.GenerateExplanation(ctx, llmReq)
+	if err != nil {
+		return nil, err
+	}
+	return &ExplanationResult{
+		Explanation:         result.Explanation,
+		CitedContextIndices: result.CitedContextIndices,
+		MaxTokens:           result.MaxTokens,
+		Truncated:           result.Truncated,
+		FullExplanation:     result.FullExplanation,
+		Model:               result.Model,
+	}, nil
+}
+
+func (a *LLMAdapter) MaxOutputTokens() int {
+	return a.client.MaxOutputTokens()
+}
+
+func (a *LLMAdapter) GenerateConceptDescription(ctx context.Context, conceptName string) (string, error) {
+	return a.client.GenerateConceptDescription(ctx, conceptName)
+}
+
+func (a *LLMAdapter) SuggestPrerequisites(ctx context.Context, conceptName string) ([]string, error) {
+	return a.client.SuggestPrerequisites(ctx, conceptName)
 }
 
 func (a *LLMAdapter) Provider() string {
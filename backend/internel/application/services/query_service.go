@@ -2,43 +2,121 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"mathprereq/internel/core/config"
+	"mathprereq/internel/core/llm"
 	scraper "mathprereq/internel/data/webscraper"
 	"mathprereq/internel/domain/entities"
 	"mathprereq/internel/domain/repositories"
 	"mathprereq/internel/domain/services"
 	"mathprereq/internel/types"
+	"mathprereq/pkg/logger"
+	"mathprereq/pkg/metrics"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
 )
 
+const (
+	descriptionBatchSize       = 10
+	descriptionRequestInterval = 2 * time.Second
+
+	// resourceFetchConcurrency bounds the per-concept resource lookups in flight
+	resourceFetchConcurrency = 4
+)
+
+// defaultConceptDenylist holds generic terms the LLM returns that never resolve to a
+// useful graph concept
+var defaultConceptDenylist = []string{
+	"mathematics", "math", "maths", "education", "learning", "concepts", "problem solving",
+}
+
 type queryService struct {
+	config          config.QueryConfig
 	conceptRepo     repositories.ConceptRepository
 	queryRepo       repositories.QueryRepository
 	vectorRepo      repositories.VectorRepository
 	llmClient       LLMClient
 	resourceScraper *scraper.EducationalWebScraper
 	logger          *zap.Logger
+
+	// scrapeSlots holds one token per running query-triggered scrape job
+	scrapeSlots chan struct{}
+
+	// knownConcepts caches the graph's concepts for concept name validation
+	knownConceptsMu sync.Mutex
+	knownConcepts   []types.Concept
+	knownConceptsAt time.Time
+
+	// baseCtx is cancelled on shutdown; background scrapes derive from it and
+	// background tracks every goroutine that may still use the repositories
+	baseCtx    context.Context
+	background sync.WaitGroup
 }
 
 // LLMClient interface for the service layer
 type LLMClient interface {
 	IdentifyConcepts(ctx context.Context, query string) ([]string, error)
-	GenerateExplanation(ctx context.Context, req ExplanationRequest) (string, error)
+	IdentifyConceptsDetailed(ctx context.Context, query string) ([]IdentifiedConcept, error)
+	IdentifyConceptsExplicit(ctx context.Context, query string, minConcepts int) ([]string, error)
+	GenerateExplanation(ctx context.Context, req ExplanationRequest) (*ExplanationResult, error)
+	GenerateConceptDescription(ctx context.Context, conceptName string) (string, error)
+	SuggestPrerequisites(ctx context.Context, conceptName string) ([]string, error)
+	MaxOutputTokens() int
 	Provider() string
 	Model() string
 	IsHealthy(ctx context.Context) bool
 }
 
+// IdentifiedConcept is a concept extracted from a query with its normalized name and,
+// when Resolved, its graph ID (empty when the concept is not in the graph)
+type IdentifiedConcept struct {
+	Name       string `json:"name"`
+	Normalized string `json:"normalized"`
+	ID         string `json:"id,omitempty"`
+	Resolved   bool   `json:"resolved"`
+}
+
 type ExplanationRequest struct {
 	Query            string          `json:"query"`
 	PrerequisitePath []types.Concept `json:"prerequisite_path"`
 	ContextChunks    []string        `json:"context_chunks"`
+	AudienceLevel    string          `json:"audience_level"`
+	OutputFormat     string          `json:"output_format"`
+	// LowCoverage asks for an explanation that flags it is not grounded in course material
+	LowCoverage bool `json:"low_coverage"`
+	// MaxTokens lowers the output token limit (0 uses the configured limit)
+	MaxTokens int `json:"max_tokens,omitempty"`
+	// SuggestedPrerequisites are LLM-suggested, unverified prerequisites of concepts the
+	// graph has none for
+	SuggestedPrerequisites map[string][]string `json:"suggested_prerequisites,omitempty"`
+}
+
+// ExplanationResult is an explanation, the zero-based indices of the context chunks it
+// cited and the output token limit it was generated with. Truncated marks an
+// explanation trimmed for length; FullExplanation keeps the original when configured.
+type ExplanationResult struct {
+	Explanation         string `json:"explanation"`
+	CitedContextIndices []int  `json:"cited_context_indices"`
+	MaxTokens           int    `json:"max_tokens"`
+	Truncated           bool   `json:"truncated"`
+	FullExplanation     string `json:"full_explanation,omitempty"`
+	// Model is the model that generated the explanation
+	Model string `json:"model"`
 }
 
 func NewQueryService(
+	ctx context.Context,
+	cfg config.QueryConfig,
 	conceptRepo repositories.ConceptRepository,
 	queryRepo repositories.QueryRepository,
 	vectorRepo repositories.VectorRepository,
@@ -46,31 +124,151 @@ func NewQueryService(
 	resourceScraper *scraper.EducationalWebScraper,
 	logger *zap.Logger,
 ) services.QueryService {
+	if cfg.VectorSearchAttempts <= 0 {
+		cfg.VectorSearchAttempts = 1
+	}
+	if cfg.StatsBackendTimeout <= 0 {
+		cfg.StatsBackendTimeout = 2 * time.Second
+	}
+	if cfg.StatsDeadline <= 0 {
+		cfg.StatsDeadline = 5 * time.Second
+	}
+	if cfg.StatsConcurrency <= 0 {
+		cfg.StatsConcurrency = 4
+	}
+	if len(cfg.ConceptDenylist) == 0 {
+		cfg.ConceptDenylist = defaultConceptDenylist
+	}
+	if cfg.VectorResultsPerConcept <= 0 {
+		cfg.VectorResultsPerConcept = 3
+	}
+	if cfg.MaxContextChunks <= 0 {
+		cfg.MaxContextChunks = 8
+	}
+	if cfg.LowCoverageMinScore <= 0 || cfg.LowCoverageMinScore > 1 {
+		cfg.LowCoverageMinScore = 0.7
+	}
+	if cfg.ExplanationCacheMaxAge <= 0 {
+		cfg.ExplanationCacheMaxAge = 30 * 24 * time.Hour
+	}
+	if cfg.ConceptFuzzyThreshold <= 0 || cfg.ConceptFuzzyThreshold > 1 {
+		cfg.ConceptFuzzyThreshold = 0.8
+	}
+	if cfg.ConceptSuggestionLimit <= 0 {
+		cfg.ConceptSuggestionLimit = 5
+	}
+	if cfg.ConceptFallbackMaxConcepts <= 0 {
+		cfg.ConceptFallbackMaxConcepts = 5
+	}
+	if cfg.SuggestPrerequisitesMaxConcepts <= 0 {
+		cfg.SuggestPrerequisitesMaxConcepts = 3
+	}
+
+	if cfg.StepRetries < 0 {
+		cfg.StepRetries = 0
+	}
+	if cfg.StepRetryBackoff <= 0 {
+		cfg.StepRetryBackoff = 500 * time.Millisecond
+	}
+
+	if cfg.MaxBackgroundScrapes <= 0 {
+		cfg.MaxBackgroundScrapes = 3
+	}
+
 	return &queryService{
+		config:          cfg,
 		conceptRepo:     conceptRepo,
 		queryRepo:       queryRepo,
 		vectorRepo:      vectorRepo,
 		llmClient:       llmClient,
 		resourceScraper: resourceScraper,
 		logger:          logger,
+		scrapeSlots:     make(chan struct{}, cfg.MaxBackgroundScrapes),
+		baseCtx:         ctx,
+	}
+}
+
+// log returns the service logger, limited to warnings and errors for requests that
+// request log sampling left out
+func (s *queryService) log(ctx context.Context) *zap.Logger {
+	return logger.Sampled(ctx, s.logger)
+}
+
+// goBackground runs fn in a goroutine that Drain waits for
+func (s *queryService) goBackground(fn func()) {
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		fn()
+	}()
+}
+
+// Drain waits for background saves and scrapes to finish, or for ctx to end. Cancel
+// the service's base context first so running scrapes stop early.
+func (s *queryService) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background work still running: %w", ctx.Err())
 	}
 }
 
 func (s *queryService) ProcessQuery(ctx context.Context, req *services.QueryRequest) (*services.QueryResult, error) {
 	startTime := time.Now()
 
+	if req.AudienceLevel == "" {
+		req.AudienceLevel = entities.DefaultAudienceLevel
+	}
+	if !entities.IsValidAudienceLevel(req.AudienceLevel) {
+		return nil, fmt.Errorf("%w: %s", services.ErrInvalidAudienceLevel, req.AudienceLevel)
+	}
+	if req.OutputFormat == "" {
+		req.OutputFormat = entities.DefaultOutputFormat
+	}
+	if !entities.IsValidOutputFormat(req.OutputFormat) {
+		return nil, fmt.Errorf("%w: %s", services.ErrInvalidOutputFormat, req.OutputFormat)
+	}
+	if ceiling := s.llmClient.MaxOutputTokens(); req.MaxTokens < 0 || req.MaxTokens > ceiling {
+		return nil, fmt.Errorf("%w: %d is outside 1-%d", services.ErrInvalidMaxTokens, req.MaxTokens, ceiling)
+	}
+
 	// Create query entity
-	query := entities.NewQuery(req.UserID, req.Question, "")
+	query := entities.NewQuery(req.UserID, req.Question, req.RequestID)
+	query.AudienceLevel = req.AudienceLevel
+	query.OutputFormat = req.OutputFormat
+	query.MaxTokens = req.MaxTokens
+	query.SessionID = req.SessionID
+	query.UserAgent = req.UserAgent
+	query.IPAddress = req.IPAddress
+
+	samplingKey := req.RequestID
+	if samplingKey == "" {
+		samplingKey = query.ID
+	}
+	ctx = logger.WithRequestSampling(ctx, samplingKey)
 
-	s.logger.Info("Processing query",
+	s.log(ctx).Info("Processing query",
 		zap.String("query_id", query.ID),
 		zap.String("question", req.Question[:min(len(req.Question), 100)]))
 
-	// Process through pipeline
-	result, err := s.processQueryPipeline(ctx, query)
+	// Process through pipeline, recording the LLM tokens it consumes
+	pipelineCtx, usage := llm.WithUsageTracker(ctx)
+	result, err := s.processQueryPipeline(pipelineCtx, query)
+	query.Response.TokensUsed = usage.Tokens()
+	if models := usage.Models(); len(models) > 0 {
+		query.Metadata.Models = models
+	}
 
 	// Always save query (success or failure)
 	query.MarkCompleted(err == nil, err)
+	s.recordQueryMetrics(query)
 	s.saveQueryAsync(ctx, query)
 
 	if err != nil {
@@ -81,8 +279,9 @@ func (s *queryService) ProcessQuery(ctx context.Context, req *services.QueryRequ
 	}
 
 	result.ProcessingTime = time.Since(startTime)
+	result.RequestID = req.RequestID
 
-	s.logger.Info("Query processed successfully",
+	s.log(ctx).Info("Query processed successfully",
 		zap.String("query_id", query.ID),
 		zap.Duration("processing_time", result.ProcessingTime))
 
@@ -93,20 +292,65 @@ func (s *queryService) processQueryPipeline(ctx context.Context, query *entities
 	var result = &services.QueryResult{Query: query}
 
 	// Step 1: Extract concepts
-	stepStart := time.Now()
-	conceptNames, err := s.llmClient.IdentifyConcepts(ctx, query.Text)
-	query.AddProcessingStep("identify_concepts", time.Since(stepStart), err == nil, err)
+	var identified []IdentifiedConcept
+	err := s.runStep(ctx, query, "identify_concepts", func() error {
+		var err error
+		identified, err = s.llmClient.IdentifyConceptsDetailed(ctx, query.Text)
+		return err
+	})
+
+	// knownIDs holds the graph lookups already made during extraction, "" for concepts
+	// not in the graph, so filtering does not resolve them again
+	conceptNames := make([]string, len(identified))
+	knownIDs := make(map[string]string, len(identified))
+	for i, concept := range identified {
+		conceptNames[i] = concept.Name
+		if concept.Resolved {
+			knownIDs[concept.Name] = concept.ID
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("concept identification failed: %w", err)
+		if !s.config.ConceptFallbackEnabled {
+			metrics.ConceptExtractions.WithLabelValues(metrics.ConceptSourceFailed).Inc()
+			return nil, fmt.Errorf("concept identification failed: %w", err)
+		}
+
+		s.logger.Warn("Concept identification failed, falling back to vector search",
+			zap.String("query_id", query.ID),
+			zap.Error(err))
+
+		conceptNames, err = s.identifyConceptsFromVectors(ctx, query)
+		if err != nil {
+			metrics.ConceptExtractions.WithLabelValues(metrics.ConceptSourceFailed).Inc()
+			return nil, fmt.Errorf("concept identification failed: %w", err)
+		}
+		query.Metadata.ConceptSource = metrics.ConceptSourceVectorFallback
+		result.Degraded = true
+	} else {
+		query.Metadata.ConceptSource = metrics.ConceptSourceLLM
+		conceptNames = s.ensureMinimumConcepts(ctx, query, conceptNames)
 	}
+	metrics.ConceptExtractions.WithLabelValues(query.Metadata.ConceptSource).Inc()
+
+	conceptNames = s.filterIdentifiedConcepts(ctx, query, conceptNames, knownIDs)
 
 	query.IdentifiedConcepts = conceptNames
 	result.IdentifiedConcepts = conceptNames
 
+	// Path finding falls back to the raw query when no concepts could be identified
+	pathTargets := conceptNames
+	if len(pathTargets) == 0 {
+		pathTargets = []string{query.Text}
+	}
+
 	// Step 2: Find prerequisite path
-	stepStart = time.Now()
-	prereqPath, err := s.conceptRepo.FindPrerequisitePath(ctx, conceptNames)
-	query.AddProcessingStep("find_prerequisites", time.Since(stepStart), err == nil, err)
+	var prereqPath []types.Concept
+	var unresolved []string
+	err = s.runStep(ctx, query, "find_prerequisites", func() error {
+		var err error
+		prereqPath, unresolved, err = s.conceptRepo.FindPrerequisitePath(ctx, pathTargets)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("prerequisite path finding failed: %w", err)
 	}
@@ -114,51 +358,642 @@ func (s *queryService) processQueryPipeline(ctx context.Context, query *entities
 	query.PrerequisitePath = prereqPath
 	result.PrerequisitePath = prereqPath
 
+	// The raw query text standing in for concepts is not worth reporting as unknown
+	if len(conceptNames) > 0 && len(unresolved) > 0 {
+		query.Metadata.UnresolvedConcepts = unresolved
+		result.UnresolvedConcepts = unresolved
+		s.log(ctx).Info("Identified concepts missing from the knowledge graph",
+			zap.String("query_id", query.ID),
+			zap.Strings("unresolved", unresolved))
+	}
+
+	suggested := s.suggestMissingPrerequisites(ctx, query, conceptNames, result.UnresolvedConcepts, prereqPath)
+	result.SuggestedPrerequisites = suggested
+
 	// Step 3: Start background resource scraping for concepts (non-blocking)
 	if s.resourceScraper != nil && len(conceptNames) > 0 {
-		go s.scrapeResourcesAsync(ctx, conceptNames, query.ID)
+		s.goBackground(func() { s.scrapeResourcesAsync(ctx, conceptNames, query.ID) })
 	}
 
-	// Step 4: Vector search
-	stepStart = time.Now()
-	vectorResults, err := s.vectorRepo.Search(ctx, query.Text, 5)
+	// Step 4: Vector search, one query per concept so each contributes context
+	stepStart := time.Now()
+	vectorResults, attempts, err := s.retrieveContext(ctx, pathTargets)
 	query.AddProcessingStep("vector_search", time.Since(stepStart), err == nil, err)
-	if err != nil {
-		s.logger.Warn("Vector search failed", zap.Error(err))
+	query.Metadata.RetrievalAttempts = attempts
+	switch {
+	case err != nil:
+		s.logger.Warn("Vector search failed, continuing without context",
+			zap.Int("attempts", attempts),
+			zap.Error(err))
 		vectorResults = []types.VectorResult{}
+		query.Metadata.RetrievalStatus = metrics.RetrievalDegraded
+	case attempts > 1:
+		query.Metadata.RetrievalStatus = metrics.RetrievalTransientFailure
+	default:
+		query.Metadata.RetrievalStatus = metrics.RetrievalSucceeded
+	}
+	if err == nil {
+		// Taken before any broadening, so a cache hit can repeat the same search
+		query.Metadata.ContextHash = contextHash(vectorResults)
 	}
+	metrics.VectorRetrievals.WithLabelValues(query.Metadata.RetrievalStatus).Inc()
+
+	lowCoverage := false
+	if s.config.LowCoverageEnabled && !s.hasCoverage(vectorResults) {
+		if err == nil && s.config.LowCoverageBroadSearch {
+			vectorResults = s.broadenContext(ctx, query, vectorResults)
+		}
+		if !s.hasCoverage(vectorResults) {
+			lowCoverage = true
+			query.Metadata.LowCoverage = true
+			result.Degraded = true
+			s.logger.Warn("Low retrieval coverage, generating explanation without grounding",
+				zap.String("query_id", query.ID),
+				zap.Int("vector_hits", len(vectorResults)),
+				zap.Float64("min_score", s.config.LowCoverageMinScore))
+		}
+	}
+	query.Metadata.VectorHits = len(vectorResults)
 
 	context := make([]string, len(vectorResults))
+	sources := make([]types.ContextSource, len(vectorResults))
 	for i, vr := range vectorResults {
 		context[i] = vr.Content
+		sources[i] = contextSource(vr)
 	}
 	result.RetrievedContext = context
+	result.ContextSources = sources
 
 	// Step 4: Generate explanation
 	stepStart = time.Now()
-	explanation, err := s.llmClient.GenerateExplanation(ctx, ExplanationRequest{
+	explanationReq := ExplanationRequest{
 		Query:            query.Text,
 		PrerequisitePath: prereqPath,
 		ContextChunks:    context,
+		AudienceLevel:    query.AudienceLevel,
+		OutputFormat:     query.OutputFormat,
+		LowCoverage:      lowCoverage,
+		MaxTokens:        query.MaxTokens,
+		// Marked as unverified in the prompt
+		SuggestedPrerequisites: suggested,
+	}
+	var generated *ExplanationResult
+	err = s.runStep(ctx, query, "generate_explanation", func() error {
+		var err error
+		generated, err = s.llmClient.GenerateExplanation(ctx, explanationReq)
+		return err
 	})
-	query.AddProcessingStep("generate_explanation", time.Since(stepStart), err == nil, err)
 	if err != nil {
 		return nil, fmt.Errorf("explanation generation failed: %w", err)
 	}
 
+	// Unclosed LaTeX delimiters are a common LLM error; optionally retry once
+	blocks, balanced := parseExplanationBlocks(generated.Explanation)
+	if !balanced && s.config.RegenerateUnbalancedMath {
+		stepStart = time.Now()
+		retry, retryErr := s.llmClient.GenerateExplanation(ctx, explanationReq)
+		query.AddProcessingStep("regenerate_explanation", time.Since(stepStart), retryErr == nil, retryErr)
+		if retryErr != nil {
+			s.logger.Warn("Explanation regeneration failed, keeping unbalanced explanation",
+				zap.String("query_id", query.ID),
+				zap.Error(retryErr))
+		} else if retryBlocks, retryBalanced := parseExplanationBlocks(retry.Explanation); retryBalanced {
+			generated, blocks, balanced = retry, retryBlocks, true
+		}
+	}
+	if !balanced {
+		s.logger.Warn("Explanation has unbalanced math delimiters", zap.String("query_id", query.ID))
+	}
+
 	query.Response = entities.QueryResponse{
-		Explanation:      explanation,
-		RetrievedContext: context,
-		LLMProvider:      s.llmClient.Provider(),
-		LLMModel:         s.llmClient.Model(),
+		Explanation:         generated.Explanation,
+		RetrievedContext:    context,
+		ContextSources:      sources,
+		CitedContextIndices: generated.CitedContextIndices,
+		MaxOutputTokens:     generated.MaxTokens,
+		Truncated:           generated.Truncated,
+		UnbalancedMath:      !balanced,
+		FullExplanation:     generated.FullExplanation,
+		LLMProvider:         s.llmClient.Provider(),
+		LLMModel:            generated.Model,
+	}
+	if query.Response.LLMModel == "" {
+		query.Response.LLMModel = s.llmClient.Model()
 	}
-	result.Explanation = explanation
+	result.Explanation = generated.Explanation
+	result.ExplanationBlocks = blocks
+	result.UnbalancedMath = !balanced
+	result.CitedContextIndices = generated.CitedContextIndices
 
 	return result, nil
 }
 
+// contextSource extracts the vector store origin of a retrieved chunk
+func contextSource(vr types.VectorResult) types.ContextSource {
+	source := types.ContextSource{Concept: vr.Concept, Score: vr.Score}
+	source.Chapter, _ = vr.Metadata["chapter"].(string)
+	source.Source, _ = vr.Metadata["source"].(string)
+	source.ChunkIndex, _ = vr.Metadata["chunk_index"].(int)
+	return source
+}
+
+// hasCoverage reports whether any retrieved chunk scores at least LowCoverageMinScore
+func (s *queryService) hasCoverage(results []types.VectorResult) bool {
+	for _, vr := range results {
+		if vr.Score >= s.config.LowCoverageMinScore {
+			return true
+		}
+	}
+	return false
+}
+
+// broadenContext searches on the full question with a wider limit and adds the
+// well-scoring chunks it finds ahead of the existing ones, up to MaxContextChunks.
+// A failed broad search leaves the results unchanged.
+func (s *queryService) broadenContext(ctx context.Context, query *entities.Query, results []types.VectorResult) []types.VectorResult {
+	stepStart := time.Now()
+	broad, _, err := s.searchWithRetry(ctx, query.Text, s.config.MaxContextChunks*2)
+	query.AddProcessingStep("vector_search_broad", time.Since(stepStart), err == nil, err)
+	if err != nil {
+		s.logger.Warn("Broad vector search failed", zap.String("query_id", query.ID), zap.Error(err))
+		return results
+	}
+
+	seen := make(map[string]bool, len(results))
+	for _, vr := range results {
+		seen[vr.Content] = true
+	}
+
+	var merged []types.VectorResult
+	for _, vr := range broad {
+		if vr.Score >= s.config.LowCoverageMinScore && !seen[vr.Content] {
+			seen[vr.Content] = true
+			merged = append(merged, vr)
+		}
+	}
+	if len(merged) == 0 {
+		return results
+	}
+
+	merged = append(merged, results...)
+	if len(merged) > s.config.MaxContextChunks {
+		merged = merged[:s.config.MaxContextChunks]
+	}
+	s.log(ctx).Info("Broad vector search improved coverage",
+		zap.String("query_id", query.ID),
+		zap.Int("vector_hits", len(merged)))
+	return merged
+}
+
+// identifyConceptsFromVectors derives candidate concepts from the concept field of the
+// chunks most similar to the query, in score order. It is used when the LLM cannot
+// identify concepts, so the query can still be answered in degraded form.
+func (s *queryService) identifyConceptsFromVectors(ctx context.Context, query *entities.Query) ([]string, error) {
+	stepStart := time.Now()
+	vectorResults, _, err := s.searchWithRetry(ctx, query.Text, s.config.ConceptFallbackMaxConcepts*2)
+	if err == nil && len(vectorResults) == 0 {
+		err = fmt.Errorf("vector search returned no results")
+	}
+	if err != nil {
+		err = fmt.Errorf("vector fallback failed: %w", err)
+		query.AddProcessingStep("identify_concepts_fallback", time.Since(stepStart), false, err)
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var concepts []string
+	for _, vr := range vectorResults {
+		concept := strings.TrimSpace(vr.Concept)
+		key := strings.ToLower(concept)
+		if concept == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		concepts = append(concepts, concept)
+		if len(concepts) >= s.config.ConceptFallbackMaxConcepts {
+			break
+		}
+	}
+
+	if len(concepts) == 0 {
+		err = fmt.Errorf("vector fallback found no concepts in %d results", len(vectorResults))
+		query.AddProcessingStep("identify_concepts_fallback", time.Since(stepStart), false, err)
+		return nil, err
+	}
+
+	query.AddProcessingStep("identify_concepts_fallback", time.Since(stepStart), true, nil)
+	s.log(ctx).Info("Concepts derived from vector search",
+		zap.String("query_id", query.ID),
+		zap.Strings("concepts", concepts))
+
+	return concepts, nil
+}
+
+// ensureMinimumConcepts guards against the LLM returning too few concepts for a long
+// query. It retries once with a more explicit prompt and keeps whichever attempt found
+// more concepts; an empty result makes the pipeline fall back to the raw query text.
+func (s *queryService) ensureMinimumConcepts(ctx context.Context, query *entities.Query, concepts []string) []string {
+	if len(concepts) >= s.config.MinConcepts || len(strings.Fields(query.Text)) < s.config.MinConceptsQueryWords {
+		return concepts
+	}
+
+	s.logger.Warn("Too few concepts identified, retrying with explicit prompt",
+		zap.String("query_id", query.ID),
+		zap.Int("identified", len(concepts)),
+		zap.Int("minimum", s.config.MinConcepts))
+
+	stepStart := time.Now()
+	retried, err := s.llmClient.IdentifyConceptsExplicit(ctx, query.Text, s.config.MinConcepts)
+	query.AddProcessingStep("identify_concepts_retry", time.Since(stepStart), err == nil, err)
+	if err == nil && len(retried) > len(concepts) {
+		concepts = retried
+	}
+
+	if len(concepts) == 0 {
+		s.logger.Warn("Concept identification returned nothing, falling back to raw query text",
+			zap.String("query_id", query.ID),
+			zap.Error(err))
+	}
+
+	return concepts
+}
+
+// filterIdentifiedConcepts drops denylisted generic terms and, in allowlist mode, concepts
+// missing from the graph. knownIDs holds graph IDs already looked up ("" when absent);
+// only the other concepts are resolved. If everything would be dropped the first concept
+// is kept so the pipeline still has something to work with.
+func (s *queryService) filterIdentifiedConcepts(ctx context.Context, query *entities.Query, concepts []string, knownIDs map[string]string) []string {
+	if len(concepts) == 0 || (!s.config.ConceptDenylistEnabled && !s.config.ConceptGraphAllowlist) {
+		return concepts
+	}
+
+	kept := concepts
+	var filtered []string
+
+	if s.config.ConceptDenylistEnabled {
+		denied := make(map[string]bool, len(s.config.ConceptDenylist))
+		for _, term := range s.config.ConceptDenylist {
+			denied[strings.ToLower(strings.TrimSpace(term))] = true
+		}
+
+		var allowed []string
+		for _, concept := range kept {
+			if denied[strings.ToLower(strings.TrimSpace(concept))] {
+				filtered = append(filtered, concept)
+				continue
+			}
+			allowed = append(allowed, concept)
+		}
+		kept = allowed
+	}
+
+	if s.config.ConceptGraphAllowlist && len(kept) > 0 {
+		ids, err := s.resolveUnknownIDs(ctx, kept, knownIDs)
+		if err != nil {
+			// Keep the denylist result rather than failing the query on a lookup error
+			s.logger.Warn("Concept allowlist check failed, skipping", zap.Error(err))
+		} else {
+			var inGraph []string
+			for _, concept := range kept {
+				if ids[concept] != "" {
+					inGraph = append(inGraph, concept)
+					continue
+				}
+				filtered = append(filtered, concept)
+			}
+			kept = inGraph
+		}
+	}
+
+	if len(kept) == 0 {
+		kept = concepts[:1]
+	}
+
+	if len(filtered) > 0 {
+		s.log(ctx).Info("Filtered identified concepts",
+			zap.String("query_id", query.ID),
+			zap.Strings("filtered", filtered),
+			zap.Strings("kept", kept))
+	}
+
+	return kept
+}
+
+// resolveUnknownIDs returns the graph IDs of concepts, taking those in knownIDs as given
+// and resolving the rest in one batch. Concepts not in the graph are absent.
+func (s *queryService) resolveUnknownIDs(ctx context.Context, concepts []string, knownIDs map[string]string) (map[string]string, error) {
+	ids := make(map[string]string, len(concepts))
+	var unknown []string
+	for _, concept := range concepts {
+		id, ok := knownIDs[concept]
+		switch {
+		case !ok:
+			unknown = append(unknown, concept)
+		case id != "":
+			ids[concept] = id
+		}
+	}
+	if len(unknown) == 0 {
+		return ids, nil
+	}
+
+	resolved, err := s.conceptRepo.ResolveIDs(ctx, unknown)
+	if err != nil {
+		return nil, err
+	}
+	for name, id := range resolved {
+		ids[name] = id
+	}
+	return ids, nil
+}
+
+// recordQueryMetrics observes the steps the query actually reached, so an early
+// failure only reports the steps that ran, plus the end-to-end latency
+func (s *queryService) recordQueryMetrics(query *entities.Query) {
+	for _, step := range query.Metadata.ProcessingSteps {
+		metrics.QueryStepDuration.
+			WithLabelValues(step.Name, strconv.FormatBool(step.Success)).
+			Observe(step.Duration.Seconds())
+	}
+
+	metrics.QueryDuration.
+		WithLabelValues(strconv.FormatBool(query.Success)).
+		Observe(float64(query.ProcessingTimeMs) / 1000)
+}
+
+// searchWithRetry runs the vector search with exponential backoff between attempts.
+// It returns the number of attempts made so callers can tell transient failures apart.
+func (s *queryService) searchWithRetry(ctx context.Context, text string, limit int) ([]types.VectorResult, int, error) {
+	var results []types.VectorResult
+	attempts, err := s.retryVectorSearch(ctx, func() error {
+		var err error
+		results, err = s.vectorRepo.Search(ctx, text, limit)
+		return err
+	})
+	return results, attempts, err
+}
+
+// retrieveContext searches the vector store for every query concurrently and merges the
+// results round-robin, dropping duplicate chunks, up to MaxContextChunks. Within each
+// query's results, chunks tagged with one of the queried concepts are reranked ahead
+// first (see rerankByConcept), so every query still gets its turn in the merge.
+// Queries that fail contribute nothing; the search is retried only when all of them fail.
+func (s *queryService) retrieveContext(ctx context.Context, queries []string) ([]types.VectorResult, int, error) {
+	var batches [][]types.VectorResult
+	attempts, err := s.retryVectorSearch(ctx, func() error {
+		var err error
+		batches, err = s.vectorRepo.BatchSearch(ctx, queries, s.config.VectorResultsPerConcept)
+		return err
+	})
+	if err != nil {
+		return nil, attempts, err
+	}
+
+	if s.config.ConceptMatchBoost <= 0 {
+		return mergeRoundRobin(batches, s.config.MaxContextChunks), attempts, nil
+	}
+
+	reranked := make([][]types.VectorResult, len(batches))
+	for i, batch := range batches {
+		reranked[i] = rerankByConcept(batch, queries, s.config.ConceptMatchBoost)
+	}
+	merged := mergeRoundRobin(reranked, s.config.MaxContextChunks)
+
+	// Report how the chosen context changed: matching chunks in it, and chunks that
+	// only made the cut because of the rerank
+	wanted := normalizedConceptSet(queries)
+	before := make(map[string]bool)
+	for _, vr := range mergeRoundRobin(batches, s.config.MaxContextChunks) {
+		before[vr.Content] = true
+	}
+	matched, promoted := 0, 0
+	for _, vr := range merged {
+		if wanted[normalizeConceptName(vr.Concept)] {
+			matched++
+		}
+		if !before[vr.Content] {
+			promoted++
+		}
+	}
+	s.log(ctx).Info("Reranked context by concept",
+		zap.Strings("concepts", queries),
+		zap.Int("chunks", len(merged)),
+		zap.Int("concept_matches", matched),
+		zap.Int("promoted", promoted),
+		zap.Float64("boost", s.config.ConceptMatchBoost))
+
+	return merged, attempts, nil
+}
+
+// mergeRoundRobin takes the best remaining chunk of each batch in turn, skipping
+// duplicate chunks, until limit chunks are chosen or the batches run out
+func mergeRoundRobin(batches [][]types.VectorResult, limit int) []types.VectorResult {
+	merged := []types.VectorResult{}
+	seen := make(map[string]bool)
+	for rank := 0; len(merged) < limit; rank++ {
+		exhausted := true
+		for _, batch := range batches {
+			if rank >= len(batch) {
+				continue
+			}
+			exhausted = false
+			if len(merged) >= limit || seen[batch[rank].Content] {
+				continue
+			}
+			seen[batch[rank].Content] = true
+			merged = append(merged, batch[rank])
+		}
+		if exhausted {
+			break
+		}
+	}
+	return merged
+}
+
+// rerankByConcept stably reorders one query's results by score plus boost for chunks
+// whose concept matches one of concepts, so on-topic chunks are not crowded out of the
+// context by semantically near chunks about other concepts. Scores are left unchanged.
+func rerankByConcept(results []types.VectorResult, concepts []string, boost float64) []types.VectorResult {
+	wanted := normalizedConceptSet(concepts)
+	boosted := func(vr types.VectorResult) float64 {
+		if wanted[normalizeConceptName(vr.Concept)] {
+			return vr.Score + boost
+		}
+		return vr.Score
+	}
+
+	reranked := make([]types.VectorResult, len(results))
+	copy(reranked, results)
+	sort.SliceStable(reranked, func(i, j int) bool {
+		return boosted(reranked[i]) > boosted(reranked[j])
+	})
+	return reranked
+}
+
+// normalizedConceptSet is the set of normalized concept names
+func normalizedConceptSet(concepts []string) map[string]bool {
+	set := make(map[string]bool, len(concepts))
+	for _, concept := range concepts {
+		if key := normalizeConceptName(concept); key != "" {
+			set[key] = true
+		}
+	}
+	return set
+}
+
+// suggestMissingPrerequisites asks the LLM for the likely prerequisites of identified
+// concepts that are absent from the prerequisite path, i.e. that the graph has no
+// prerequisites for, starting with the unresolved ones the graph does not have at all.
+// Suggestions are recorded on the query so curators can review them; failures only lose
+// the suggestions.
+func (s *queryService) suggestMissingPrerequisites(ctx context.Context, query *entities.Query, conceptNames, unresolved []string, prereqPath []types.Concept) map[string][]string {
+	if !s.config.SuggestPrerequisitesEnabled || len(conceptNames) == 0 {
+		return nil
+	}
+
+	inPath := make(map[string]bool, len(prereqPath))
+	for _, concept := range prereqPath {
+		inPath[strings.ToLower(concept.Name)] = true
+		inPath[strings.ToLower(concept.ID)] = true
+	}
+	var gaps []string
+	for _, name := range append(append([]string{}, unresolved...), conceptNames...) {
+		if !inPath[strings.ToLower(name)] {
+			gaps = append(gaps, name)
+			inPath[strings.ToLower(name)] = true
+		}
+		if len(gaps) == s.config.SuggestPrerequisitesMaxConcepts {
+			break
+		}
+	}
+	if len(gaps) == 0 {
+		return nil
+	}
+
+	stepStart := time.Now()
+	perConcept := make([][]string, len(gaps))
+	g, gCtx := errgroup.WithContext(ctx)
+	for i, concept := range gaps {
+		g.Go(func() error {
+			prerequisites, err := s.llmClient.SuggestPrerequisites(gCtx, concept)
+			if err != nil {
+				s.logger.Warn("Failed to suggest prerequisites",
+					zap.String("query_id", query.ID),
+					zap.String("concept", concept),
+					zap.Error(err))
+				return nil
+			}
+			perConcept[i] = prerequisites
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	suggested := make(map[string][]string)
+	for i, concept := range gaps {
+		if len(perConcept[i]) > 0 {
+			suggested[concept] = perConcept[i]
+		}
+	}
+	query.AddProcessingStep("suggest_prerequisites", time.Since(stepStart), len(suggested) > 0, nil)
+	if len(suggested) == 0 {
+		return nil
+	}
+
+	query.Metadata.SuggestedPrerequisites = suggested
+	s.log(ctx).Info("Using LLM-suggested prerequisites for concepts missing from the graph",
+		zap.String("query_id", query.ID),
+		zap.Strings("concepts", gaps),
+		zap.Any("suggested", suggested))
+
+	return suggested
+}
+
+// runStep runs one pipeline step, re-running it alone up to StepRetries times with
+// exponential backoff while it fails with a retryable error. Every attempt is recorded
+// as a processing step and retries are counted in the query metadata.
+func (s *queryService) runStep(ctx context.Context, query *entities.Query, name string, step func() error) error {
+	backoff := s.config.StepRetryBackoff
+
+	for retry := 0; ; retry++ {
+		stepStart := time.Now()
+		err := step()
+		query.AddProcessingStep(name, time.Since(stepStart), err == nil, err)
+		if err == nil || retry >= s.config.StepRetries || !isRetryableStepError(ctx, err) {
+			return err
+		}
+
+		if query.Metadata.StepRetries == nil {
+			query.Metadata.StepRetries = make(map[string]int)
+		}
+		query.Metadata.StepRetries[name]++
+		metrics.PipelineStepRetries.WithLabelValues(name).Inc()
+
+		s.logger.Warn("Pipeline step failed, retrying",
+			zap.String("query_id", query.ID),
+			zap.String("step", name),
+			zap.Int("retry", retry+1),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isRetryableStepError reports whether re-running a step could succeed; oversized
+// requests, a finished request context and a backend behind an open circuit breaker
+// fail the same way every time
+func isRetryableStepError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return !errors.Is(err, llm.ErrPromptTooLarge) &&
+		!errors.Is(err, llm.ErrMaxTokensExceeded) &&
+		!errors.Is(err, repositories.ErrBackendUnavailable)
+}
+
+// retryVectorSearch runs search up to VectorSearchAttempts times with exponential backoff
+// and returns the number of attempts made. An open circuit breaker is not retried, so
+// retrieval degrades at once.
+func (s *queryService) retryVectorSearch(ctx context.Context, search func() error) (int, error) {
+	var lastErr error
+	backoff := s.config.VectorSearchBackoff
+
+	for attempt := 1; attempt <= s.config.VectorSearchAttempts; attempt++ {
+		err := search()
+		if err == nil {
+			return attempt, nil
+		}
+		lastErr = err
+
+		if attempt == s.config.VectorSearchAttempts || errors.Is(err, repositories.ErrBackendUnavailable) {
+			return attempt, err
+		}
+
+		s.logger.Warn("Vector search attempt failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return attempt, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	return s.config.VectorSearchAttempts, lastErr
+}
+
 func (s *queryService) saveQueryAsync(ctx context.Context, query *entities.Query) {
-	go func() {
+	// Not derived from baseCtx: a query that has finished is still saved during shutdown
+	s.goBackground(func() {
 		saveCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
@@ -167,24 +1002,63 @@ func (s *queryService) saveQueryAsync(ctx context.Context, query *entities.Query
 				zap.Error(err),
 				zap.String("query_id", query.ID))
 		}
-	}()
+	})
+}
+
+// acquireScrapeSlot reserves a background scrape slot without waiting; false means
+// MaxBackgroundScrapes jobs are already running and this one should be skipped
+func (s *queryService) acquireScrapeSlot(trigger string) bool {
+	select {
+	case s.scrapeSlots <- struct{}{}:
+		metrics.BackgroundScrapeJobs.WithLabelValues(metrics.ScrapeJobStarted, trigger).Inc()
+		metrics.BackgroundScrapesInFlight.Inc()
+		return true
+	default:
+		metrics.BackgroundScrapeJobs.WithLabelValues(metrics.ScrapeJobSkipped, trigger).Inc()
+		return false
+	}
+}
+
+func (s *queryService) releaseScrapeSlot() {
+	<-s.scrapeSlots
+	metrics.BackgroundScrapesInFlight.Dec()
 }
 
 // scrapeResourcesAsync scrapes educational resources in the background
 func (s *queryService) scrapeResourcesAsync(ctx context.Context, conceptNames []string, queryID string) {
-	s.logger.Info("Starting background resource scraping",
+	if !s.acquireScrapeSlot("query") {
+		s.log(ctx).Info("Background scrape limit reached, skipping resource scraping",
+			zap.String("query_id", queryID),
+			zap.Int("max_background_scrapes", s.config.MaxBackgroundScrapes))
+		return
+	}
+	defer s.releaseScrapeSlot()
+
+	s.log(ctx).Info("Starting background resource scraping",
 		zap.String("query_id", queryID),
 		zap.Strings("concepts", conceptNames))
 
+	// Drop names that would produce empty or colliding concept IDs before they use up
+	// one of the limited scrape slots
+	conceptNames, skipped := s.resourceScraper.FilterConceptNames(conceptNames)
+	if len(skipped) > 0 {
+		s.log(ctx).Info("Skipped unscrapable concepts",
+			zap.String("query_id", queryID),
+			zap.Strings("skipped", skipped))
+	}
+	if len(conceptNames) == 0 {
+		return
+	}
+
 	// Create a background context with timeout for scraping
-	scraperCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	scraperCtx, cancel := context.WithTimeout(logger.WithSamplingFrom(s.baseCtx, ctx), 2*time.Minute)
 	defer cancel()
 
 	// Limit concepts to avoid excessive scraping
 	maxConcepts := 5
 	if len(conceptNames) > maxConcepts {
 		conceptNames = conceptNames[:maxConcepts]
-		s.logger.Info("Limited concept scraping",
+		s.log(ctx).Info("Limited concept scraping",
 			zap.Int("max_concepts", maxConcepts),
 			zap.String("query_id", queryID))
 	}
@@ -196,7 +1070,7 @@ func (s *queryService) scrapeResourcesAsync(ctx context.Context, conceptNames []
 			zap.String("query_id", queryID),
 			zap.Strings("concepts", conceptNames))
 	} else {
-		s.logger.Info("Background resource scraping completed successfully",
+		s.log(ctx).Info("Background resource scraping completed successfully",
 			zap.String("query_id", queryID),
 			zap.Strings("concepts", conceptNames))
 	}
@@ -208,28 +1082,29 @@ func (s *queryService) GetResourcesForConcepts(ctx context.Context, conceptNames
 		return nil, fmt.Errorf("resource scraper not available")
 	}
 
-	var allResources []scraper.EducationalResource
-
-	for _, conceptName := range conceptNames {
-		conceptID := s.generateConceptID(conceptName)
-		resources, err := s.resourceScraper.GetResourcesForConcept(ctx, conceptID, limit)
-		if err != nil {
-			s.logger.Warn("Failed to get resources for concept",
-				zap.String("concept", conceptName),
-				zap.Error(err))
-			continue
-		}
-		allResources = append(allResources, resources...)
-	}
-
-	// Sort by quality score (descending)
-	for i := 0; i < len(allResources)-1; i++ {
-		for j := 0; j < len(allResources)-i-1; j++ {
-			if allResources[j].QualityScore < allResources[j+1].QualityScore {
-				allResources[j], allResources[j+1] = allResources[j+1], allResources[j]
+	// Each lookup writes only its own slot, so the merge below sees the same input
+	// regardless of the order the lookups finish in
+	perConcept := make([][]scraper.EducationalResource, len(conceptNames))
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(resourceFetchConcurrency)
+	for i, conceptName := range conceptNames {
+		i, conceptName := i, conceptName
+		g.Go(func() error {
+			conceptID := s.resourceScraper.ResolveConceptID(gCtx, conceptName)
+			resources, err := s.resourceScraper.GetResourcesForConcept(gCtx, conceptID, limit, false)
+			if err != nil {
+				s.logger.Warn("Failed to get resources for concept",
+					zap.String("concept", conceptName),
+					zap.Error(err))
+				return nil
 			}
-		}
+			perConcept[i] = resources
+			return nil
+		})
 	}
+	_ = g.Wait()
+
+	allResources := mergeConceptResources(perConcept, s.resourceScraper.RankScore)
 
 	// Limit total results
 	if len(allResources) > limit {
@@ -239,8 +1114,9 @@ func (s *queryService) GetResourcesForConcepts(ctx context.Context, conceptNames
 	return allResources, nil
 }
 
-// FindCachedConceptQuery searches for existing queries that match the concept
-func (s *queryService) FindCachedConceptQuery(ctx context.Context, conceptName string) (*entities.Query, error) {
+// FindCachedConceptQuery searches for existing queries that match the concept and were
+// explained for the same audience level in the same output format
+func (s *queryService) FindCachedConceptQuery(ctx context.Context, conceptName, audienceLevel, outputFormat string) (*entities.Query, error) {
 	// Normalize the concept name for better matching
 	normalizedConcept := strings.TrimSpace(strings.ToLower(conceptName))
 
@@ -252,7 +1128,7 @@ func (s *queryService) FindCachedConceptQuery(ctx context.Context, conceptName s
 	}
 
 	for _, searchTerm := range searchStrategies {
-		query, err := s.queryRepo.FindByConceptName(ctx, searchTerm)
+		query, err := s.queryRepo.FindByConceptName(ctx, searchTerm, audienceLevel, outputFormat)
 		if err != nil {
 			s.logger.Warn("Error searching for cached concept",
 				zap.String("search_term", searchTerm),
@@ -261,7 +1137,7 @@ func (s *queryService) FindCachedConceptQuery(ctx context.Context, conceptName s
 		}
 
 		if query != nil {
-			s.logger.Info("Found cached concept query",
+			s.log(ctx).Info("Found cached concept query",
 				zap.String("concept", conceptName),
 				zap.String("search_term", searchTerm),
 				zap.String("cached_query_id", query.ID),
@@ -271,84 +1147,165 @@ func (s *queryService) FindCachedConceptQuery(ctx context.Context, conceptName s
 	}
 
 	// No cached query found
-	s.logger.Info("No cached query found for concept", zap.String("concept", conceptName))
+	s.log(ctx).Info("No cached query found for concept", zap.String("concept", conceptName))
 	return nil, nil
 }
 
+// contextHash fingerprints retrieved chunks by content, ignoring their order so a
+// reshuffle of near-equal scores does not count as a change
+func contextHash(results []types.VectorResult) string {
+	contents := make([]string, len(results))
+	for i, vr := range results {
+		contents[i] = vr.Content
+	}
+	sort.Strings(contents)
+
+	hash := sha256.New()
+	for _, content := range contents {
+		hash.Write([]byte(content))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// cachedContextChanged repeats a cached query's vector search and reports whether the
+// context now differs from the one its explanation was grounded in. Entries stored
+// without a hash, and searches that fail, keep the cached explanation.
+func (s *queryService) cachedContextChanged(ctx context.Context, cached *entities.Query) bool {
+	if cached.Metadata.ContextHash == "" {
+		return false
+	}
+
+	targets := cached.IdentifiedConcepts
+	if len(targets) == 0 {
+		targets = []string{cached.Text}
+	}
+
+	results, _, err := s.retrieveContext(ctx, targets)
+	if err != nil {
+		s.logger.Warn("Failed to check cached context, keeping cached explanation",
+			zap.String("cached_query_id", cached.ID),
+			zap.Error(err))
+		return false
+	}
+	return contextHash(results) != cached.Metadata.ContextHash
+}
+
 // SmartConceptQuery checks cache first, then processes if needed
-func (s *queryService) SmartConceptQuery(ctx context.Context, conceptName, userID, requestID string) (*services.QueryResult, error) {
+func (s *queryService) SmartConceptQuery(ctx context.Context, conceptName, userID, requestID, audienceLevel, outputFormat string) (*services.QueryResult, error) {
 	startTime := time.Now()
+	ctx = logger.WithRequestSampling(ctx, requestID)
 
-	s.logger.Info("Smart concept query started",
+	if audienceLevel == "" {
+		audienceLevel = entities.DefaultAudienceLevel
+	}
+	if !entities.IsValidAudienceLevel(audienceLevel) {
+		return nil, fmt.Errorf("%w: %s", services.ErrInvalidAudienceLevel, audienceLevel)
+	}
+	if outputFormat == "" {
+		outputFormat = entities.DefaultOutputFormat
+	}
+	if !entities.IsValidOutputFormat(outputFormat) {
+		return nil, fmt.Errorf("%w: %s", services.ErrInvalidOutputFormat, outputFormat)
+	}
+
+	s.log(ctx).Info("Smart concept query started",
 		zap.String("concept", conceptName),
 		zap.String("user_id", userID),
 		zap.String("request_id", requestID))
 
+	// Reject names the graph does not know before spending an LLM call on them
+	if s.config.ConceptValidationEnabled {
+		canonical, err := s.resolveConceptName(ctx, conceptName)
+		if err != nil {
+			return nil, err
+		}
+		conceptName = canonical
+	}
+
 	// Step 1: Try to find cached query for this concept in MongoDB
-	s.logger.Info("Checking MongoDB cache for concept", zap.String("concept", conceptName))
+	s.log(ctx).Info("Checking MongoDB cache for concept", zap.String("concept", conceptName))
 
-	cachedQuery, err := s.FindCachedConceptQuery(ctx, conceptName)
-	if err != nil {
-		s.logger.Warn("Failed to search MongoDB cache",
-			zap.String("concept", conceptName),
-			zap.Error(err))
-		// Continue to fresh processing if cache search fails
+	var cachedQuery *entities.Query
+	if s.config.ExplanationCacheEnabled {
+		var err error
+		cachedQuery, err = s.FindCachedConceptQuery(ctx, conceptName, audienceLevel, outputFormat)
+		if err != nil {
+			s.logger.Warn("Failed to search MongoDB cache",
+				zap.String("concept", conceptName),
+				zap.Error(err))
+			// Continue to fresh processing if cache search fails
+		}
 	}
 
-	// Step 2: If we have cached data and it's relatively recent (within 30 days), return it
+	// Step 2: If we have cached data and it's recent enough, return it
 	if cachedQuery != nil {
 		cacheAge := time.Since(cachedQuery.Timestamp)
-		maxCacheAge := 30 * 24 * time.Hour // 30 days for math concepts
+		maxCacheAge := s.config.ExplanationCacheMaxAge
 
-		if cacheAge < maxCacheAge {
-			s.logger.Info("Returning cached concept data",
+		switch {
+		case cacheAge >= maxCacheAge:
+			s.log(ctx).Info("Cached data is too old, processing fresh query",
+				zap.String("concept", conceptName),
+				zap.Duration("cache_age", cacheAge),
+				zap.Duration("max_age", maxCacheAge))
+		case s.config.ExplanationCacheContextCheck && s.cachedContextChanged(ctx, cachedQuery):
+			s.logger.Info("Cached explanation invalidated, retrieved context changed",
+				zap.String("concept", conceptName),
+				zap.String("cached_query_id", cachedQuery.ID),
+				zap.Duration("cache_age", cacheAge))
+		default:
+			s.log(ctx).Info("Returning cached concept data",
 				zap.String("concept", conceptName),
 				zap.String("cached_query_id", cachedQuery.ID),
 				zap.Time("cached_at", cachedQuery.Timestamp),
 				zap.Duration("cache_age", cacheAge))
 
 			// Start background resource gathering (non-blocking)
-			go s.gatherResourcesInBackground(ctx, conceptName, cachedQuery.IdentifiedConcepts)
+			s.goBackground(func() { s.gatherResourcesInBackground(ctx, conceptName, cachedQuery.IdentifiedConcepts) })
 
 			// Convert cached query to QueryResult
+			blocks, balanced := parseExplanationBlocks(cachedQuery.Response.Explanation)
 			result := &services.QueryResult{
-				Query:              cachedQuery,
-				IdentifiedConcepts: cachedQuery.IdentifiedConcepts,
-				PrerequisitePath:   cachedQuery.PrerequisitePath,
-				RetrievedContext:   cachedQuery.Response.RetrievedContext,
-				Explanation:        cachedQuery.Response.Explanation,
-				ProcessingTime:     time.Since(startTime),
-				RequestID:          requestID,
+				Query:               cachedQuery,
+				IdentifiedConcepts:  cachedQuery.IdentifiedConcepts,
+				PrerequisitePath:    cachedQuery.PrerequisitePath,
+				RetrievedContext:    cachedQuery.Response.RetrievedContext,
+				ContextSources:      cachedQuery.Response.ContextSources,
+				CitedContextIndices: cachedQuery.Response.CitedContextIndices,
+				Explanation:         cachedQuery.Response.Explanation,
+				ExplanationBlocks:   blocks,
+				UnbalancedMath:      !balanced,
+				ProcessingTime:      time.Since(startTime),
+				RequestID:           requestID,
+				UnresolvedConcepts:  cachedQuery.Metadata.UnresolvedConcepts,
 			}
 
-			s.logger.Info("Smart concept query completed from cache",
+			s.log(ctx).Info("Smart concept query completed from cache",
 				zap.String("concept", conceptName),
 				zap.Duration("total_time", result.ProcessingTime),
 				zap.Duration("cache_age", cacheAge))
 
 			return result, nil
-		} else {
-			s.logger.Info("Cached data is too old, processing fresh query",
-				zap.String("concept", conceptName),
-				zap.Duration("cache_age", cacheAge),
-				zap.Duration("max_age", maxCacheAge))
 		}
 	} else {
-		s.logger.Info("No cached data found, processing fresh query",
+		s.log(ctx).Info("No cached data found, processing fresh query",
 			zap.String("concept", conceptName))
 	}
 
 	// Step 3: No suitable cached data found, process fresh query
-	s.logger.Info("Processing fresh concept query", zap.String("concept", conceptName))
+	s.log(ctx).Info("Processing fresh concept query", zap.String("concept", conceptName))
 
 	// Create a query request for the concept name
 	// Use a more specific prompt for better concept explanation
 	conceptQuestion := s.buildConceptQueryPrompt(conceptName)
 
 	queryReq := &services.QueryRequest{
-		UserID:    userID,
-		Question:  conceptQuestion,
-		RequestID: requestID,
+		UserID:        userID,
+		Question:      conceptQuestion,
+		RequestID:     requestID,
+		AudienceLevel: audienceLevel,
+		OutputFormat:  outputFormat,
 	}
 
 	// Process the query through the normal pipeline
@@ -360,7 +1317,7 @@ func (s *queryService) SmartConceptQuery(ctx context.Context, conceptName, userI
 		return nil, fmt.Errorf("failed to process fresh concept query: %w", err)
 	}
 
-	s.logger.Info("Smart concept query completed with fresh processing",
+	s.log(ctx).Info("Smart concept query completed with fresh processing",
 		zap.String("concept", conceptName),
 		zap.Duration("total_time", time.Since(startTime)),
 		zap.Int("identified_concepts", len(result.IdentifiedConcepts)),
@@ -388,12 +1345,20 @@ Make the explanation educational, detailed, and suitable for students learning t
 
 // gatherResourcesInBackground starts resource gathering without blocking the response
 func (s *queryService) gatherResourcesInBackground(ctx context.Context, conceptName string, identifiedConcepts []string) {
-	s.logger.Info("Starting background resource gathering",
+	if !s.acquireScrapeSlot("cached_concept") {
+		s.log(ctx).Info("Background scrape limit reached, skipping resource gathering",
+			zap.String("concept", conceptName),
+			zap.Int("max_background_scrapes", s.config.MaxBackgroundScrapes))
+		return
+	}
+	defer s.releaseScrapeSlot()
+
+	s.log(ctx).Info("Starting background resource gathering",
 		zap.String("concept", conceptName),
 		zap.Strings("identified_concepts", identifiedConcepts))
 
 	// Create a background context with timeout
-	bgCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	bgCtx, cancel := context.WithTimeout(logger.WithSamplingFrom(s.baseCtx, ctx), 2*time.Minute)
 	defer cancel()
 
 	// Use all concepts for resource gathering (both original concept and identified ones)
@@ -407,7 +1372,7 @@ func (s *queryService) gatherResourcesInBackground(ctx context.Context, conceptN
 	maxConcepts := 3
 	if len(uniqueConcepts) > maxConcepts {
 		uniqueConcepts = uniqueConcepts[:maxConcepts]
-		s.logger.Info("Limited background concept scraping",
+		s.log(ctx).Info("Limited background concept scraping",
 			zap.Int("max_concepts", maxConcepts),
 			zap.String("original_concept", conceptName))
 	}
@@ -420,15 +1385,11 @@ func (s *queryService) gatherResourcesInBackground(ctx context.Context, conceptN
 				zap.String("concept", conceptName),
 				zap.Strings("concepts", uniqueConcepts))
 		} else {
-			s.logger.Info("Background resource gathering completed",
+			s.log(ctx).Info("Background resource gathering completed",
 				zap.String("concept", conceptName),
 				zap.Strings("concepts", uniqueConcepts))
 		}
 	}
-} // generateConceptID creates a standardized concept ID (same logic as scraper)
-func (s *queryService) generateConceptID(conceptName string) string {
-	// Use same logic as scraper to ensure consistency
-	return strings.ToLower(strings.ReplaceAll(conceptName, " ", "_"))
 }
 
 // removeDuplicateStrings removes duplicate strings from a slice
@@ -449,7 +1410,133 @@ func (s *queryService) removeDuplicateStrings(input []string) []string {
 
 // Implement remaining service methods
 func (s *queryService) GetConceptDetail(ctx context.Context, conceptID string) (*types.ConceptDetailResult, error) {
-	return s.conceptRepo.GetConceptDetail(ctx, conceptID)
+	detail, err := s.conceptRepo.GetConceptDetail(ctx, conceptID)
+	if err == nil || errors.Is(err, repositories.ErrConceptNotFound) || errors.Is(err, context.Canceled) {
+		return detail, err
+	}
+	if !s.config.ConceptDetailFallbackEnabled || s.queryRepo == nil {
+		return nil, err
+	}
+
+	fallback := s.cachedConceptDetail(ctx, conceptID)
+	if fallback == nil {
+		return nil, err
+	}
+
+	s.log(ctx).Warn("Graph unavailable, serving concept detail from cached query",
+		zap.String("concept_id", conceptID),
+		zap.Int("prerequisites", len(fallback.Prerequisites)),
+		zap.Error(err))
+	return fallback, nil
+}
+
+// conceptDetailFallbackCandidates is how many recent queries cachedConceptDetail looks
+// through for one whose prerequisite path contains the concept
+const conceptDetailFallbackCandidates = 5
+
+// cachedConceptDetail assembles a degraded concept detail from the most recent cached
+// query whose prerequisite path contains the concept: the path entries before it are
+// its prerequisites. It returns nil when no such query exists.
+func (s *queryService) cachedConceptDetail(ctx context.Context, conceptID string) *types.ConceptDetailResult {
+	candidates, err := s.queryRepo.FindByPathConceptID(ctx, conceptID, conceptDetailFallbackCandidates)
+	if err != nil {
+		s.log(ctx).Warn("Failed to look up cached queries for concept detail",
+			zap.String("concept_id", conceptID),
+			zap.Error(err))
+		return nil
+	}
+
+	for _, cached := range candidates {
+		if detail := conceptDetailFromPath(cached, conceptID); detail != nil {
+			return detail
+		}
+	}
+	return nil
+}
+
+// conceptDetailFromPath builds a degraded concept detail from a cached query's
+// prerequisite path, or returns nil when the path does not contain the concept. The
+// prerequisites are the path's prerequisites ordered before the concept; other targets
+// of the query are left out.
+func conceptDetailFromPath(cached *entities.Query, conceptID string) *types.ConceptDetailResult {
+	for i, concept := range cached.PrerequisitePath {
+		if concept.ID != conceptID {
+			continue
+		}
+
+		prerequisites := make([]types.Concept, 0, i)
+		for _, prereq := range cached.PrerequisitePath[:i] {
+			if prereq.HasRole(types.ConceptRolePrerequisite) {
+				prerequisites = append(prerequisites, prereq)
+			}
+		}
+
+		// Like graph details, the subject is not labelled a target: it is a prerequisite
+		// if the query had it as one and the next concept of its prerequisites
+		subject := concept
+		subject.Roles = nil
+		if concept.HasRole(types.ConceptRolePrerequisite) {
+			subject.Roles = append(subject.Roles, types.ConceptRolePrerequisite)
+		}
+		if len(prerequisites) > 0 {
+			subject.Roles = append(subject.Roles, types.ConceptRoleNext)
+		}
+		subject.Type = "concept"
+		if len(subject.Roles) > 0 {
+			subject.Type = subject.PrimaryRole()
+		}
+
+		return &types.ConceptDetailResult{
+			Concept:             subject,
+			Prerequisites:       prerequisites,
+			LeadsTo:             []types.Concept{},
+			DetailedExplanation: cached.Response.Explanation,
+			Degraded:            true,
+			DegradedReason:      "knowledge graph unavailable; prerequisites taken from a cached query",
+		}
+	}
+	return nil
+}
+
+// GetNextConcepts returns what a concept unlocks, most enabling next steps first
+func (s *queryService) GetNextConcepts(ctx context.Context, conceptID string, limit int) ([]types.Concept, error) {
+	return s.conceptRepo.GetNextConcepts(ctx, conceptID, limit)
+}
+
+// GetPathGraph returns the prerequisite subgraph for the target concepts with its edges
+func (s *queryService) GetPathGraph(ctx context.Context, targetConcepts []string) (*types.PathGraph, error) {
+	graph, err := s.conceptRepo.GetPathGraph(ctx, targetConcepts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Built prerequisite path graph",
+		zap.Strings("targets", targetConcepts),
+		zap.Int("nodes", len(graph.Nodes)),
+		zap.Int("edges", len(graph.Edges)))
+
+	return graph, nil
+}
+
+// CheckPrerequisiteRelationship answers "do I need to know X before Y?"
+func (s *queryService) CheckPrerequisiteRelationship(ctx context.Context, fromConcept, toConcept string) (*types.PrerequisiteRelationshipResult, error) {
+	isPrereq, path, err := s.conceptRepo.IsPrerequisiteOf(ctx, fromConcept, toConcept)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Checked prerequisite relationship",
+		zap.String("from", fromConcept),
+		zap.String("to", toConcept),
+		zap.Bool("is_prerequisite", isPrereq),
+		zap.Int("path_length", len(path)))
+
+	return &types.PrerequisiteRelationshipResult{
+		From:           fromConcept,
+		To:             toConcept,
+		IsPrerequisite: isPrereq,
+		Path:           path,
+	}, nil
 }
 
 func (s *queryService) GetAllConcepts(ctx context.Context) ([]types.Concept, error) {
@@ -464,6 +1551,47 @@ func (s *queryService) GetPopularConcepts(ctx context.Context, limit int) ([]rep
 	return s.queryRepo.GetPopularConcepts(ctx, limit)
 }
 
+// mergeConceptResources flattens per-concept resources, keeping the best-scoring copy of
+// a URL stored under several concepts (ties go to the earlier concept), and orders the
+// result by rank score descending then URL ascending so identical inputs always
+// produce identical output
+func mergeConceptResources(perConcept [][]scraper.EducationalResource, rank func(scraper.EducationalResource) float64) []scraper.EducationalResource {
+	byURL := make(map[string]int)
+	merged := []scraper.EducationalResource{}
+	for _, resources := range perConcept {
+		for _, resource := range resources {
+			if i, ok := byURL[resource.URL]; ok {
+				if rank(resource) > rank(merged[i]) {
+					merged[i] = resource
+				}
+				continue
+			}
+			byURL[resource.URL] = len(merged)
+			merged = append(merged, resource)
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		if ri, rj := rank(merged[i]), rank(merged[j]); ri != rj {
+			return ri > rj
+		}
+		return merged[i].URL < merged[j].URL
+	})
+	return merged
+}
+
+func (s *queryService) GetRecentFailures(ctx context.Context, limit int) ([]repositories.FailedQuerySummary, error) {
+	return s.queryRepo.GetRecentFailures(ctx, limit)
+}
+
+func (s *queryService) GetPrerequisiteSuggestions(ctx context.Context, limit int) ([]repositories.PrerequisiteSuggestion, error) {
+	return s.queryRepo.GetPrerequisiteSuggestions(ctx, limit)
+}
+
+func (s *queryService) GetCoStudiedConcepts(ctx context.Context, conceptName string, limit int) ([]repositories.CoStudiedConcept, error) {
+	return s.queryRepo.GetCoStudiedConcepts(ctx, conceptName, limit)
+}
+
 func (s *queryService) GetQueryTrends(ctx context.Context, days int) ([]repositories.QueryTrend, error) {
 	return s.queryRepo.GetQueryTrends(ctx, days)
 }
@@ -490,6 +1618,163 @@ func (s *queryService) GetCachedConcepts(ctx context.Context, limit int) ([]enti
 	return result, nil
 }
 
+// GenerateMissingConceptDescriptions fills blank concept descriptions in the graph using the LLM.
+// Concepts are processed in rate-limited batches; concepts that already have a description are
+// never touched, so the operation is safe to re-run.
+func (s *queryService) GenerateMissingConceptDescriptions(ctx context.Context, limit int) (*types.DescriptionFillResult, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	concepts, err := s.conceptRepo.FindWithoutDescription(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load concepts without description: %w", err)
+	}
+
+	result := &types.DescriptionFillResult{Candidates: len(concepts)}
+	limiter := rate.NewLimiter(rate.Every(descriptionRequestInterval), 1)
+
+	for start := 0; start < len(concepts); start += descriptionBatchSize {
+		end := min(start+descriptionBatchSize, len(concepts))
+
+		for _, concept := range concepts[start:end] {
+			if err := limiter.Wait(ctx); err != nil {
+				return result, fmt.Errorf("description generation interrupted: %w", err)
+			}
+
+			description, err := s.llmClient.GenerateConceptDescription(ctx, concept.Name)
+			if err != nil || description == "" {
+				s.logger.Warn("Failed to generate concept description",
+					zap.String("concept_id", concept.ID),
+					zap.Error(err))
+				result.Failed = append(result.Failed, concept.ID)
+				continue
+			}
+
+			updated, err := s.conceptRepo.SetDescriptionIfBlank(ctx, concept.ID, description)
+			if err != nil {
+				s.logger.Warn("Failed to store concept description",
+					zap.String("concept_id", concept.ID),
+					zap.Error(err))
+				result.Failed = append(result.Failed, concept.ID)
+				continue
+			}
+
+			if updated {
+				result.Filled++
+			} else {
+				result.Skipped++
+			}
+		}
+
+		s.logger.Info("Processed concept description batch",
+			zap.Int("processed", end),
+			zap.Int("candidates", len(concepts)))
+	}
+
+	s.logger.Info("Concept description generation completed",
+		zap.Int("candidates", result.Candidates),
+		zap.Int("filled", result.Filled),
+		zap.Int("skipped", result.Skipped),
+		zap.Int("failed", len(result.Failed)))
+
+	return result, nil
+}
+
+// explanationCacheName labels the stored explanation cache in invalidation results
+const explanationCacheName = "explanations"
+
+// InvalidateCaches stops cached explanations from being served after the graph was
+// edited out of band. With a concept ID only explanations that identify the concept or
+// include it in their prerequisite path are invalidated; the concept is looked up so
+// both its ID and name match, but a concept deleted from the graph still matches by ID.
+// Concept detail and prerequisite paths are always read from the graph, so they need
+// no invalidation.
+func (s *queryService) InvalidateCaches(ctx context.Context, conceptID, triggeredBy string) (*types.CacheInvalidationResult, error) {
+	result := &types.CacheInvalidationResult{
+		ConceptID:     conceptID,
+		TriggeredBy:   triggeredBy,
+		Invalidated:   make(map[string]int64),
+		InvalidatedAt: time.Now(),
+	}
+
+	var names []string
+	if conceptID != "" {
+		names = append(names, conceptID)
+		if concept, err := s.conceptRepo.FindByID(ctx, conceptID); err == nil && concept.Name != "" && concept.Name != conceptID {
+			names = append(names, concept.Name)
+		}
+		result.MatchedNames = names
+	}
+
+	count, err := s.queryRepo.InvalidateCachedExplanations(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	result.Invalidated[explanationCacheName] = count
+
+	s.logger.Info("Caches invalidated",
+		zap.String("concept_id", conceptID),
+		zap.String("triggered_by", triggeredBy),
+		zap.Int64("explanations", count))
+
+	return result, nil
+}
+
+// RebuildVectorStore wipes the vector store and, when content is supplied, re-ingests it.
+// The returned object count lets the operator verify the rebuild.
+func (s *queryService) RebuildVectorStore(ctx context.Context, content []types.VectorContent, triggeredBy string) (*types.VectorStoreRebuildResult, error) {
+	result := &types.VectorStoreRebuildResult{
+		TriggeredBy: triggeredBy,
+		StartedAt:   time.Now(),
+	}
+
+	s.logger.Warn("Vector store rebuild started",
+		zap.String("triggered_by", triggeredBy),
+		zap.Time("started_at", result.StartedAt),
+		zap.Int("content_chunks", len(content)))
+
+	if err := s.vectorRepo.DeleteAll(ctx); err != nil {
+		return nil, fmt.Errorf("failed to clear vector store: %w", err)
+	}
+
+	if len(content) > 0 {
+		if err := s.vectorRepo.AddContent(ctx, content); err != nil {
+			return nil, fmt.Errorf("failed to re-ingest vector content: %w", err)
+		}
+		result.Ingested = len(content)
+	}
+
+	count, err := s.vectorRepo.Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count vector objects after rebuild: %w", err)
+	}
+	result.ObjectCount = count
+
+	s.logger.Warn("Vector store rebuild completed",
+		zap.String("triggered_by", triggeredBy),
+		zap.Int("ingested", result.Ingested),
+		zap.Int64("object_count", result.ObjectCount),
+		zap.Duration("duration", time.Since(result.StartedAt)))
+
+	return result, nil
+}
+
+// RunGraphDiagnosticQuery runs an operator's read-only Cypher query against the graph
+func (s *queryService) RunGraphDiagnosticQuery(ctx context.Context, cypher string, params map[string]interface{}, triggeredBy string) ([]map[string]interface{}, error) {
+	start := time.Now()
+	rows, err := s.conceptRepo.ExecuteReadQuery(ctx, cypher, params)
+
+	s.logger.Info("Graph diagnostic query",
+		zap.String("triggered_by", triggeredBy),
+		zap.String("cypher", cypher),
+		zap.Int("rows", len(rows)),
+		zap.Duration("duration", time.Since(start)),
+		zap.Error(err))
+
+	return rows, err
+}
+
 // ClearConceptCache removes old cached concept queries (for maintenance)
 func (s *queryService) ClearConceptCache(ctx context.Context, olderThanDays int) error {
 	cutoffDate := time.Now().AddDate(0, 0, -olderThanDays)
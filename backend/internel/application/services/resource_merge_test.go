@@ -0,0 +1,67 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	scraper "mathprereq/internel/data/webscraper"
+)
+
+func TestMergeConceptResources(t *testing.T) {
+	resource := func(conceptID, url string, score float64) scraper.EducationalResource {
+		return scraper.EducationalResource{ConceptID: conceptID, URL: url, QualityScore: score}
+	}
+	byQuality := func(r scraper.EducationalResource) float64 { return r.QualityScore }
+
+	tests := []struct {
+		name         string
+		perConcept   [][]scraper.EducationalResource
+		wantURLs     []string
+		wantConcepts []string
+	}{
+		{
+			name:       "empty",
+			perConcept: [][]scraper.EducationalResource{nil, {}},
+		},
+		{
+			name: "ordered by score then URL",
+			perConcept: [][]scraper.EducationalResource{
+				{resource("limits", "https://b.org", 0.5), resource("limits", "https://c.org", 0.9)},
+				{resource("derivatives", "https://a.org", 0.5)},
+			},
+			wantURLs:     []string{"https://c.org", "https://a.org", "https://b.org"},
+			wantConcepts: []string{"limits", "derivatives", "limits"},
+		},
+		{
+			name: "best-scoring copy of a shared URL kept",
+			perConcept: [][]scraper.EducationalResource{
+				{resource("limits", "https://a.org", 0.4)},
+				{resource("derivatives", "https://a.org", 0.8)},
+			},
+			wantURLs:     []string{"https://a.org"},
+			wantConcepts: []string{"derivatives"},
+		},
+		{
+			name: "tie goes to the earlier concept",
+			perConcept: [][]scraper.EducationalResource{
+				{resource("limits", "https://a.org", 0.6)},
+				{resource("derivatives", "https://a.org", 0.6)},
+			},
+			wantURLs:     []string{"https://a.org"},
+			wantConcepts: []string{"limits"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls, concepts []string
+			for _, r := range mergeConceptResources(tt.perConcept, byQuality) {
+				urls = append(urls, r.URL)
+				concepts = append(concepts, r.ConceptID)
+			}
+			if !reflect.DeepEqual(urls, tt.wantURLs) || !reflect.DeepEqual(concepts, tt.wantConcepts) {
+				t.Errorf("merged = %v %v, want %v %v", urls, concepts, tt.wantURLs, tt.wantConcepts)
+			}
+		})
+	}
+}
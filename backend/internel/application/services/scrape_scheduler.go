@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"mathprereq/internel/core/config"
+	scraper "mathprereq/internel/data/webscraper"
+	"mathprereq/internel/domain/repositories"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// ScrapeRunSummary describes one pass of the scrape scheduler
+type ScrapeRunSummary struct {
+	StartedAt       time.Time     `json:"started_at"`
+	Duration        time.Duration `json:"duration"`
+	Considered      int           `json:"considered"`
+	Due             int           `json:"due"`
+	Deferred        int           `json:"deferred"`
+	Scraped         int           `json:"scraped"`
+	Failed          int           `json:"failed"`
+	ResourcesStored int           `json:"resources_stored"`
+	Interrupted     bool          `json:"interrupted"`
+}
+
+// maxOverdueRatio caps how overdue a concept counts as when prioritizing
+const maxOverdueRatio = 2.0
+
+// neverScrapedOverdue is how overdue a concept never scraped counts as: just due, so
+// popularity rather than a missing history decides where it goes
+const neverScrapedOverdue = 1.0
+
+// maxEmptyScrapeBackoff caps the doublings of a concept's interval after consecutive
+// scrapes that stored nothing
+const maxEmptyScrapeBackoff = 3
+
+// dueConcept is a popular concept whose re-scrape interval has elapsed
+type dueConcept struct {
+	name       string
+	conceptID  string
+	queryCount int64
+	// overdue is the time since the last scrape over the interval, capped at maxOverdueRatio
+	overdue  float64
+	priority float64
+}
+
+// ScrapeScheduler re-scrapes concepts on intervals derived from their query popularity,
+// so frequently asked concepts stay fresher than rarely asked ones
+type ScrapeScheduler struct {
+	config    config.ScraperConfig
+	queryRepo repositories.QueryRepository
+	scraper   *scraper.EducationalWebScraper
+	limiter   *rate.Limiter
+	logger    *zap.Logger
+}
+
+func NewScrapeScheduler(
+	cfg config.ScraperConfig,
+	queryRepo repositories.QueryRepository,
+	resourceScraper *scraper.EducationalWebScraper,
+	logger *zap.Logger,
+) *ScrapeScheduler {
+	if cfg.ScheduleCheckInterval <= 0 {
+		cfg.ScheduleCheckInterval = time.Hour
+	}
+	if cfg.ScheduleDefaultInterval <= 0 {
+		cfg.ScheduleDefaultInterval = 7 * 24 * time.Hour
+	}
+	if cfg.ScheduleConceptLimit <= 0 {
+		cfg.ScheduleConceptLimit = 200
+	}
+	if cfg.ScheduleRate <= 0 {
+		cfg.ScheduleRate = 6
+	}
+	if cfg.SchedulePopularityWeight < 0 || cfg.SchedulePopularityWeight > 1 {
+		cfg.SchedulePopularityWeight = 0.7
+	}
+
+	return &ScrapeScheduler{
+		config:    cfg,
+		queryRepo: queryRepo,
+		scraper:   resourceScraper,
+		limiter:   rate.NewLimiter(rate.Every(time.Minute/time.Duration(cfg.ScheduleRate)), 1),
+		logger:    logger,
+	}
+}
+
+// IntervalFor returns the re-scrape interval for a concept queried queryCount times
+func (s *ScrapeScheduler) IntervalFor(queryCount int64) time.Duration {
+	for _, tier := range s.config.ScheduleTiers {
+		if queryCount >= tier.MinQueries {
+			return tier.Interval
+		}
+	}
+	return s.config.ScheduleDefaultInterval
+}
+
+// prioritizeDueConcepts orders due concepts by priority, a blend of popularity (query
+// count relative to the most queried due concept) weighted by popularityWeight and
+// staleness (how overdue the concept is) weighted by the rest. Ties go to the more
+// queried concept.
+func prioritizeDueConcepts(due []dueConcept, popularityWeight float64) {
+	var maxCount int64
+	for _, concept := range due {
+		if concept.queryCount > maxCount {
+			maxCount = concept.queryCount
+		}
+	}
+
+	for i := range due {
+		popularity := 0.0
+		if maxCount > 0 {
+			popularity = float64(due[i].queryCount) / float64(maxCount)
+		}
+		staleness := due[i].overdue / maxOverdueRatio
+		due[i].priority = popularityWeight*popularity + (1-popularityWeight)*staleness
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		if due[i].priority != due[j].priority {
+			return due[i].priority > due[j].priority
+		}
+		return due[i].queryCount > due[j].queryCount
+	})
+}
+
+// selectDueConcepts returns the popular concepts whose interval has elapsed since their
+// last scrape attempt, or since their newest stored resource when no attempt is
+// recorded. Each attempt in a row that stored nothing doubles the interval, up to
+// maxEmptyScrapeBackoff times, so concepts the sources have nothing for are not
+// re-scraped every run.
+func (s *ScrapeScheduler) selectDueConcepts(
+	popular []repositories.ConceptPopularity,
+	conceptIDs []string,
+	lastScraped map[string]time.Time,
+	attempts map[string]scraper.ScrapeAttempt,
+	now time.Time,
+) []dueConcept {
+	var due []dueConcept
+	for i, concept := range popular {
+		interval := s.IntervalFor(concept.QueryCount)
+		last, scraped := lastScraped[conceptIDs[i]]
+		if attempt, ok := attempts[conceptIDs[i]]; ok {
+			if attempt.AttemptedAt.After(last) {
+				last, scraped = attempt.AttemptedAt, true
+			}
+			interval <<= min(attempt.EmptyStreak, maxEmptyScrapeBackoff)
+		}
+
+		overdue := neverScrapedOverdue
+		if scraped {
+			elapsed := now.Sub(last)
+			if elapsed < interval {
+				continue
+			}
+			if interval > 0 {
+				overdue = math.Min(float64(elapsed)/float64(interval), maxOverdueRatio)
+			}
+		}
+		due = append(due, dueConcept{
+			name:       concept.ConceptName,
+			conceptID:  conceptIDs[i],
+			queryCount: concept.QueryCount,
+			overdue:    overdue,
+		})
+	}
+	return due
+}
+
+// RunScheduledScrapes scrapes popular concepts whose interval has elapsed since their
+// last scrape (see selectDueConcepts), highest priority first (see
+// prioritizeDueConcepts), and records each attempt. At most
+// ScheduleMaxScrapesPerRun are scraped per run; the rest are deferred to the next one.
+// A failing concept does not stop the run; cancellation does.
+func (s *ScrapeScheduler) RunScheduledScrapes(ctx context.Context) (*ScrapeRunSummary, error) {
+	summary := &ScrapeRunSummary{StartedAt: time.Now()}
+	defer func() { summary.Duration = time.Since(summary.StartedAt) }()
+
+	popular, err := s.queryRepo.GetPopularConcepts(ctx, s.config.ScheduleConceptLimit)
+	if err != nil {
+		return summary, fmt.Errorf("failed to get popular concepts: %w", err)
+	}
+	summary.Considered = len(popular)
+
+	conceptIDs := make([]string, len(popular))
+	for i, concept := range popular {
+		conceptIDs[i] = s.scraper.ResolveConceptID(ctx, concept.ConceptName)
+	}
+
+	lastScraped, err := s.scraper.LastScrapedAt(ctx, conceptIDs)
+	if err != nil {
+		return summary, err
+	}
+	attempts, err := s.scraper.LastScrapeAttempts(ctx, conceptIDs)
+	if err != nil {
+		return summary, err
+	}
+
+	due := s.selectDueConcepts(popular, conceptIDs, lastScraped, attempts, time.Now())
+	summary.Due = len(due)
+
+	prioritizeDueConcepts(due, s.config.SchedulePopularityWeight)
+	if limit := s.config.ScheduleMaxScrapesPerRun; limit > 0 && len(due) > limit {
+		summary.Deferred = len(due) - limit
+		due = due[:limit]
+	}
+
+	for _, concept := range due {
+		if err := s.limiter.Wait(ctx); err != nil {
+			summary.Interrupted = true
+			break
+		}
+
+		stored, err := s.scraper.RefreshConcept(ctx, concept.name)
+		if ctx.Err() == nil {
+			if recordErr := s.scraper.RecordScrapeAttempt(ctx, concept.conceptID, stored); recordErr != nil {
+				s.logger.Warn("Failed to record scrape attempt",
+					zap.String("concept", concept.name),
+					zap.Error(recordErr))
+			}
+		}
+		if err != nil {
+			summary.Failed++
+			s.logger.Warn("Scheduled scrape failed",
+				zap.String("concept", concept.name),
+				zap.Float64("priority", concept.priority),
+				zap.Error(err))
+			continue
+		}
+		summary.Scraped++
+		summary.ResourcesStored += stored
+	}
+
+	s.logger.Info("Scheduled scrape run completed",
+		zap.Int("considered", summary.Considered),
+		zap.Int("due", summary.Due),
+		zap.Int("deferred", summary.Deferred),
+		zap.Int("scraped", summary.Scraped),
+		zap.Int("failed", summary.Failed),
+		zap.Int("resources_stored", summary.ResourcesStored),
+		zap.Bool("interrupted", summary.Interrupted),
+		zap.Duration("duration", time.Since(summary.StartedAt)))
+
+	return summary, nil
+}
+
+// Start runs scheduled scrapes every check interval until ctx is cancelled
+func (s *ScrapeScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.config.ScheduleCheckInterval)
+	defer ticker.Stop()
+
+	s.logger.Info("Scrape scheduler started",
+		zap.Duration("check_interval", s.config.ScheduleCheckInterval))
+
+	for {
+		if _, err := s.RunScheduledScrapes(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Scheduled scrape run failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Scrape scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
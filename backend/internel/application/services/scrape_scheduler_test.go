@@ -0,0 +1,151 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"mathprereq/internel/core/config"
+	scraper "mathprereq/internel/data/webscraper"
+	"mathprereq/internel/domain/repositories"
+)
+
+func TestPrioritizeDueConcepts(t *testing.T) {
+	tests := []struct {
+		name             string
+		popularityWeight float64
+		due              []dueConcept
+		want             []string
+	}{
+		{
+			name:             "popularity only",
+			popularityWeight: 1,
+			due: []dueConcept{
+				{name: "limits", queryCount: 10, overdue: 2},
+				{name: "series", queryCount: 50, overdue: 1},
+			},
+			want: []string{"series", "limits"},
+		},
+		{
+			name:             "staleness only",
+			popularityWeight: 0,
+			due: []dueConcept{
+				{name: "series", queryCount: 50, overdue: 1},
+				{name: "limits", queryCount: 10, overdue: 2},
+			},
+			want: []string{"limits", "series"},
+		},
+		{
+			name:             "a very overdue concept overtakes a slightly more popular one",
+			popularityWeight: 0.5,
+			due: []dueConcept{
+				{name: "series", queryCount: 50, overdue: 1},
+				{name: "limits", queryCount: 40, overdue: 2},
+			},
+			want: []string{"limits", "series"},
+		},
+		{
+			name:             "ties go to the more queried concept",
+			popularityWeight: 0,
+			due: []dueConcept{
+				{name: "limits", queryCount: 10, overdue: 1},
+				{name: "series", queryCount: 50, overdue: 1},
+			},
+			want: []string{"series", "limits"},
+		},
+		{
+			name:             "a never-scraped concept does not outrank a more popular overdue one",
+			popularityWeight: 0.7,
+			due: []dueConcept{
+				{name: "new", queryCount: 5, overdue: neverScrapedOverdue},
+				{name: "limits", queryCount: 50, overdue: 1.5},
+			},
+			want: []string{"limits", "new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prioritizeDueConcepts(tt.due, tt.popularityWeight)
+			var got []string
+			for _, concept := range tt.due {
+				got = append(got, concept.name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectDueConcepts(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	s := &ScrapeScheduler{config: config.ScraperConfig{ScheduleDefaultInterval: 7 * day}}
+
+	tests := []struct {
+		name        string
+		lastScraped map[string]time.Time
+		attempts    map[string]scraper.ScrapeAttempt
+		wantDue     bool
+		wantOverdue float64
+	}{
+		{
+			name:        "never scraped is just due",
+			wantDue:     true,
+			wantOverdue: neverScrapedOverdue,
+		},
+		{
+			name:        "scraped within the interval",
+			lastScraped: map[string]time.Time{"limits": now.Add(-3 * day)},
+		},
+		{
+			name:        "overdue since the last resource",
+			lastScraped: map[string]time.Time{"limits": now.Add(-14 * day)},
+			wantDue:     true,
+			wantOverdue: 2,
+		},
+		{
+			name:        "a recent attempt counts even without resources",
+			lastScraped: map[string]time.Time{"limits": now.Add(-14 * day)},
+			attempts:    map[string]scraper.ScrapeAttempt{"limits": {AttemptedAt: now.Add(-day), Stored: 3}},
+		},
+		{
+			name:     "an empty attempt doubles the interval",
+			attempts: map[string]scraper.ScrapeAttempt{"limits": {AttemptedAt: now.Add(-10 * day), EmptyStreak: 1}},
+		},
+		{
+			name:        "due again once the doubled interval has passed",
+			attempts:    map[string]scraper.ScrapeAttempt{"limits": {AttemptedAt: now.Add(-21 * day), EmptyStreak: 1}},
+			wantDue:     true,
+			wantOverdue: 1.5,
+		},
+		{
+			name:     "backoff is capped",
+			attempts: map[string]scraper.ScrapeAttempt{"limits": {AttemptedAt: now.Add(-57 * day), EmptyStreak: 10}},
+			wantDue:  true,
+			// 57 days over the capped 56-day interval
+			wantOverdue: 57.0 / 56.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			popular := []repositories.ConceptPopularity{{ConceptName: "Limits", QueryCount: 3}}
+			due := s.selectDueConcepts(popular, []string{"limits"}, tt.lastScraped, tt.attempts, now)
+
+			if !tt.wantDue {
+				if len(due) != 0 {
+					t.Fatalf("due = %+v, want none", due)
+				}
+				return
+			}
+			if len(due) != 1 || due[0].conceptID != "limits" {
+				t.Fatalf("due = %+v, want limits", due)
+			}
+			if diff := due[0].overdue - tt.wantOverdue; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("overdue = %v, want %v", due[0].overdue, tt.wantOverdue)
+			}
+		})
+	}
+}
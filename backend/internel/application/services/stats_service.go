@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mathprereq/internel/domain/services"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// Backend names used as keys in StatsReport.Errors
+const (
+	statsBackendGraph     = "graph"
+	statsBackendVector    = "vector_store"
+	statsBackendQueries   = "queries"
+	statsBackendResources = "resources"
+)
+
+// statsCollector fetches one backend's stats and stores them in the report
+type statsCollector func(ctx context.Context, report *services.StatsReport) error
+
+// GetStatsReport gathers stats from every backend concurrently. Each backend call gets
+// its own timeout, and the whole report returns by the overall deadline even if a
+// backend ignores cancellation; unfinished or failed backends are reported in Errors.
+func (s *queryService) GetStatsReport(ctx context.Context) (*services.StatsReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.StatsDeadline)
+	defer cancel()
+
+	collectors := map[string]statsCollector{
+		statsBackendGraph: func(ctx context.Context, report *services.StatsReport) error {
+			stats, err := s.conceptRepo.GetStats(ctx)
+			if err != nil {
+				return err
+			}
+			report.Graph = stats
+			return nil
+		},
+		statsBackendVector: func(ctx context.Context, report *services.StatsReport) error {
+			stats, err := s.vectorRepo.GetStats(ctx)
+			if err != nil {
+				return err
+			}
+			report.VectorStore = stats
+			return nil
+		},
+		statsBackendQueries: func(ctx context.Context, report *services.StatsReport) error {
+			stats, err := s.queryRepo.GetQueryStats(ctx)
+			if err != nil {
+				return err
+			}
+			report.Queries = stats
+			return nil
+		},
+	}
+	if s.resourceScraper != nil {
+		collectors[statsBackendResources] = func(ctx context.Context, report *services.StatsReport) error {
+			stats, err := s.resourceScraper.GetResourceStats(ctx)
+			if err != nil {
+				return err
+			}
+			report.Resources = stats
+			return nil
+		}
+	}
+
+	report := &services.StatsReport{
+		Errors:      make(map[string]string),
+		GeneratedAt: time.Now(),
+	}
+
+	var mu sync.Mutex
+	pending := make(map[string]bool, len(collectors))
+	for name := range collectors {
+		pending[name] = true
+	}
+	closed := false
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(s.config.StatsConcurrency)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for name, collect := range collectors {
+			g.Go(func() error {
+				callCtx, callCancel := context.WithTimeout(gCtx, s.config.StatsBackendTimeout)
+				defer callCancel()
+
+				// Collect into a scratch report so a late result never races the returned one
+				var partial services.StatsReport
+				err := collect(callCtx, &partial)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if closed {
+					return nil
+				}
+				delete(pending, name)
+				if err != nil {
+					report.Errors[name] = err.Error()
+					return nil // Keep the other backends running
+				}
+				mergeStatsReport(report, &partial)
+				return nil
+			})
+		}
+		_ = g.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	closed = true
+	for name := range pending {
+		report.Errors[name] = fmt.Sprintf("timed out: %v", ctx.Err())
+	}
+	report.Partial = len(report.Errors) > 0
+
+	if report.Partial {
+		s.logger.Warn("Stats report is partial",
+			zap.Any("errors", report.Errors),
+			zap.Duration("duration", time.Since(report.GeneratedAt)))
+	}
+
+	return report, nil
+}
+
+// mergeStatsReport copies the sections a collector filled in into the shared report
+func mergeStatsReport(dst, src *services.StatsReport) {
+	if src.Graph != nil {
+		dst.Graph = src.Graph
+	}
+	if src.VectorStore != nil {
+		dst.VectorStore = src.VectorStore
+	}
+	if src.Queries != nil {
+		dst.Queries = src.Queries
+	}
+	if src.Resources != nil {
+		dst.Resources = src.Resources
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"mathprereq/internel/core/config"
+	"mathprereq/internel/domain/repositories"
+	"mathprereq/internel/types"
+
+	"go.uber.org/zap"
+)
+
+// statsBehavior is how a fake backend answers a stats call
+type statsBehavior int
+
+const (
+	statsOK statsBehavior = iota
+	statsFail
+	statsSlow  // waits for its context to end
+	statsStuck // ignores cancellation until the test ends
+)
+
+func runStatsBehavior(ctx context.Context, behavior statsBehavior, release <-chan struct{}) error {
+	switch behavior {
+	case statsFail:
+		return errors.New("backend down")
+	case statsSlow:
+		<-ctx.Done()
+		return ctx.Err()
+	case statsStuck:
+		<-release
+		return nil
+	}
+	return nil
+}
+
+type statsConceptRepo struct {
+	repositories.ConceptRepository
+	behavior statsBehavior
+	release  <-chan struct{}
+}
+
+func (r *statsConceptRepo) GetStats(ctx context.Context) (*types.SystemStats, error) {
+	if err := runStatsBehavior(ctx, r.behavior, r.release); err != nil {
+		return nil, err
+	}
+	return &types.SystemStats{TotalConcepts: 7}, nil
+}
+
+type statsVectorRepo struct {
+	repositories.VectorRepository
+	behavior statsBehavior
+	release  <-chan struct{}
+}
+
+func (r *statsVectorRepo) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	if err := runStatsBehavior(ctx, r.behavior, r.release); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"total_chunks": 3}, nil
+}
+
+type statsQueryRepo struct {
+	repositories.QueryRepository
+	behavior statsBehavior
+	release  <-chan struct{}
+}
+
+func (r *statsQueryRepo) GetQueryStats(ctx context.Context) (*repositories.QueryStats, error) {
+	if err := runStatsBehavior(ctx, r.behavior, r.release); err != nil {
+		return nil, err
+	}
+	return &repositories.QueryStats{TotalQueries: 5}, nil
+}
+
+func TestGetStatsReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		graph      statsBehavior
+		vector     statsBehavior
+		queries    statsBehavior
+		wantErrors map[string]string // backend -> error substring
+	}{
+		{name: "all backends answer"},
+		{
+			name:       "failed backend is reported",
+			vector:     statsFail,
+			wantErrors: map[string]string{statsBackendVector: "backend down"},
+		},
+		{
+			name:       "slow backend hits its own timeout",
+			graph:      statsSlow,
+			wantErrors: map[string]string{statsBackendGraph: "deadline exceeded"},
+		},
+		{
+			name:       "backend ignoring cancellation is cut off by the deadline",
+			queries:    statsStuck,
+			wantErrors: map[string]string{statsBackendQueries: "timed out"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			defer close(release)
+
+			s := &queryService{
+				config: config.QueryConfig{
+					StatsBackendTimeout: 20 * time.Millisecond,
+					StatsDeadline:       100 * time.Millisecond,
+					StatsConcurrency:    3,
+				},
+				conceptRepo: &statsConceptRepo{behavior: tt.graph, release: release},
+				vectorRepo:  &statsVectorRepo{behavior: tt.vector, release: release},
+				queryRepo:   &statsQueryRepo{behavior: tt.queries, release: release},
+				logger:      zap.NewNop(),
+			}
+
+			start := time.Now()
+			report, err := s.GetStatsReport(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("report took %v, want it bounded by the deadline", elapsed)
+			}
+
+			if report.Partial != (len(tt.wantErrors) > 0) || len(report.Errors) != len(tt.wantErrors) {
+				t.Fatalf("partial = %v, errors = %v, want %v", report.Partial, report.Errors, tt.wantErrors)
+			}
+			for backend, want := range tt.wantErrors {
+				if !strings.Contains(report.Errors[backend], want) {
+					t.Errorf("errors[%s] = %q, want it to mention %q", backend, report.Errors[backend], want)
+				}
+			}
+			if _, failed := tt.wantErrors[statsBackendGraph]; !failed && (report.Graph == nil || report.Graph.TotalConcepts != 7) {
+				t.Errorf("graph stats = %+v", report.Graph)
+			}
+			if _, failed := tt.wantErrors[statsBackendVector]; !failed && report.VectorStore["total_chunks"] != 3 {
+				t.Errorf("vector stats = %v", report.VectorStore)
+			}
+			if _, failed := tt.wantErrors[statsBackendQueries]; !failed && (report.Queries == nil || report.Queries.TotalQueries != 5) {
+				t.Errorf("query stats = %+v", report.Queries)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	scraper "mathprereq/internel/data/webscraper"
+	"mathprereq/internel/domain/repositories"
+	"mathprereq/internel/domain/services"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// GetStudyGuide orders targetConcept's prerequisite path so every concept follows its
+// prerequisites, ending with the target, and attaches up to resourcesPerConcept of the
+// best ranked resources to each step. A step whose resources cannot be loaded is kept
+// with an empty resource list.
+func (s *queryService) GetStudyGuide(ctx context.Context, targetConcept string, resourcesPerConcept int) (*services.StudyGuide, error) {
+	targetIDs, err := s.conceptRepo.ResolveIDs(ctx, []string{targetConcept})
+	if err != nil {
+		return nil, err
+	}
+	targetID, ok := targetIDs[targetConcept]
+	if !ok || targetID == "" {
+		return nil, fmt.Errorf("%w: %s", repositories.ErrConceptNotFound, targetConcept)
+	}
+
+	graph, err := s.conceptRepo.GetPathGraph(ctx, []string{targetID})
+	if err != nil {
+		return nil, err
+	}
+
+	path := topologicalOrder(graph.Nodes, graph.Edges)
+
+	guide := &services.StudyGuide{Steps: make([]services.StudyGuideStep, len(path))}
+	for i, concept := range path {
+		guide.Steps[i] = services.StudyGuideStep{
+			Step:      i + 1,
+			Concept:   concept,
+			Resources: []scraper.EducationalResource{},
+		}
+		if concept.ID == targetID {
+			guide.TargetConcept = concept
+		}
+	}
+
+	if s.resourceScraper != nil && resourcesPerConcept > 0 {
+		// Each lookup writes only its own step
+		g, gCtx := errgroup.WithContext(ctx)
+		g.SetLimit(resourceFetchConcurrency)
+		for i := range guide.Steps {
+			step := &guide.Steps[i]
+			g.Go(func() error {
+				resources, err := s.resourceScraper.GetResourcesForConcept(gCtx, step.Concept.ID, resourcesPerConcept, false)
+				if err != nil {
+					s.logger.Warn("Failed to get resources for study guide step",
+						zap.String("concept_id", step.Concept.ID),
+						zap.Error(err))
+					return nil
+				}
+				if len(resources) > 0 {
+					step.Resources = resources
+				}
+				return nil
+			})
+		}
+		_ = g.Wait()
+	}
+
+	s.logger.Info("Built study guide",
+		zap.String("target", targetConcept),
+		zap.Int("steps", len(guide.Steps)))
+
+	return guide, nil
+}
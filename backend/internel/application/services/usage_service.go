@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"mathprereq/internel/domain/services"
+	"sort"
+	"time"
+)
+
+const usageCurrency = "USD"
+
+// GetUsageSummary totals LLM token usage per model and per day since the given time and
+// estimates its cost from the configured per-model prices.
+func (s *queryService) GetUsageSummary(ctx context.Context, since time.Time) (*services.UsageSummary, error) {
+	usage, err := s.queryRepo.GetUsage(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	summary := &services.UsageSummary{
+		Since:    since,
+		Currency: usageCurrency,
+		Priced:   true,
+		ByModel:  []services.ModelUsageCost{},
+		Daily:    []services.DailyUsage{},
+	}
+
+	byModel := make(map[string]*services.ModelUsageCost)
+	daily := make(map[time.Time]*services.DailyUsage)
+
+	for _, u := range usage {
+		price, priced := s.config.ModelPrices[u.Model]
+		cost := float64(u.TokensUsed) / 1e6 * price
+
+		model, ok := byModel[u.Model]
+		if !ok {
+			model = &services.ModelUsageCost{Model: u.Model, Priced: priced}
+			byModel[u.Model] = model
+		}
+		model.QueryCount += u.QueryCount
+		model.UntrackedQueries += u.UntrackedQueries
+		model.TokensUsed += u.TokensUsed
+		model.EstimatedCost += cost
+
+		day, ok := daily[u.Date]
+		if !ok {
+			day = &services.DailyUsage{Date: u.Date}
+			daily[u.Date] = day
+		}
+		day.QueryCount += u.QueryCount
+		day.UntrackedQueries += u.UntrackedQueries
+		day.TokensUsed += u.TokensUsed
+		day.EstimatedCost += cost
+
+		summary.TotalQueries += u.QueryCount
+		summary.UntrackedQueries += u.UntrackedQueries
+		summary.TotalTokens += u.TokensUsed
+		summary.EstimatedCost += cost
+		if !priced && u.TokensUsed > 0 {
+			summary.Priced = false
+		}
+	}
+
+	for _, model := range byModel {
+		summary.ByModel = append(summary.ByModel, *model)
+	}
+	sort.Slice(summary.ByModel, func(i, j int) bool {
+		return summary.ByModel[i].TokensUsed > summary.ByModel[j].TokensUsed
+	})
+
+	for _, day := range daily {
+		summary.Daily = append(summary.Daily, *day)
+	}
+	sort.Slice(summary.Daily, func(i, j int) bool {
+		return summary.Daily[i].Date.Before(summary.Daily[j].Date)
+	})
+
+	return summary, nil
+}
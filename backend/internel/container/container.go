@@ -15,7 +15,9 @@ import (
 	infrastructurerepos "mathprereq/internel/infrastructure/repositories"
 
 	"mathprereq/internel/domain/repositories"
+	"mathprereq/pkg/breaker"
 	"mathprereq/pkg/logger"
+	"mathprereq/pkg/metrics"
 	"strings"
 	"time"
 
@@ -34,9 +36,15 @@ type Container interface {
 
 	// GetResourceScraper returns the web scraper for educational resources
 	GetResourceScraper() *scraper.EducationalWebScraper
+	// GetScrapeScheduler returns the popularity-based scrape scheduler
+	GetScrapeScheduler() *services.ScrapeScheduler
 
 	// Health check for all services
 	HealthCheck(ctx context.Context) map[string]bool
+	// DetailedHealthCheck adds each backend's circuit breaker state to HealthCheck
+	DetailedHealthCheck(ctx context.Context) *HealthReport
+	// RunDiagnostics performs deeper pre-deployment checks of every dependency
+	RunDiagnostics(ctx context.Context) (*DiagnosticsReport, error)
 
 	// Graceful shutdown
 	Shutdown(ctx context.Context) error
@@ -59,17 +67,38 @@ type AppContainer struct {
 	conceptRepo repositories.ConceptRepository
 	queryRepo   repositories.QueryRepository
 	vectorRepo  repositories.VectorRepository
+	// resourceRepo is nil without MongoDB
+	resourceRepo repositories.ResourceRepository
+	// breakers guard the repositories, one per backend
+	breakers []*breaker.Breaker
 
 	// Services
 	queryService domainServices.QueryService
+
+	// Background scrape scheduler, running until the root context is cancelled
+	scrapeScheduler *services.ScrapeScheduler
+	schedulerDone   chan struct{}
+
+	// rootCtx is the parent of all background work; cancelling it on shutdown
+	// stops the scheduler and background scrapes at once
+	rootCtx context.Context
+	cancel  context.CancelFunc
 }
 
+// shutdownDrainTimeout bounds the wait for background work when Shutdown's context
+// has no deadline
+const shutdownDrainTimeout = 30 * time.Second
+
 func NewContainer(cfg *config.Config) (Container, error) {
+	logger.SetSampleRate(cfg.Logging.SampleRate)
 	logger := logger.MustGetLogger()
 
+	rootCtx, cancel := context.WithCancel(context.Background())
 	container := &AppContainer{
-		config: cfg,
-		logger: logger,
+		config:  cfg,
+		logger:  logger,
+		rootCtx: rootCtx,
+		cancel:  cancel,
 	}
 
 	if err := container.initializeClients(); err != nil {
@@ -155,6 +184,9 @@ func (c *AppContainer) initializeClientsEnhanced() error {
 	}
 	c.llmClient = llmClient
 
+	// Resolve identified concepts to graph IDs in the same call that extracts them
+	llmClient.SetConceptResolver(c.neo4jClient)
+
 	c.logger.Info("LLM client initialized successfully")
 
 	c.logger.Info("All data clients initialized successfully with enhanced authentication")
@@ -192,6 +224,7 @@ func (c *AppContainer) initializeRepositories() error {
 				databaseName = "mathprereq" // default database name
 			}
 			mongoRepo = infrastructurerepos.NewMongoQueryRepository(rawMongoClient, databaseName, c.logger)
+			c.resourceRepo = infrastructurerepos.NewMongoResourceRepository(rawMongoClient, databaseName, c.logger)
 		} else {
 			c.logger.Warn("Raw MongoDB client is nil, using nil repository")
 		}
@@ -203,14 +236,47 @@ func (c *AppContainer) initializeRepositories() error {
 
 	weaviateRepo := infrastructurerepos.NewWeaviateVectorRepository(c.weaviateClient, c.logger)
 
-	c.conceptRepo = neo4jRepo
-	c.queryRepo = mongoRepo
-	c.vectorRepo = weaviateRepo
+	c.conceptRepo = infrastructurerepos.NewBreakerConceptRepository(neo4jRepo, c.newBreaker("neo4j", c.config.Neo4j.Breaker))
+	c.vectorRepo = infrastructurerepos.NewBreakerVectorRepository(weaviateRepo, c.newBreaker("weaviate", c.config.Weaviate.Breaker))
+	if mongoRepo != nil {
+		// Both MongoDB repositories share one breaker, as they share the backend
+		mongoBreaker := c.newBreaker("mongodb", c.config.MongoDB.Breaker)
+		c.queryRepo = infrastructurerepos.NewBreakerQueryRepository(mongoRepo, mongoBreaker)
+		c.resourceRepo = infrastructurerepos.NewBreakerResourceRepository(c.resourceRepo, mongoBreaker)
+	}
 
 	c.logger.Info("All repositories initialized successfully")
 	return nil
 }
 
+// newBreaker creates a backend's circuit breaker, logging and exporting its transitions
+func (c *AppContainer) newBreaker(backend string, cfg config.BreakerConfig) *breaker.Breaker {
+	b := breaker.New(backend, breaker.Config{
+		FailureThreshold: cfg.FailureThreshold,
+		OpenTimeout:      cfg.OpenTimeout,
+	}, infrastructurerepos.IsBackendFailure)
+
+	metrics.BreakerState.WithLabelValues(backend).Set(float64(breaker.StateClosed))
+	b.OnStateChange(func(name string, from, to breaker.State) {
+		metrics.BreakerState.WithLabelValues(name).Set(float64(to))
+		metrics.BreakerTransitions.WithLabelValues(name, to.String()).Inc()
+		if to == breaker.StateOpen {
+			c.logger.Warn("Circuit breaker opened, failing fast",
+				zap.String("backend", name),
+				zap.String("from", from.String()),
+				zap.Duration("open_timeout", cfg.OpenTimeout))
+			return
+		}
+		c.logger.Info("Circuit breaker state changed",
+			zap.String("backend", name),
+			zap.String("from", from.String()),
+			zap.String("to", to.String()))
+	})
+
+	c.breakers = append(c.breakers, b)
+	return b
+}
+
 func (c *AppContainer) initializeServices() error {
 	c.logger.Info("Initializing services")
 
@@ -219,6 +285,8 @@ func (c *AppContainer) initializeServices() error {
 
 	// Initialize query service with all dependencies (scraper will be added later)
 	c.queryService = services.NewQueryService(
+		c.rootCtx,
+		c.config.Query,
 		c.conceptRepo,
 		c.queryRepo,
 		c.vectorRepo,
@@ -244,14 +312,45 @@ func (c *AppContainer) initializeScraper() error {
 
 	// Create scraper configuration
 	scraperConfig := scraper.ScraperConfig{
-		MaxConcurrentRequests: 3,                // Reduced from 5
-		RequestTimeout:        45 * time.Second, // Increased from 30s
-		RateLimit:             1.5,              // Slower rate to avoid timeouts
-		UserAgent:             "MathPrereq-ResourceFinder/2.0",
-		DatabaseName:          "mathprereq",
-		CollectionName:        "educational_resources",
-		MaxRetries:            2,               // Reduced retries
-		RetryDelay:            3 * time.Second, // Increased delay
+		RequestTimeout:            45 * time.Second, // Increased from 30s
+		RateLimit:                 1.5,              // Slower rate to avoid timeouts
+		UserAgent:                 "MathPrereq-ResourceFinder/2.0",
+		DatabaseName:              "mathprereq",
+		CollectionName:            "educational_resources",
+		MaxRetries:                2,               // Reduced retries
+		RetryDelay:                3 * time.Second, // Increased delay
+		StopWords:                 c.config.Scraper.StopWords,
+		PreserveStopWords:         c.config.Scraper.PreserveStopWords,
+		SearchTermTemplates:       c.config.Scraper.SearchTermTemplates,
+		BeginnerKeywords:          c.config.Scraper.BeginnerKeywords,
+		AdvancedKeywords:          c.config.Scraper.AdvancedKeywords,
+		EnsureConceptNodes:        c.config.Scraper.EnsureConceptNodes,
+		ReportDemotionThreshold:   c.config.Scraper.ReportDemotionThreshold,
+		ConceptFeeds:              c.config.Scraper.ConceptFeeds,
+		SufficientResourceCount:   c.config.Scraper.SufficientResourceCount,
+		SufficientResourceQuality: c.config.Scraper.SufficientResourceQuality,
+		VerifiedBoost:             c.config.Scraper.VerifiedBoost,
+		YouTubeScoring:            scraper.YouTubeScoringWeights(c.config.Scraper.YouTubeScoring),
+		CrawlDelays:               c.config.Scraper.CrawlDelays,
+		DefaultCrawlDelay:         c.config.Scraper.DefaultCrawlDelay,
+		RespectRobotsCrawlDelay:   c.config.Scraper.RespectRobotsCrawlDelay,
+		RespectRobotsTxt:          c.config.Scraper.RespectRobotsTxt,
+		RobotsTxtTTL:              c.config.Scraper.RobotsTxtTTL,
+		YouTubeAPIKey:             c.config.Scraper.YouTubeAPIKey,
+		MaxResourcesPerType:       c.config.Scraper.MaxResourcesPerType,
+		MaxResourcesPerOtherType:  c.config.Scraper.MaxResourcesPerOtherType,
+		MaxResourcesPerConcept:    c.config.Scraper.MaxResourcesPerConcept,
+		StackExchangeResults:      c.config.Scraper.StackExchangeResults,
+		DuplicateTitleThreshold:   c.config.Scraper.DuplicateTitleThreshold,
+		EducationalDomains:        c.config.Scraper.EducationalDomains,
+		MaxResultsPerSource:       c.config.Scraper.MaxResultsPerSource,
+		MinQualityScore:           &c.config.Scraper.MinQualityScore,
+		RescrapeInterval:          c.config.Scraper.RescrapeInterval,
+		ProxyURL:                  c.config.Scraper.ProxyURL,
+		ProxyRotation:             c.config.Scraper.ProxyRotation,
+		// Up to the product of the two searches run at once
+		MaxConcurrentConcepts:          c.config.Scraper.MaxConcurrentConcepts,
+		MaxConcurrentSourcesPerConcept: c.config.Scraper.MaxConcurrentSourcesPerConcept,
 	}
 
 	// Initialize scraper with shared MongoDB client
@@ -260,8 +359,25 @@ func (c *AppContainer) initializeScraper() error {
 		return fmt.Errorf("failed to initialize resource scraper: %w", err)
 	}
 
+	// Key stored resources by the graph's canonical concept IDs
+	resourceScraper.SetConceptResolver(c.neo4jClient)
+
+	// Mirror scraped resources into the domain model through the resource repository
+	if c.config.Scraper.PersistLearningResources && c.resourceRepo != nil {
+		resourceScraper.SetLearningResourceStore(c.resourceRepo)
+	}
+
 	c.resourceScraper = resourceScraper
 
+	c.scrapeScheduler = services.NewScrapeScheduler(c.config.Scraper, c.queryRepo, resourceScraper, c.logger)
+	if c.config.Scraper.ScheduleEnabled {
+		c.schedulerDone = make(chan struct{})
+		go func() {
+			defer close(c.schedulerDone)
+			c.scrapeScheduler.Start(c.rootCtx)
+		}()
+	}
+
 	// Now update the query service with the scraper
 	if err := c.updateQueryServiceWithScraper(); err != nil {
 		return fmt.Errorf("failed to update query service with scraper: %w", err)
@@ -278,6 +394,8 @@ func (c *AppContainer) updateQueryServiceWithScraper() error {
 
 	// Recreate query service with the scraper
 	c.queryService = services.NewQueryService(
+		c.rootCtx,
+		c.config.Query,
 		c.conceptRepo,
 		c.queryRepo,
 		c.vectorRepo,
@@ -326,6 +444,11 @@ func (c *AppContainer) GetRawMongoClient() *mongo.Client {
 	return rawClient
 }
 
+// GetScrapeScheduler returns the popularity-based scrape scheduler
+func (c *AppContainer) GetScrapeScheduler() *services.ScrapeScheduler {
+	return c.scrapeScheduler
+}
+
 // GetResourceScraper returns the web scraper for educational resources
 func (c *AppContainer) GetResourceScraper() *scraper.EducationalWebScraper {
 	return c.resourceScraper
@@ -349,13 +472,78 @@ func (c *AppContainer) HealthCheck(ctx context.Context) map[string]bool {
 	return health
 }
 
-// Graceful shutdown
+// HealthReport is the component health of HealthCheck with the circuit breaker state
+// (closed, open or half_open) of each backend
+type HealthReport struct {
+	Healthy    bool              `json:"healthy"`
+	Components map[string]bool   `json:"components"`
+	Breakers   map[string]string `json:"breakers"`
+}
+
+// DetailedHealthCheck runs HealthCheck and adds the breaker states; the report is
+// healthy only if every component is and no breaker is open
+func (c *AppContainer) DetailedHealthCheck(ctx context.Context) *HealthReport {
+	report := &HealthReport{
+		Healthy:    true,
+		Components: c.HealthCheck(ctx),
+		Breakers:   make(map[string]string, len(c.breakers)),
+	}
+	for _, healthy := range report.Components {
+		if !healthy {
+			report.Healthy = false
+		}
+	}
+	for _, b := range c.breakers {
+		state := b.State()
+		report.Breakers[b.Name()] = state.String()
+		if state == breaker.StateOpen {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+// Graceful shutdown. Background work is stopped and drained before any client is
+// closed, so nothing is left writing through a closed connection: cancel the root
+// context, wait (bounded) for the scheduler and the query service's background
+// saves and scrapes, then close the scraper before the clients it shares.
 func (c *AppContainer) Shutdown(ctx context.Context) error {
 	c.logger.Info("Starting graceful shutdown of container")
 
 	var errs []error
 
-	// Close database connections
+	// Stop accepting new background work
+	c.cancel()
+
+	// Wait for in-flight work, keeping part of ctx for closing clients
+	drainTimeout := shutdownDrainTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		drainTimeout = time.Until(deadline) / 2
+	}
+	drainCtx, cancelDrain := context.WithTimeout(ctx, drainTimeout)
+	defer cancelDrain()
+
+	if c.schedulerDone != nil {
+		select {
+		case <-c.schedulerDone:
+		case <-drainCtx.Done():
+			c.logger.Warn("Scrape scheduler did not stop before the drain deadline")
+		}
+	}
+	if c.queryService != nil {
+		if err := c.queryService.Drain(drainCtx); err != nil {
+			c.logger.Warn("Closing clients with background work still running", zap.Error(err))
+		}
+	}
+
+	// Close the scraper before the MongoDB client it shares
+	if c.resourceScraper != nil {
+		if err := c.resourceScraper.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close resource scraper: %w", err))
+		}
+	}
+
+	// Repositories hold no resources of their own; close the clients behind them
 	if c.mongoClient != nil {
 		if err := c.mongoClient.Close(ctx); err != nil {
 			errs = append(errs, fmt.Errorf("failed to close MongoDB client: %w", err))
@@ -368,6 +556,18 @@ func (c *AppContainer) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	if c.weaviateClient != nil {
+		if err := c.weaviateClient.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close Weaviate client: %w", err))
+		}
+	}
+
+	if c.llmClient != nil {
+		if err := c.llmClient.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close LLM client: %w", err))
+		}
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("shutdown errors: %v", errs)
 	}
@@ -0,0 +1,129 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// diagnosticSearchQuery is the probe sent to the vector store
+const diagnosticSearchQuery = "derivative"
+
+// DiagnosticCheck is the outcome of one dependency check
+type DiagnosticCheck struct {
+	Component string        `json:"component"`
+	Name      string        `json:"name"`
+	Passed    bool          `json:"passed"`
+	Detail    string        `json:"detail,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// DiagnosticsReport lists every dependency check; Passed is set only if all passed
+type DiagnosticsReport struct {
+	Passed      bool              `json:"passed"`
+	Checks      []DiagnosticCheck `json:"checks"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// Print writes the report as one PASS/FAIL line per check followed by a summary
+func (r *DiagnosticsReport) Print(w io.Writer) {
+	failed := 0
+	for _, check := range r.Checks {
+		status := "PASS"
+		if !check.Passed {
+			status = "FAIL"
+			failed++
+		}
+		line := fmt.Sprintf("%s  %-9s %-14s %6dms", status, check.Component, check.Name, check.Duration.Milliseconds())
+		if check.Detail != "" {
+			line += "  " + check.Detail
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(w, "\n%d of %d checks failed\n", failed, len(r.Checks))
+		return
+	}
+	fmt.Fprintf(w, "\nAll %d checks passed\n", len(r.Checks))
+}
+
+// RunDiagnostics checks every dependency more thoroughly than HealthCheck: a MongoDB
+// write and the resource indexes, Neo4j connectivity and graph content, the Weaviate
+// schema and a real vector search, and an LLM call with the configured credentials.
+// Checks run in order and a failure does not stop the rest; the error is only for a
+// cancelled context.
+func (c *AppContainer) RunDiagnostics(ctx context.Context) (*DiagnosticsReport, error) {
+	report := &DiagnosticsReport{Passed: true, GeneratedAt: time.Now()}
+
+	run := func(component, name string, check func(ctx context.Context) (string, error)) {
+		checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		start := time.Now()
+		detail, err := check(checkCtx)
+		result := DiagnosticCheck{
+			Component: component,
+			Name:      name,
+			Passed:    err == nil,
+			Detail:    detail,
+			Duration:  time.Since(start),
+		}
+		if err != nil {
+			result.Detail = err.Error()
+			report.Passed = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	run("mongodb", "write", func(ctx context.Context) (string, error) {
+		return "", c.mongoClient.TestConnection(ctx)
+	})
+	run("mongodb", "indexes", func(ctx context.Context) (string, error) {
+		if c.resourceScraper == nil {
+			return "", errors.New("resource scraper not initialized")
+		}
+		return "", c.resourceScraper.CheckIndexes(ctx)
+	})
+
+	run("neo4j", "graph", func(ctx context.Context) (string, error) {
+		stats, err := c.neo4jClient.GetStats(ctx)
+		if err != nil {
+			return "", err
+		}
+		if concepts, _ := stats["total_concepts"].(int64); concepts == 0 {
+			return "", errors.New("knowledge graph has no concepts")
+		}
+		return fmt.Sprintf("%v concepts, %v prerequisite edges", stats["total_concepts"], stats["total_edges"]), nil
+	})
+
+	run("weaviate", "schema", func(ctx context.Context) (string, error) {
+		missing, err := c.weaviateClient.MissingClasses(ctx)
+		if err != nil {
+			return "", err
+		}
+		if len(missing) > 0 {
+			return "", fmt.Errorf("missing classes: %s", strings.Join(missing, ", "))
+		}
+		return strings.Join(c.weaviateClient.Classes(), ", "), nil
+	})
+	run("weaviate", "search", func(ctx context.Context) (string, error) {
+		results, err := c.weaviateClient.Search(ctx, diagnosticSearchQuery, 1)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d result(s) for %q", len(results), diagnosticSearchQuery), nil
+	})
+
+	run("llm", "credentials", func(ctx context.Context) (string, error) {
+		if err := c.llmClient.Ping(ctx); err != nil {
+			return "", err
+		}
+		return c.llmClient.Model(), nil
+	})
+
+	return report, ctx.Err()
+}
@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// citationInstruction asks the model to name the context chunks it relied on in a
+// trailing line that parseCitations can strip
+const citationInstruction = `After the explanation, add one final line starting with "Sources:" followed by the numbers of the Context entries you actually used, comma-separated (for example "Sources: 1, 3"), or "Sources: none" if you used none of them.`
+
+var (
+	// sourcesLinePattern matches the trailing citation line, tolerating markdown emphasis
+	sourcesLinePattern = regexp.MustCompile(`(?im)^[ \t>*_#-]*sources?[ \t*_]*:[ \t*_]*(.*)$`)
+	citationNumber     = regexp.MustCompile(`\d+`)
+)
+
+// ExplanationResult is a generated explanation with the context chunks it cited.
+// CitedContextIndices are zero-based positions in the request's ContextChunks;
+// MaxTokens is the output token limit the explanation was generated with. Truncated
+// is set when the explanation was trimmed to MaxExplanationChars; FullExplanation
+// then holds the untrimmed text if KeepFullExplanation is enabled.
+type ExplanationResult struct {
+	Explanation         string `json:"explanation"`
+	CitedContextIndices []int  `json:"cited_context_indices"`
+	MaxTokens           int    `json:"max_tokens"`
+	Truncated           bool   `json:"truncated"`
+	FullExplanation     string `json:"full_explanation,omitempty"`
+	// Model is the model that generated the explanation
+	Model string `json:"model"`
+}
+
+// parseCitations removes the last "Sources:" line from a response and returns the
+// distinct cited chunk indices, zero-based and sorted. Numbers outside 1..chunkCount
+// are ignored; a response without the line cites nothing.
+func parseCitations(response string, chunkCount int) (string, []int) {
+	matches := sourcesLinePattern.FindAllStringSubmatchIndex(response, -1)
+	if len(matches) == 0 {
+		return response, []int{}
+	}
+	last := matches[len(matches)-1]
+
+	seen := make(map[int]bool)
+	cited := []int{}
+	for _, raw := range citationNumber.FindAllString(response[last[2]:last[3]], -1) {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > chunkCount || seen[n-1] {
+			continue
+		}
+		seen[n-1] = true
+		cited = append(cited, n-1)
+	}
+	sort.Ints(cited)
+
+	text := strings.TrimSpace(response[:last[0]] + response[last[1]:])
+	return text, cited
+}
@@ -2,88 +2,212 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"mathprereq/internel/core/config"
 	"mathprereq/internel/types"
 	"mathprereq/pkg/logger"
+	"mathprereq/pkg/metrics"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
+	"text/template"
 	"time"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
 	"google.golang.org/genai"
 )
 
 type Client struct {
-	genaiClient *genai.Client
-	config      config.LLMConfig
-	ctx         context.Context
-	cancel      context.CancelFunc
-	logger      *zap.Logger
+	keys   *keyPool
+	config config.LLMConfig
+	ctx    context.Context
+	cancel context.CancelFunc
+	logger *zap.Logger
+
+	// resolver is optional; without it identified concepts carry no graph ID
+	resolver ConceptResolver
+
+	// explanationPrompt renders the system prompt of explanations
+	explanationPrompt *template.Template
+}
+
+// ConceptResolver maps concept names to canonical graph IDs in one batch; names that
+// match no concept are absent from the result
+type ConceptResolver interface {
+	FindConceptIDs(ctx context.Context, conceptNames []string) (map[string]string, error)
+}
+
+// IdentifiedConcept is a concept extracted from a query. ID is the graph ID, empty when
+// the concept is not in the graph; Resolved reports whether the graph was consulted.
+type IdentifiedConcept struct {
+	Name       string `json:"name"`
+	Normalized string `json:"normalized"`
+	ID         string `json:"id,omitempty"`
+	Resolved   bool   `json:"resolved"`
 }
 
 const (
-	DefaultModel      = "gemini-2.0-flash-exp"
-	DefaultMaxTokens  = 4000
-	DefaultTimeout    = 60 * time.Second
-	HealthCheckPrompt = "Respond with 'OK' to confirm you are working."
+	DefaultModel         = "gemini-2.0-flash-exp"
+	DefaultMaxTokens     = 4000
+	DefaultContextWindow = 1048576
+	DefaultTimeout       = 60 * time.Second
+	HealthCheckPrompt    = "Respond with 'OK' to confirm you are working."
+
+	// charsPerToken approximates Gemini's tokenizer for English prose and LaTeX
+	charsPerToken = 4
+
+	DefaultRetryBaseDelay = time.Second
+	DefaultMaxRetryDelay  = 30 * time.Second
+)
+
+// retryInMessagePattern matches the "Please retry in 13.5s" hint Gemini puts in 429 messages
+var retryInMessagePattern = regexp.MustCompile(`(?i)retry in ([0-9]+(?:\.[0-9]+)?(?:ms|s))`)
+
+// LLM operations; identification and explanation may each run on their own model
+const (
+	OperationIdentify             = "identify_concepts"
+	OperationExplain              = "explanation"
+	OperationSuggestPrerequisites = "suggest_prerequisites"
+	OperationDescribeConcept      = "concept_description"
+	OperationHealthCheck          = "health_check"
 )
 
+// ErrPromptTooLarge is returned when a prompt cannot be trimmed to fit the context window
+var ErrPromptTooLarge = errors.New("prompt exceeds model context window")
+
 type ExplanationRequest struct {
 	Query            string          `json:"query"`
 	PrerequisitePath []types.Concept `json:"prerequisite_path"`
 	ContextChunks    []string        `json:"context_chunks"`
+	AudienceLevel    string          `json:"audience_level"`
+	OutputFormat     string          `json:"output_format"`
+	// LowCoverage is set when no course material relevant to the query was retrieved
+	LowCoverage bool `json:"low_coverage"`
+	// MaxTokens lowers the output token limit for this explanation (0 uses the configured limit)
+	MaxTokens int `json:"max_tokens,omitempty"`
+	// SuggestedPrerequisites maps concepts missing from the graph to model-suggested
+	// prerequisites; the prompt marks them as unverified
+	SuggestedPrerequisites map[string][]string `json:"suggested_prerequisites,omitempty"`
+}
+
+// maxSuggestedPrerequisites caps the prerequisites kept from one suggestion
+const maxSuggestedPrerequisites = 5
+
+// ErrMaxTokensExceeded is returned when a request asks for more output tokens than configured
+var ErrMaxTokensExceeded = errors.New("max tokens exceeds configured limit")
+
+// lengthGuidance asks for an explanation that fits a lowered output token limit
+const lengthGuidance = `Length: this student asked for a shorter explanation. Your entire answer must fit in about %d words. Be concise: answer the question directly first, mention only the prerequisites that matter most, and skip optional detail, while still ending with a complete final sentence.`
+
+// lowCoverageGuidance replaces grounding in course material when retrieval found
+// nothing relevant
+const lowCoverageGuidance = `Note: no sufficiently relevant course material was found for this question, so any "Relevant Course Material" below may be unrelated. Rely on well-established mathematical knowledge, do not cite or invent course material, and tell the student briefly at the start that this explanation is not based on their course material. Flag any step or claim you are not certain of.`
+
+// audienceGuidance adjusts tone and rigor of explanations for each audience level. The
+// base prompt is written for undergraduates, so that level needs no extra guidance.
+var audienceGuidance = map[string]string{
+	"middle_school": `Audience: a middle school student. Use everyday language and concrete numerical examples, avoid formal notation where possible, define every term you use, and favour intuition and pictures-in-words over proofs.`,
+	"high_school":   `Audience: a high school student. Use standard algebraic notation, explain each step explicitly, connect ideas to familiar pre-calculus topics, and keep formal proofs light.`,
+	"graduate":      `Audience: a graduate student. Be concise and rigorous, use precise notation and formal definitions (e.g. epsilon-delta where relevant), state assumptions and theorems explicitly, and skip elementary steps.`,
 }
 
 func NewClient(cfg config.LLMConfig) (*Client, error) {
 	logger := logger.MustGetLogger()
 	logger.Info("Initializing Gemini LLM Client",
 		zap.String("model", cfg.Model),
-		zap.Bool("api_key_provided", cfg.APIKey != ""))
+		zap.Bool("api_key_provided", cfg.APIKey != "" || len(cfg.APIKeys) > 0))
+
+	explanationPrompt, err := loadExplanationPrompt(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// Get API key with fallback priority
-	apiKey := cfg.APIKey
-	if apiKey == "" {
-		apiKey = os.Getenv("GEMINI_API_KEY")
-	}
-	if apiKey == "" {
-		apiKey = os.Getenv("GOOGLE_API_KEY")
-	}
-	if apiKey == "" {
-		apiKey = os.Getenv("MLF_LLM_API_KEY")
-	}
-	if apiKey == "" {
+	apiKeys := resolveAPIKeys(cfg)
+	if len(apiKeys) == 0 {
 		cancel()
 		return nil, fmt.Errorf("Gemini API key not found. Set GEMINI_API_KEY, GOOGLE_API_KEY, or MLF_LLM_API_KEY environment variable")
 	}
 
-	genaiClient, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey: apiKey,
-	})
-
+	keys, err := newKeyPool(ctx, apiKeys)
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to initialize Gemini client: %w", err)
 	}
 
 	client := &Client{
-		genaiClient: genaiClient,
-		config:      cfg,
-		ctx:         ctx,
-		cancel:      cancel,
-		logger:      logger,
+		keys:              keys,
+		config:            cfg,
+		ctx:               ctx,
+		cancel:            cancel,
+		logger:            logger,
+		explanationPrompt: explanationPrompt,
 	}
 
 	logger.Info("Gemini LLM client initialized successfully",
 		zap.String("model", cfg.Model),
-		zap.String("provider", "gemini"))
+		zap.String("provider", "gemini"),
+		zap.Int("api_keys", keys.size()))
 
 	return client, nil
 }
 
+// resolveAPIKeys returns the configured key pool, de-duplicated and in order. The single
+// APIKey is used when no pool is configured, falling back to the environment.
+func resolveAPIKeys(cfg config.LLMConfig) []string {
+	candidates := append([]string{}, cfg.APIKeys...)
+	if len(candidates) == 0 {
+		candidates = append(candidates, cfg.APIKey)
+	}
+	if strings.TrimSpace(cfg.APIKey) == "" && len(cfg.APIKeys) == 0 {
+		for _, env := range []string{"GEMINI_API_KEY", "GOOGLE_API_KEY", "MLF_LLM_API_KEY"} {
+			if value := os.Getenv(env); value != "" {
+				candidates = []string{value}
+				break
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var keys []string
+	for _, key := range candidates {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// SetConceptResolver makes IdentifyConceptsDetailed resolve concepts to graph IDs
+func (c *Client) SetConceptResolver(resolver ConceptResolver) {
+	c.resolver = resolver
+}
+
+// IdentifyConcepts returns the names of the concepts IdentifyConceptsDetailed extracts
 func (c *Client) IdentifyConcepts(ctx context.Context, query string) ([]string, error) {
+	concepts, err := c.IdentifyConceptsDetailed(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(concepts))
+	for i, concept := range concepts {
+		names[i] = concept.Name
+	}
+	return names, nil
+}
+
+// IdentifyConceptsDetailed extracts the concepts of a query, de-duplicated by normalized
+// name, and resolves them to graph IDs with a single batched lookup. A failed lookup
+// leaves the concepts unresolved rather than failing the extraction.
+func (c *Client) IdentifyConceptsDetailed(ctx context.Context, query string) ([]IdentifiedConcept, error) {
 	systemPromt := `You are an expert mathematics educator specializing in calculus and its foundational prerequisites. Your task is to analyze a student's query and identify the key mathematical concepts involved, focusing on concepts typically taught in undergraduate calculus courses and their essential prerequisite concepts.
 
 	Instructions:
@@ -113,24 +237,97 @@ func (c *Client) IdentifyConcepts(ctx context.Context, query string) ([]string,
 	`
 	userPrompt := fmt.Sprintf("Student query: '%s'\n\nIdentified concepts:", query)
 
-	response, err := c.callGemini(ctx, systemPromt, userPrompt, 0.1)
+	response, err := c.callGemini(ctx, OperationIdentify, systemPromt, userPrompt, 0.1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to identify concepts: %w", err)
 	}
 
-	concepts := strings.Split(strings.TrimSpace(response), ",")
-	var cleanedConcepts []string
-	for _, concept := range concepts {
-		cleaned := strings.TrimSpace(concept)
-		if cleaned != "" {
-			cleanedConcepts = append(cleanedConcepts, cleaned)
+	cleanedConcepts := parseConceptList(response)
+	c.logger.Info("Identified concepts", zap.Strings("concepts", cleanedConcepts))
+
+	concepts := make([]IdentifiedConcept, 0, len(cleanedConcepts))
+	seen := make(map[string]bool, len(cleanedConcepts))
+	for _, name := range cleanedConcepts {
+		normalized := NormalizeConceptName(name)
+		if normalized == "" || seen[normalized] {
+			continue
 		}
+		seen[normalized] = true
+		concepts = append(concepts, IdentifiedConcept{Name: name, Normalized: normalized})
 	}
-	c.logger.Info("Identified concepts", zap.Strings("concepts", cleanedConcepts))
-	return cleanedConcepts, nil
+
+	c.resolveConcepts(ctx, concepts)
+	return concepts, nil
+}
+
+// resolveConcepts fills in the graph IDs of concepts when a resolver is set
+func (c *Client) resolveConcepts(ctx context.Context, concepts []IdentifiedConcept) {
+	if c.resolver == nil || len(concepts) == 0 {
+		return
+	}
+
+	names := make([]string, len(concepts))
+	for i, concept := range concepts {
+		names[i] = concept.Name
+	}
+
+	ids, err := c.resolver.FindConceptIDs(ctx, names)
+	if err != nil {
+		c.logger.Warn("Failed to resolve identified concepts to graph IDs", zap.Error(err))
+		return
+	}
+
+	for i := range concepts {
+		concepts[i].ID = ids[concepts[i].Name]
+		concepts[i].Resolved = true
+	}
+}
+
+// NormalizeConceptName lowercases a concept name, treats underscores and hyphens as
+// spaces and collapses whitespace
+func NormalizeConceptName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer("_", " ", "-", " ").Replace(name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// IdentifyConceptsExplicit is a second-chance concept extraction for queries where
+// IdentifyConcepts returned too few concepts. The prompt spells out that the query is
+// non-trivial and asks for at least minConcepts concepts including prerequisites.
+func (c *Client) IdentifyConceptsExplicit(ctx context.Context, query string, minConcepts int) ([]string, error) {
+	systemPrompt := fmt.Sprintf(`You are an expert mathematics educator. A previous attempt to extract concepts from the student query below returned too few results.
+
+	Instructions:
+	1. Read the whole query carefully; it involves several mathematical ideas.
+	2. List every mathematical concept the student must understand to answer it, including foundational prerequisites (e.g. algebra, functions, limits).
+	3. Return at least %d concepts, ordered from most foundational to most advanced.
+	4. Use standard mathematical terminology.
+	5. Format your output as a lowercase, comma-separated list with no extra text.`, minConcepts)
+
+	userPrompt := fmt.Sprintf("Student query: '%s'\n\nConcepts:", query)
+
+	response, err := c.callGemini(ctx, OperationIdentify, systemPrompt, userPrompt, 0.2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to identify concepts explicitly: %w", err)
+	}
+
+	concepts := parseConceptList(response)
+	c.logger.Info("Identified concepts with explicit prompt", zap.Strings("concepts", concepts))
+	return concepts, nil
+}
+
+// parseConceptList splits a comma-separated LLM response into trimmed, non-empty concepts
+func parseConceptList(response string) []string {
+	var concepts []string
+	for _, concept := range strings.Split(strings.TrimSpace(response), ",") {
+		if cleaned := strings.TrimSpace(concept); cleaned != "" {
+			concepts = append(concepts, cleaned)
+		}
+	}
+	return concepts
 }
 
-func (c *Client) GenerateExplanation(ctx context.Context, req ExplanationRequest) (string, error) {
+func (c *Client) GenerateExplanation(ctx context.Context, req ExplanationRequest) (*ExplanationResult, error) {
 	pathText := ""
 	if len(req.PrerequisitePath) > 0 {
 		pathConcepts := make([]string, len(req.PrerequisitePath))
@@ -139,31 +336,54 @@ func (c *Client) GenerateExplanation(ctx context.Context, req ExplanationRequest
 		}
 		pathText = fmt.Sprintf("Learning path: %s\n\n", strings.Join(pathConcepts, " -> "))
 	}
+	if len(req.SuggestedPrerequisites) > 0 {
+		concepts := make([]string, 0, len(req.SuggestedPrerequisites))
+		for concept := range req.SuggestedPrerequisites {
+			concepts = append(concepts, concept)
+		}
+		sort.Strings(concepts)
 
-	contextText := ""
-	if len(req.ContextChunks) > 0 {
-		contextParts := make([]string, len(req.ContextChunks))
-		for i, chunk := range req.ContextChunks {
-			contextParts[i] = fmt.Sprintf("Context %d: %s", i+1, chunk)
+		lines := make([]string, len(concepts))
+		for i, concept := range concepts {
+			lines[i] = fmt.Sprintf("- %s: %s", concept, strings.Join(req.SuggestedPrerequisites[concept], ", "))
 		}
-		contextText = strings.Join(contextParts, "\n\n")
+		pathText += fmt.Sprintf("Suggested prerequisites (model-suggested, NOT verified against the course knowledge graph; present them as likely background, not as the official learning path):\n%s\n\n", strings.Join(lines, "\n"))
+	}
+
+	systemPrompt, err := c.renderExplanationPrompt(req)
+	if err != nil {
+		return nil, err
 	}
 
-	systemPrompt := `You are an expert mathematics tutor specializing in calculus. Your goal is to provide clear, complete, educational explanations that help students understand mathematical concepts and their prerequisites.
+	if guidance, ok := audienceGuidance[req.AudienceLevel]; ok {
+		systemPrompt += "\n\n" + guidance
+	}
+	if req.LowCoverage {
+		systemPrompt += "\n\n" + lowCoverageGuidance
+	}
 
-		Guidelines:
-		1. Start with the fundamental concepts and build up logically
-		2. Explain WHY prerequisites are needed, not just WHAT they are
-		3. Use clear, accessible language but maintain mathematical accuracy
-		4. Include specific step-by-step solutions with calculations
-		5. Address the student's specific question directly
-		6. Always provide a COMPLETE explanation - do not truncate your response
-		7. Use the provided context and learning path to ground your explanation
-		8. End with a clear conclusion or final answer
+	maxTokens := c.maxOutputTokens()
+	if req.MaxTokens > maxTokens {
+		return nil, fmt.Errorf("%w: requested %d, limit %d", ErrMaxTokensExceeded, req.MaxTokens, maxTokens)
+	}
+	if req.MaxTokens > 0 && req.MaxTokens < maxTokens {
+		maxTokens = req.MaxTokens
+		systemPrompt += "\n\n" + fmt.Sprintf(lengthGuidance, maxTokens*3/4)
+	}
 
-		IMPORTANT: Provide a complete, thorough explanation. Do not stop mid-sentence or leave the explanation incomplete.`
+	buildUserPrompt := func(chunks []string) string {
+		contextText := ""
+		citationText := ""
+		if len(chunks) > 0 {
+			contextParts := make([]string, len(chunks))
+			for i, chunk := range chunks {
+				contextParts[i] = fmt.Sprintf("Context %d: %s", i+1, chunk)
+			}
+			contextText = strings.Join(contextParts, "\n\n")
+			citationText = "\n\n" + citationInstruction
+		}
 
-	userPrompt := fmt.Sprintf(`Student Question: %s
+		return fmt.Sprintf(`Student Question: %s
 
 		%sRelevant Course Material:
 		%s
@@ -176,20 +396,132 @@ func (c *Client) GenerateExplanation(ctx context.Context, req ExplanationRequest
 		5. Provides the final numerical answer if applicable
 		6. Includes practical guidance for learning
 
-		Make sure to provide a COMPLETE response that fully answers the question.
+		Make sure to provide a COMPLETE response that fully answers the question.%s
 
-		Explanation:`, req.Query, pathText, contextText)
+		Explanation:`, req.Query, pathText, contextText, citationText)
+	}
+
+	// Drop the lowest-ranked context chunks until the prompt fits the input budget
+	budget := c.promptTokenBudget()
+	chunks := req.ContextChunks
+	userPrompt := buildUserPrompt(chunks)
+	promptTokens := c.EstimateTokens(systemPrompt + "\n\n" + userPrompt)
+	for promptTokens > budget && len(chunks) > 0 {
+		chunks = chunks[:len(chunks)-1]
+		userPrompt = buildUserPrompt(chunks)
+		promptTokens = c.EstimateTokens(systemPrompt + "\n\n" + userPrompt)
+	}
 
-	response, err := c.callGemini(ctx, systemPrompt, userPrompt, 0.3)
+	metrics.LLMPromptTokens.WithLabelValues(OperationExplain).Observe(float64(promptTokens))
+
+	if dropped := len(req.ContextChunks) - len(chunks); dropped > 0 {
+		metrics.LLMPromptBudgetActions.WithLabelValues(metrics.PromptTrimmed).Inc()
+		c.logger.Warn("Trimmed context chunks to fit context window",
+			zap.Int("dropped_chunks", dropped),
+			zap.Int("estimated_prompt_tokens", promptTokens),
+			zap.Int("token_budget", budget))
+	}
+	if promptTokens > budget {
+		metrics.LLMPromptBudgetActions.WithLabelValues(metrics.PromptRejected).Inc()
+		return nil, fmt.Errorf("%w: estimated %d tokens, budget %d", ErrPromptTooLarge, promptTokens, budget)
+	}
+
+	c.logger.Info("Generating explanation",
+		zap.String("audience_level", req.AudienceLevel),
+		zap.Bool("low_coverage", req.LowCoverage),
+		zap.Int("max_output_tokens", maxTokens),
+		zap.Int("estimated_prompt_tokens", promptTokens),
+		zap.Int("context_chunks", len(chunks)))
+
+	response, err := c.callGeminiWithLimit(ctx, OperationExplain, systemPrompt, userPrompt, 0.3, maxTokens)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate explanation: %w", err)
+		return nil, fmt.Errorf("failed to generate explanation: %w", err)
+	}
+
+	// Citations are only requested when context was sent
+	explanation, cited := response, []int{}
+	if len(chunks) > 0 {
+		explanation, cited = parseCitations(response, len(chunks))
 	}
 
 	c.logger.Info("Generated explanation successfully",
-		zap.Int("explanation_length", len(response)),
-		zap.Bool("appears_complete", !c.isResponseTruncated(response)))
+		zap.Int("explanation_length", len(explanation)),
+		zap.Ints("cited_context", cited),
+		zap.Bool("appears_complete", !c.isResponseTruncated(explanation)))
+
+	result := &ExplanationResult{
+		Explanation:         explanation,
+		CitedContextIndices: cited,
+		MaxTokens:           maxTokens,
+		Model:               c.ModelFor(OperationExplain),
+	}
+	if trimmed, truncated := trimExplanation(explanation, c.config.MaxExplanationChars); truncated {
+		c.logger.Warn("Trimmed overlong explanation",
+			zap.Int("explanation_length", len(explanation)),
+			zap.Int("trimmed_length", len(trimmed)),
+			zap.Int("max_explanation_chars", c.config.MaxExplanationChars))
+		result.Explanation = trimmed
+		result.Truncated = true
+		if c.config.KeepFullExplanation {
+			result.FullExplanation = explanation
+		}
+	}
 
-	return response, nil
+	return result, nil
+}
+
+// SuggestPrerequisites asks the model for the likely direct prerequisites of a concept
+// the knowledge graph has none for. The suggestions are unverified.
+func (c *Client) SuggestPrerequisites(ctx context.Context, conceptName string) ([]string, error) {
+	systemPrompt := fmt.Sprintf(`You are an expert mathematics educator helping to complete a calculus prerequisite graph.
+
+	Instructions:
+	1. List the concepts a student must understand before learning the given concept.
+	2. Only include direct prerequisites, at most %d, most important first.
+	3. Use standard mathematical terminology and never repeat the concept itself.
+	4. Format your output as a lowercase, comma-separated list with no extra text.`, maxSuggestedPrerequisites)
+
+	userPrompt := fmt.Sprintf("Concept: '%s'\n\nPrerequisites:", conceptName)
+
+	response, err := c.callGemini(ctx, OperationSuggestPrerequisites, systemPrompt, userPrompt, 0.1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest prerequisites: %w", err)
+	}
+
+	var prerequisites []string
+	for _, prerequisite := range parseConceptList(response) {
+		if strings.EqualFold(prerequisite, conceptName) {
+			continue
+		}
+		prerequisites = append(prerequisites, prerequisite)
+		if len(prerequisites) == maxSuggestedPrerequisites {
+			break
+		}
+	}
+
+	c.logger.Info("Suggested prerequisites",
+		zap.String("concept", conceptName),
+		zap.Strings("prerequisites", prerequisites))
+	return prerequisites, nil
+}
+
+// GenerateConceptDescription writes a concise 1-2 sentence description for a graph concept
+func (c *Client) GenerateConceptDescription(ctx context.Context, conceptName string) (string, error) {
+	systemPrompt := `You are an expert mathematics educator writing short glossary entries for a calculus knowledge graph.
+
+	Instructions:
+	1. Describe the concept in 1-2 plain sentences suitable for an undergraduate student.
+	2. State what the concept is, not how to teach it.
+	3. Do not use markdown, lists, LaTeX, or introductory phrases such as "Sure" or "Here is".`
+
+	userPrompt := fmt.Sprintf("Concept: '%s'\n\nDescription:", conceptName)
+
+	response, err := c.callGemini(ctx, OperationDescribeConcept, systemPrompt, userPrompt, 0.2)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate concept description: %w", err)
+	}
+
+	return strings.TrimSpace(strings.Trim(response, "\"")), nil
 }
 
 func (c *Client) Provider() string {
@@ -204,12 +536,24 @@ func (c *Client) Model() string {
 	return model
 }
 
-func (c *Client) IsHealthy(ctx context.Context) bool {
-	healthCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
+// ModelFor returns the model an operation runs on: IdentifyModel for concept
+// identification, ExplainModel for explanations, and Model otherwise or when unset
+func (c *Client) ModelFor(operation string) string {
+	var model string
+	switch operation {
+	case OperationIdentify:
+		model = c.config.IdentifyModel
+	case OperationExplain:
+		model = c.config.ExplainModel
+	}
+	if model == "" {
+		return c.Model()
+	}
+	return model
+}
 
-	_, err := c.callGemini(healthCtx, "You are a health check assistant.", HealthCheckPrompt, 0.1)
-	if err != nil {
+func (c *Client) IsHealthy(ctx context.Context) bool {
+	if err := c.Ping(ctx); err != nil {
 		c.logger.Warn("Gemini health check failed", zap.Error(err))
 		return false
 	}
@@ -217,28 +561,70 @@ func (c *Client) IsHealthy(ctx context.Context) bool {
 	return true
 }
 
-func (c *Client) callGemini(ctx context.Context, systemPrompt, userPrompt string, temperature float32) (string, error) {
-	model := c.config.Model
-	if model == "" {
-		model = DefaultModel
+// Ping sends a minimal prompt to verify the model is reachable with the configured keys
+func (c *Client) Ping(ctx context.Context) error {
+	healthCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := c.callGemini(healthCtx, OperationHealthCheck, "You are a health check assistant.", HealthCheckPrompt, 0.1)
+	return err
+}
+
+// EstimateTokens approximates the number of tokens text will consume. It uses a
+// characters-per-token heuristic rather than a tokenizer round trip, so it is cheap
+// enough to run before every call.
+func (c *Client) EstimateTokens(text string) int {
+	runes := utf8.RuneCountInString(text)
+	return (runes + charsPerToken - 1) / charsPerToken
+}
+
+// promptTokenBudget is the context window minus the tokens reserved for the response
+func (c *Client) promptTokenBudget() int {
+	window := c.config.ContextWindow
+	if window <= 0 {
+		window = DefaultContextWindow
 	}
+	return window - c.maxOutputTokens()
+}
 
-	fullPrompt := systemPrompt + "\n\n" + userPrompt
+// MaxOutputTokens is the configured ceiling on tokens generated per call
+func (c *Client) MaxOutputTokens() int {
+	return c.maxOutputTokens()
+}
 
-	maxTokens := c.config.MaxTokens
-	if maxTokens <= 0 {
-		maxTokens = DefaultMaxTokens
+func (c *Client) maxOutputTokens() int {
+	if c.config.MaxTokens <= 0 {
+		return DefaultMaxTokens
 	}
+	return c.config.MaxTokens
+}
+
+func (c *Client) callGemini(ctx context.Context, operation, systemPrompt, userPrompt string, temperature float32) (string, error) {
+	return c.callGeminiWithLimit(ctx, operation, systemPrompt, userPrompt, temperature, c.maxOutputTokens())
+}
+
+// callGeminiWithLimit calls Gemini on the operation's model with an explicit output
+// token limit, recording the model used on the context's usage tracker
+func (c *Client) callGeminiWithLimit(ctx context.Context, operation, systemPrompt, userPrompt string, temperature float32, maxTokens int) (string, error) {
+	model := c.ModelFor(operation)
+	recordModel(ctx, operation, model)
+
+	fullPrompt := systemPrompt + "\n\n" + userPrompt
 
 	config := &genai.GenerateContentConfig{
 		Temperature:     &temperature,
 		MaxOutputTokens: int32(maxTokens),
 	}
 
-	timeoutCtx, cancel := context.WithTimeout(ctx, DefaultTimeout)
-	defer cancel()
+	if c.config.LogPrompts {
+		c.logger.Debug("Gemini prompt",
+			zap.String("model", model),
+			zap.Float32("temperature", temperature),
+			zap.Int("prompt_length", len(fullPrompt)),
+			zap.String("prompt", c.truncateForLog(fullPrompt)))
+	}
 
-	resp, err := c.genaiClient.Models.GenerateContent(timeoutCtx, model, genai.Text(fullPrompt), config)
+	resp, err := c.generateWithRetry(ctx, model, fullPrompt, config)
 	if err != nil {
 		return "", fmt.Errorf("Gemini API call failed: %w", err)
 	}
@@ -247,6 +633,10 @@ func (c *Client) callGemini(ctx context.Context, systemPrompt, userPrompt string
 		return "", fmt.Errorf("received nil response from Gemini")
 	}
 
+	if resp.UsageMetadata != nil {
+		recordUsage(ctx, resp.UsageMetadata.TotalTokenCount)
+	}
+
 	if len(resp.Candidates) == 0 {
 		return "", fmt.Errorf("no candidates returned from Gemini")
 	}
@@ -263,6 +653,13 @@ func (c *Client) callGemini(ctx context.Context, systemPrompt, userPrompt string
 		}
 	}
 
+	if c.config.LogPrompts {
+		c.logger.Debug("Gemini raw response",
+			zap.String("model", model),
+			zap.Int("response_length", content.Len()),
+			zap.String("response", c.truncateForLog(content.String())))
+	}
+
 	result := strings.TrimSpace(content.String())
 	if result == "" {
 		return "", fmt.Errorf("no text content in Gemini response")
@@ -271,6 +668,129 @@ func (c *Client) callGemini(ctx context.Context, systemPrompt, userPrompt string
 	return result, nil
 }
 
+// generateWithRetry retries rate-limited and unavailable responses. A key that hits its
+// quota is cooled down and the call fails over to the next available key immediately;
+// failovers do not count as retries. Otherwise the server's retry hint schedules the
+// next attempt when present (capped at MaxRetryDelay), or the delay grows exponentially
+// from RetryBaseDelay.
+func (c *Client) generateWithRetry(ctx context.Context, model, prompt string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	baseDelay := c.config.RetryBaseDelay
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+	maxDelay := c.config.MaxRetryDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxRetryDelay
+	}
+	keyCooldown := c.config.KeyCooldown
+	if keyCooldown <= 0 {
+		keyCooldown = DefaultKeyCooldown
+	}
+
+	failovers := 0
+	for attempt := 0; ; attempt++ {
+		key := c.keys.acquire()
+		timeoutCtx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+		resp, err := key.client.Models.GenerateContent(timeoutCtx, model, genai.Text(prompt), config)
+		cancel()
+		if err == nil {
+			c.keys.markHealthy(key)
+			return resp, nil
+		}
+
+		delay, hinted := retryDelayFromError(err)
+
+		if isQuotaError(err) && c.keys.size() > 1 {
+			cooldown := keyCooldown
+			if hinted && delay > cooldown {
+				cooldown = delay
+			}
+			c.keys.markExhausted(key, cooldown)
+
+			if failovers < c.keys.size()-1 && c.keys.available() {
+				failovers++
+				attempt--
+				c.logger.Warn("Gemini key quota exhausted, failing over to next key",
+					zap.Int("key_index", key.index),
+					zap.Duration("cooldown", cooldown),
+					zap.Error(err))
+				continue
+			}
+		}
+
+		if attempt >= c.config.MaxRetries || !isRetryableError(err) {
+			return nil, err
+		}
+
+		if !hinted {
+			delay = baseDelay << attempt
+		}
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+
+		c.logger.Warn("Gemini call failed, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Duration("delay", delay),
+			zap.Bool("server_hint", hinted),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isRetryableError reports whether a Gemini error is worth retrying: rate limiting or
+// a transient server-side failure
+func isRetryableError(err error) bool {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == 429 || apiErr.Code >= 500
+}
+
+// retryDelayFromError extracts the server's retry hint, preferring the structured
+// google.rpc.RetryInfo detail and falling back to the "retry in" message text
+func retryDelayFromError(err error) (time.Duration, bool) {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return 0, false
+	}
+
+	for _, detail := range apiErr.Details {
+		if kind, _ := detail["@type"].(string); !strings.HasSuffix(kind, "google.rpc.RetryInfo") {
+			continue
+		}
+		if raw, ok := detail["retryDelay"].(string); ok {
+			if delay, err := time.ParseDuration(raw); err == nil && delay > 0 {
+				return delay, true
+			}
+		}
+	}
+
+	if match := retryInMessagePattern.FindStringSubmatch(apiErr.Message); match != nil {
+		if delay, err := time.ParseDuration(match[1]); err == nil && delay > 0 {
+			return delay, true
+		}
+	}
+
+	return 0, false
+}
+
+// truncateForLog caps logged prompt text at LogPromptMaxChars runes
+func (c *Client) truncateForLog(text string) string {
+	limit := c.config.LogPromptMaxChars
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit]) + fmt.Sprintf("... [truncated %d chars]", len(runes)-limit)
+}
+
 func (c *Client) isResponseTruncated(response string) bool {
 	if len(response) == 0 {
 		return true
@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// DefaultKeyCooldown is how long a key that hit its quota is skipped when Gemini
+// gives no retry hint
+const DefaultKeyCooldown = time.Minute
+
+// apiKey is one Gemini API key with its own client and quota state. Keys are only
+// ever identified by index in logs.
+type apiKey struct {
+	index         int
+	client        *genai.Client
+	cooldownUntil time.Time
+	failures      int
+}
+
+// keyPool rotates requests round-robin across a set of API keys, skipping keys that
+// are cooling down after a quota error. It is safe for concurrent use.
+type keyPool struct {
+	mu   sync.Mutex
+	keys []*apiKey
+	next int
+}
+
+func newKeyPool(ctx context.Context, apiKeys []string) (*keyPool, error) {
+	pool := &keyPool{}
+	for i, key := range apiKeys {
+		client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: key})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Gemini client for key %d: %w", i, err)
+		}
+		pool.keys = append(pool.keys, &apiKey{index: i, client: client})
+	}
+	if len(pool.keys) == 0 {
+		return nil, errors.New("no Gemini API keys configured")
+	}
+	return pool, nil
+}
+
+func (p *keyPool) size() int {
+	return len(p.keys)
+}
+
+// acquire returns the next key in rotation that is not cooling down. When every key
+// is exhausted it returns the one that recovers first rather than failing outright.
+func (p *keyPool) acquire() *apiKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var earliest *apiKey
+	for i := 0; i < len(p.keys); i++ {
+		key := p.keys[(p.next+i)%len(p.keys)]
+		if !now.Before(key.cooldownUntil) {
+			p.next = (key.index + 1) % len(p.keys)
+			return key
+		}
+		if earliest == nil || key.cooldownUntil.Before(earliest.cooldownUntil) {
+			earliest = key
+		}
+	}
+
+	p.next = (earliest.index + 1) % len(p.keys)
+	return earliest
+}
+
+// available reports whether any key is currently outside its cooldown
+func (p *keyPool) available() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range p.keys {
+		if !now.Before(key.cooldownUntil) {
+			return true
+		}
+	}
+	return false
+}
+
+// markExhausted skips a key until its cooldown passes
+func (p *keyPool) markExhausted(key *apiKey, cooldown time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key.failures++
+	if until := time.Now().Add(cooldown); until.After(key.cooldownUntil) {
+		key.cooldownUntil = until
+	}
+}
+
+// markHealthy clears a key's error state after a successful call
+func (p *keyPool) markHealthy(key *apiKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key.failures = 0
+	key.cooldownUntil = time.Time{}
+}
+
+// isQuotaError reports whether Gemini rejected a call because the key is rate limited
+// or out of quota
+func isQuotaError(err error) bool {
+	var apiErr genai.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == 429
+}
@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"fmt"
+	"mathprereq/internel/core/config"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// DefaultExplanationPrompt is the built-in system prompt of explanations, used when no
+// template is configured
+const DefaultExplanationPrompt = `You are an expert mathematics tutor specializing in calculus. Your goal is to provide clear, complete, educational explanations that help students understand mathematical concepts and their prerequisites.
+
+		Guidelines:
+		1. Start with the fundamental concepts and build up logically
+		2. Explain WHY prerequisites are needed, not just WHAT they are
+		3. Use clear, accessible language but maintain mathematical accuracy
+		4. Include specific step-by-step solutions with calculations
+		5. Address the student's specific question directly
+		6. Always provide a COMPLETE explanation - do not truncate your response
+		7. Use the provided context and learning path to ground your explanation
+		8. End with a clear conclusion or final answer
+
+		IMPORTANT: Provide a complete, thorough explanation. Do not stop mid-sentence or leave the explanation incomplete.`
+
+// ExplanationPromptData is what an explanation system prompt template can refer to, e.g.
+// {{.AudienceLevel}}. Audience, low-coverage and length guidance are still appended to
+// the rendered prompt.
+type ExplanationPromptData struct {
+	AudienceLevel string
+	OutputFormat  string
+	LowCoverage   bool
+}
+
+// loadExplanationPrompt parses the configured explanation prompt template: the file at
+// ExplanationPromptFile if set, else ExplanationPrompt, else DefaultExplanationPrompt.
+// The template is also rendered once with sample data so unknown fields fail at startup.
+func loadExplanationPrompt(cfg config.LLMConfig) (*template.Template, error) {
+	source := cfg.ExplanationPrompt
+	if cfg.ExplanationPromptFile != "" {
+		content, err := os.ReadFile(cfg.ExplanationPromptFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read explanation prompt file: %w", err)
+		}
+		source = string(content)
+	}
+	if strings.TrimSpace(source) == "" {
+		source = DefaultExplanationPrompt
+	}
+
+	tmpl, err := template.New("explanation_prompt").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid explanation prompt template: %w", err)
+	}
+
+	sample := ExplanationPromptData{AudienceLevel: "undergraduate", OutputFormat: "markdown"}
+	if err := tmpl.Execute(&strings.Builder{}, sample); err != nil {
+		return nil, fmt.Errorf("invalid explanation prompt template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderExplanationPrompt renders the explanation system prompt for a request
+func (c *Client) renderExplanationPrompt(req ExplanationRequest) (string, error) {
+	var prompt strings.Builder
+	data := ExplanationPromptData{
+		AudienceLevel: req.AudienceLevel,
+		OutputFormat:  req.OutputFormat,
+		LowCoverage:   req.LowCoverage,
+	}
+	if err := c.explanationPrompt.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("failed to render explanation prompt: %w", err)
+	}
+	return prompt.String(), nil
+}
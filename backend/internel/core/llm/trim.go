@@ -0,0 +1,122 @@
+package llm
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// truncationMarker is appended to an explanation cut to MaxExplanationChars
+const truncationMarker = "\n\n(truncated)"
+
+// trimExplanation cuts text to at most maxChars bytes, marker included, reporting
+// whether it cut anything. It prefers the last sentence end, then the last word
+// boundary, in the second half of the allowance; a cut never lands inside LaTeX math
+// ($...$, $$...$$, \(...\), \[...\]) or a fenced code block.
+func trimExplanation(text string, maxChars int) (string, bool) {
+	if maxChars <= 0 || len(text) <= maxChars {
+		return text, false
+	}
+
+	limit := maxChars - len(truncationMarker)
+	if limit <= 0 {
+		limit = maxChars
+	}
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+
+	safe := outsideMath(text[:limit])
+	cut := -1
+	for i := limit; i > limit/2 && cut < 0; i-- {
+		if safe[i] && isSentenceEnd(text, i) {
+			cut = i
+		}
+	}
+	for i := limit; i > limit/2 && cut < 0; i-- {
+		if safe[i] && (text[i] == ' ' || text[i] == '\n') {
+			cut = i
+		}
+	}
+	for i := limit; i > 0 && cut < 0; i-- {
+		if safe[i] && utf8.RuneStart(text[i]) {
+			cut = i
+		}
+	}
+	if cut < 0 {
+		cut = limit
+	}
+
+	return strings.TrimRight(text[:cut], " \t\n") + truncationMarker, true
+}
+
+// isSentenceEnd reports whether position i directly follows sentence-ending
+// punctuation that is followed by whitespace, or starts a blank line
+func isSentenceEnd(text string, i int) bool {
+	if i == 0 || i >= len(text) {
+		return false
+	}
+	if strings.HasPrefix(text[i:], "\n\n") {
+		return true
+	}
+	switch text[i-1] {
+	case '.', '!', '?':
+		return text[i] == ' ' || text[i] == '\n'
+	}
+	return false
+}
+
+// outsideMath reports, for each byte offset 0..len(text), whether a cut there falls
+// outside math and code blocks. Escaped dollars (\$) are literal.
+func outsideMath(text string) []bool {
+	safe := make([]bool, len(text)+1)
+	closer := ""
+	for i := 0; i < len(text); {
+		safe[i] = closer == ""
+		rest := text[i:]
+
+		if closer != "" {
+			if strings.HasPrefix(rest, closer) && !(closer[0] == '$' && i > 0 && text[i-1] == '\\') {
+				for j := 1; j < len(closer); j++ {
+					safe[i+j] = false
+				}
+				i += len(closer)
+				closer = ""
+				continue
+			}
+			i++
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(rest, "```"):
+			closer = "```"
+		case strings.HasPrefix(rest, "$$"):
+			closer = "$$"
+		case strings.HasPrefix(rest, `\[`):
+			closer = `\]`
+		case strings.HasPrefix(rest, `\(`):
+			closer = `\)`
+		case rest[0] == '\\' && len(rest) > 1 && rest[1] == '$':
+			safe[i+1] = true
+			i += 2
+			continue
+		case rest[0] == '$':
+			closer = "$"
+		}
+		if closer != "" {
+			// Cutting just before an opening delimiter is fine; inside it is not
+			opener := len(closer)
+			if closer == `\]` || closer == `\)` {
+				opener = 2
+			}
+			for j := 1; j < opener; j++ {
+				safe[i+j] = false
+			}
+			i += opener
+			continue
+		}
+		i++
+	}
+	safe[len(text)] = closer == ""
+	return safe
+}
@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+type usageTrackerKey struct{}
+
+// UsageTracker accumulates the tokens reported by every LLM call made with a context
+// returned by WithUsageTracker
+type UsageTracker struct {
+	tokens atomic.Int64
+
+	mu     sync.Mutex
+	models map[string]string
+}
+
+// WithUsageTracker returns a context that records LLM token usage into the returned tracker
+func WithUsageTracker(ctx context.Context) (context.Context, *UsageTracker) {
+	tracker := &UsageTracker{}
+	return context.WithValue(ctx, usageTrackerKey{}, tracker), tracker
+}
+
+// Tokens returns the total tokens recorded so far
+func (t *UsageTracker) Tokens() int {
+	return int(t.tokens.Load())
+}
+
+// recordUsage adds tokens to the tracker carried by ctx, if any
+func recordUsage(ctx context.Context, tokens int32) {
+	if tracker, ok := ctx.Value(usageTrackerKey{}).(*UsageTracker); ok && tokens > 0 {
+		tracker.tokens.Add(int64(tokens))
+	}
+}
+
+// Models returns the model each operation last ran on, keyed by operation
+func (t *UsageTracker) Models() map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	models := make(map[string]string, len(t.models))
+	for operation, model := range t.models {
+		models[operation] = model
+	}
+	return models
+}
+
+// recordModel notes on the tracker carried by ctx, if any, the model an operation ran on
+func recordModel(ctx context.Context, operation, model string) {
+	tracker, ok := ctx.Value(usageTrackerKey{}).(*UsageTracker)
+	if !ok {
+		return
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if tracker.models == nil {
+		tracker.models = make(map[string]string)
+	}
+	tracker.models[operation] = model
+}
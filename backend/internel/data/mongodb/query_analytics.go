@@ -2,8 +2,10 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"mathprereq/internel/data/neo4j"
+	"mathprereq/internel/domain/repositories"
 	"mathprereq/pkg/logger"
 	"strings"
 	"time"
@@ -23,7 +25,7 @@ type QueryResponseRecord struct {
 	PrerequisitePath   []neo4j.Concept    `bson:"prerequisite_path" json:"prerequisite_path"`
 	RetrievedContext   []string           `bson:"retrieved_context" json:"retrieved_context"`
 	Explanation        string             `bson:"explanation" json:"explanation"`
-	ResponseTime       time.Duration      `bson:"response_time" json:"response_time"`
+	ResponseTimeMs     int64              `bson:"response_time_ms" json:"response_time_ms"`
 	ProcessingSuccess  bool               `bson:"processing_success" json:"processing_success"`
 	ErrorMessage       string             `bson:"error_message,omitempty" json:"error_message,omitempty"`
 	Timestamp          time.Time          `bson:"timestamp" json:"timestamp"`
@@ -36,10 +38,25 @@ type QueryResponseRecord struct {
 	VectorStoreHits    int                `bson:"vector_store_hits" json:"vector_store_hits"`
 }
 
+// avgResponseTimeMs averages response times in milliseconds. Records written before the
+// millisecond field existed hold a nanosecond response_time and are converted in place.
+var avgResponseTimeMs = bson.D{{"$avg", bson.D{{"$ifNull", bson.A{
+	"$response_time_ms",
+	bson.D{{"$divide", bson.A{"$response_time", int64(time.Millisecond)}}},
+}}}}}
+
+// Timeouts bounding each call; a shorter caller deadline still wins
+const (
+	defaultAnalyticsReadTimeout  = 30 * time.Second
+	defaultAnalyticsWriteTimeout = 10 * time.Second
+)
+
 // QueryAnalytics provides methods for storing and retrieving query data
 type QueryAnalytics struct {
-	collection *mongo.Collection
-	logger     *zap.Logger
+	collection   *mongo.Collection
+	logger       *zap.Logger
+	readTimeout  time.Duration
+	writeTimeout time.Duration
 }
 
 // NewQueryAnalytics creates a new query analytics instance using shared MongoDB client
@@ -58,14 +75,53 @@ func NewQueryAnalytics(mongoClient *mongo.Client, databaseName string) *QueryAna
 		}
 	}
 
+	// Reports convert unmigrated records on the fly, so startup need not wait for this
+	go func() {
+		if migrated, err := migrateResponseTimes(context.Background(), collection); err != nil {
+			logger.Warn("Failed to migrate response times to milliseconds", zap.Error(err))
+		} else if migrated > 0 {
+			logger.Info("Migrated response times to milliseconds", zap.Int64("records", migrated))
+		}
+	}()
+
 	logger.Info("Query analytics initialized successfully",
 		zap.String("database", databaseName),
 		zap.String("collection", "query_responses"))
 
 	return &QueryAnalytics{
-		collection: collection,
-		logger:     logger,
+		collection:   collection,
+		logger:       logger,
+		readTimeout:  defaultAnalyticsReadTimeout,
+		writeTimeout: defaultAnalyticsWriteTimeout,
+	}
+}
+
+// migrateResponseTimes rewrites nanosecond response_time values as response_time_ms.
+// It only touches unmigrated records, so it is safe to run on every startup.
+func migrateResponseTimes(ctx context.Context, collection *mongo.Collection) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	filter := bson.D{
+		{"response_time", bson.D{{"$exists", true}}},
+		{"response_time_ms", bson.D{{"$exists", false}}},
+	}
+	update := mongo.Pipeline{
+		{{"$set", bson.D{{"response_time_ms", bson.D{{"$toLong", bson.D{{"$divide", bson.A{"$response_time", int64(time.Millisecond)}}}}}}}}},
+		{{"$unset", "response_time"}},
+	}
+
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to migrate response times: %w", err)
 	}
+	return result.ModifiedCount, nil
+}
+
+// isIndexNotFound reports whether err is MongoDB's IndexNotFound (code 27)
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.Code == 27
 }
 
 // createQueryAnalyticsIndexes creates MongoDB indexes for efficient queries
@@ -90,7 +146,7 @@ func createQueryAnalyticsIndexes(ctx context.Context, collection *mongo.Collecti
 			Keys: bson.D{{"llm_provider", 1}},
 		},
 		{
-			Keys: bson.D{{"response_time", 1}},
+			Keys: bson.D{{"response_time_ms", 1}},
 		},
 	}
 
@@ -105,13 +161,18 @@ func createQueryAnalyticsIndexes(ctx context.Context, collection *mongo.Collecti
 		}
 	}
 
+	// The nanosecond response_time field is migrated away, so its index is dead weight
+	if _, err := collection.Indexes().DropOne(ctx, "response_time_1"); err != nil && !isIndexNotFound(err) {
+		logger.Warn("Failed to drop the response_time index", zap.Error(err))
+	}
+
 	logger.Info("Query analytics indexes created successfully")
 	return nil
 }
 
 // SaveQueryResponse saves a query response record to MongoDB
 func (qa *QueryAnalytics) SaveQueryResponse(ctx context.Context, record *QueryResponseRecord) error {
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, qa.writeTimeout)
 	defer cancel()
 
 	_, err := qa.collection.InsertOne(ctx, record)
@@ -123,14 +184,14 @@ func (qa *QueryAnalytics) SaveQueryResponse(ctx context.Context, record *QueryRe
 	qa.logger.Info("Query response saved successfully",
 		zap.String("query_id", record.ID.Hex()),
 		zap.Bool("success", record.ProcessingSuccess),
-		zap.Duration("response_time", record.ResponseTime))
+		zap.Int64("response_time_ms", record.ResponseTimeMs))
 
 	return nil
 }
 
 // GetQueryStats returns statistics about stored queries
 func (qa *QueryAnalytics) GetQueryStats(ctx context.Context) (map[string]interface{}, error) {
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, qa.readTimeout)
 	defer cancel()
 
 	pipeline := mongo.Pipeline{
@@ -139,7 +200,7 @@ func (qa *QueryAnalytics) GetQueryStats(ctx context.Context) (map[string]interfa
 			{"total_queries", bson.D{{"$sum", 1}}},
 			{"successful_queries", bson.D{{"$sum", bson.D{{"$cond", bson.A{bson.D{{"$eq", bson.A{"$processing_success", true}}}, 1, 0}}}}}},
 			{"failed_queries", bson.D{{"$sum", bson.D{{"$cond", bson.A{bson.D{{"$eq", bson.A{"$processing_success", false}}}, 1, 0}}}}}},
-			{"avg_response_time", bson.D{{"$avg", "$response_time"}}},
+			{"avg_response_time_ms", avgResponseTimeMs},
 			{"total_concepts_identified", bson.D{{"$sum", bson.D{{"$size", "$identified_concepts"}}}}},
 		}}},
 	}
@@ -160,7 +221,7 @@ func (qa *QueryAnalytics) GetQueryStats(ctx context.Context) (map[string]interfa
 			"total_queries":             0,
 			"successful_queries":        0,
 			"failed_queries":            0,
-			"avg_response_time":         0.0,
+			"avg_response_time_ms":      0.0,
 			"total_concepts_identified": 0,
 		}, nil
 	}
@@ -170,7 +231,7 @@ func (qa *QueryAnalytics) GetQueryStats(ctx context.Context) (map[string]interfa
 
 // GetRecentQueries returns recent queries for a user
 func (qa *QueryAnalytics) GetRecentQueries(ctx context.Context, userID string, limit int) ([]QueryResponseRecord, error) {
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, qa.readTimeout)
 	defer cancel()
 
 	filter := bson.M{"user_id": userID}
@@ -192,9 +253,81 @@ func (qa *QueryAnalytics) GetRecentQueries(ctx context.Context, userID string, l
 	return queries, nil
 }
 
+// maxSearchPageSize caps the page size of SearchQueryResponses
+const maxSearchPageSize = 100
+
+// SearchQueryResponses returns the page of query records matching filter that starts
+// at offset, newest first, together with the total number of matches. limit defaults
+// to filter.Limit, then 20, and is capped at maxSearchPageSize. Filters map onto the
+// timestamp, success/timestamp, llm_provider and query text indexes.
+func (qa *QueryAnalytics) SearchQueryResponses(ctx context.Context, filter repositories.AnalyticsFilter, offset, limit int) ([]QueryResponseRecord, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, qa.readTimeout)
+	defer cancel()
+
+	if limit <= 0 {
+		limit = filter.Limit
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > maxSearchPageSize {
+		limit = maxSearchPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := bson.D{}
+	if filter.Text != nil && strings.TrimSpace(*filter.Text) != "" {
+		query = append(query, bson.E{"$text", bson.D{{"$search", strings.TrimSpace(*filter.Text)}}})
+	}
+	if filter.Success != nil {
+		query = append(query, bson.E{"processing_success", *filter.Success})
+	}
+	if filter.StartTime != nil || filter.EndTime != nil {
+		timeRange := bson.D{}
+		if filter.StartTime != nil {
+			timeRange = append(timeRange, bson.E{"$gte", *filter.StartTime})
+		}
+		if filter.EndTime != nil {
+			timeRange = append(timeRange, bson.E{"$lt", *filter.EndTime})
+		}
+		query = append(query, bson.E{"timestamp", timeRange})
+	}
+	if filter.Provider != nil && *filter.Provider != "" {
+		query = append(query, bson.E{"llm_provider", *filter.Provider})
+	}
+	if filter.UserID != nil && *filter.UserID != "" {
+		query = append(query, bson.E{"user_id", *filter.UserID})
+	}
+
+	total, err := qa.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count query responses: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{"timestamp", -1}, {"_id", -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := qa.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search query responses: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	records := []QueryResponseRecord{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode query responses: %w", err)
+	}
+
+	return records, total, nil
+}
+
 // GetPopularConcepts returns the most frequently identified concepts
 func (qa *QueryAnalytics) GetPopularConcepts(ctx context.Context, limit int) ([]map[string]interface{}, error) {
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, qa.readTimeout)
 	defer cancel()
 
 	pipeline := mongo.Pipeline{
@@ -224,7 +357,7 @@ func (qa *QueryAnalytics) GetPopularConcepts(ctx context.Context, limit int) ([]
 
 // GetQueryTrends returns query trends over time
 func (qa *QueryAnalytics) GetQueryTrends(ctx context.Context, days int) ([]map[string]interface{}, error) {
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, qa.readTimeout)
 	defer cancel()
 
 	startDate := time.Now().AddDate(0, 0, -days)
@@ -240,7 +373,7 @@ func (qa *QueryAnalytics) GetQueryTrends(ctx context.Context, days int) ([]map[s
 			}},
 			{"total_queries", bson.D{{"$sum", 1}}},
 			{"successful_queries", bson.D{{"$sum", bson.D{{"$cond", bson.A{bson.D{{"$eq", bson.A{"$processing_success", true}}}, 1, 0}}}}}},
-			{"avg_response_time", bson.D{{"$avg", "$response_time"}}},
+			{"avg_response_time_ms", avgResponseTimeMs},
 		}}},
 		{{"$sort", bson.D{{"_id", 1}}}},
 	}
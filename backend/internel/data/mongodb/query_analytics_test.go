@@ -0,0 +1,97 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// unreachableAnalytics returns a QueryAnalytics whose server never answers, so every
+// call blocks in server selection until its context is done
+func unreachableAnalytics(t *testing.T, read, write time.Duration) *QueryAnalytics {
+	t.Helper()
+	client, err := mongo.Connect(context.Background(), options.Client().
+		ApplyURI("mongodb://127.0.0.1:1").
+		SetServerSelectionTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Disconnect(context.Background()) })
+
+	return &QueryAnalytics{
+		collection:   client.Database("test").Collection("query_responses"),
+		logger:       zap.NewNop(),
+		readTimeout:  read,
+		writeTimeout: write,
+	}
+}
+
+func TestQueryAnalyticsTimeouts(t *testing.T) {
+	tests := []struct {
+		name           string
+		callerDeadline time.Duration
+		timeout        time.Duration
+		call           func(qa *QueryAnalytics, ctx context.Context) error
+	}{
+		{
+			name:           "caller deadline shorter than read timeout",
+			callerDeadline: time.Second,
+			timeout:        30 * time.Second,
+			call: func(qa *QueryAnalytics, ctx context.Context) error {
+				_, err := qa.GetQueryStats(ctx)
+				return err
+			},
+		},
+		{
+			name:           "caller deadline shorter than write timeout",
+			callerDeadline: time.Second,
+			timeout:        30 * time.Second,
+			call: func(qa *QueryAnalytics, ctx context.Context) error {
+				return qa.SaveQueryResponse(ctx, &QueryResponseRecord{Query: "q"})
+			},
+		},
+		{
+			name:    "read timeout without caller deadline",
+			timeout: time.Second,
+			call: func(qa *QueryAnalytics, ctx context.Context) error {
+				_, err := qa.GetRecentQueries(ctx, "", 10)
+				return err
+			},
+		},
+		{
+			name:           "read timeout shorter than caller deadline",
+			callerDeadline: 30 * time.Second,
+			timeout:        time.Second,
+			call: func(qa *QueryAnalytics, ctx context.Context) error {
+				_, err := qa.GetPopularConcepts(ctx, 10)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			qa := unreachableAnalytics(t, tt.timeout, tt.timeout)
+
+			ctx := context.Background()
+			if tt.callerDeadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.callerDeadline)
+				defer cancel()
+			}
+
+			start := time.Now()
+			if err := tt.call(qa, ctx); err == nil {
+				t.Fatal("expected an error from an unreachable server")
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("call took %v, want about 1s", elapsed)
+			}
+		})
+	}
+}
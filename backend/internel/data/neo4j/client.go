@@ -2,17 +2,46 @@ package neo4j
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"mathprereq/internel/core/config"
+	"mathprereq/internel/types"
 	"mathprereq/pkg/logger"
+	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v6/neo4j"
+	neo4jconfig "github.com/neo4j/neo4j-go-driver/v6/neo4j/config"
 	"go.uber.org/zap"
 )
 
 type Client struct {
-	driver neo4j.Driver
-	logger *zap.Logger
+	driver      neo4j.Driver
+	logger      *zap.Logger
+	diagnostics diagnosticsConfig
+	// openWriteSession replaces the driver's write sessions when set (tests)
+	openWriteSession func(ctx context.Context) writeSession
+}
+
+// writeSession is the part of a driver session graph writes use: ExecuteWrite retries
+// the transaction function on transient errors
+type writeSession interface {
+	ExecuteWrite(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error)
+	Close(ctx context.Context) error
+}
+
+// newWriteSession opens a session for graph writes
+func (c *Client) newWriteSession(ctx context.Context) writeSession {
+	if c.openWriteSession != nil {
+		return c.openWriteSession(ctx)
+	}
+	return c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+}
+
+// diagnosticsConfig guards ExecuteReadQuery
+type diagnosticsConfig struct {
+	enabled bool
+	timeout time.Duration
+	maxRows int
 }
 
 type Concept struct {
@@ -20,6 +49,18 @@ type Concept struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Type        string `json:"type"`
+	// Roles lists every role of the concept in the result; Type is the primary one
+	Roles []string `json:"roles,omitempty"`
+	// CreatedAt and UpdatedAt are zero for concepts written before timestamps were tracked,
+	// and on results of queries that do not return them
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Edge is a PREREQUISITE_FOR relationship from Source to Target
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
 }
 
 type PrerequisitePathResult struct {
@@ -33,12 +74,21 @@ type ConceptDetailResult struct {
 	DetailedExplanation string    `json:"detailed_explanation"`
 }
 
+// ErrConceptNotFound is returned when a concept name cannot be resolved to a node
+var ErrConceptNotFound = errors.New("concept not found")
+
 func NewClient(cfg config.Neo4jConfig) (*Client, error) {
 	logger := logger.MustGetLogger()
 
 	driver, err := neo4j.NewDriver(
 		cfg.URI,
 		neo4j.BasicAuth(cfg.Username, cfg.Password, ""),
+		func(driverConfig *neo4jconfig.Config) {
+			// ExecuteWrite retries transient errors (leader switch, deadlock) until this elapses
+			if cfg.MaxTransactionRetryTime > 0 {
+				driverConfig.MaxTransactionRetryTime = cfg.MaxTransactionRetryTime
+			}
+		},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Neo4j driver: %w", err)
@@ -51,9 +101,22 @@ func NewClient(cfg config.Neo4jConfig) (*Client, error) {
 
 	logger.Info("Connected to Neo4j", zap.String("uri", cfg.URI))
 
+	diagnostics := diagnosticsConfig{
+		enabled: cfg.DiagnosticQueriesEnabled,
+		timeout: cfg.DiagnosticQueryTimeout,
+		maxRows: cfg.DiagnosticMaxRows,
+	}
+	if diagnostics.timeout <= 0 {
+		diagnostics.timeout = 10 * time.Second
+	}
+	if diagnostics.maxRows <= 0 {
+		diagnostics.maxRows = 1000
+	}
+
 	return &Client{
-		driver: driver,
-		logger: logger,
+		driver:      driver,
+		logger:      logger,
+		diagnostics: diagnostics,
 	}, nil
 }
 
@@ -106,6 +169,18 @@ func toString(value interface{}) string {
 	return fmt.Sprintf("%v", value)
 }
 
+// toTime converts a temporal property to time.Time; missing values yield the zero time
+func toTime(value interface{}) time.Time {
+	switch t := value.(type) {
+	case time.Time:
+		return t
+	case neo4j.LocalDateTime:
+		return t.Time()
+	default:
+		return time.Time{}
+	}
+}
+
 func (c *Client) Close() error {
 	return c.driver.Close(context.Background())
 }
@@ -180,6 +255,7 @@ func (c *Client) GetConceptInfo(ctx context.Context, conceptID string) (*Concept
 		OPTIONAL MATCH (prereq:Concept)-[:PREREQUISITE_FOR]->(c)
 		OPTIONAL MATCH (c)-[:PREREQUISITE_FOR]->(next:Concept)
 		RETURN c.id as id, c.name as name, c.description as description,
+		       c.created_at as created_at, c.updated_at as updated_at,
 		       COLLECT(DISTINCT {id: prereq.id, name: prereq.name, description: prereq.description}) as prerequisites,
 		       COLLECT(DISTINCT {id: next.id, name: next.name, description: next.description}) as leads_to
 	`
@@ -196,7 +272,7 @@ func (c *Client) GetConceptInfo(ctx context.Context, conceptID string) (*Concept
 			c.logger.Warn("Concept not found",
 				zap.String("search_term", conceptID),
 				zap.String("suggestion", "Try searching by concept ID (e.g., 'func_basics') or exact name"))
-			return nil, fmt.Errorf("concept not found: %s", conceptID)
+			return nil, fmt.Errorf("%w: %s", ErrConceptNotFound, conceptID)
 		}
 
 		rec := record.Record()
@@ -204,6 +280,8 @@ func (c *Client) GetConceptInfo(ctx context.Context, conceptID string) (*Concept
 		id, _ := rec.Get("id")
 		name, _ := rec.Get("name")
 		description, _ := rec.Get("description")
+		createdAt, _ := rec.Get("created_at")
+		updatedAt, _ := rec.Get("updated_at")
 		prereqsRaw, _ := rec.Get("prerequisites")
 		leadsToRaw, _ := rec.Get("leads_to")
 
@@ -211,7 +289,8 @@ func (c *Client) GetConceptInfo(ctx context.Context, conceptID string) (*Concept
 			ID:          toString(id),
 			Name:        toString(name),
 			Description: toString(description),
-			Type:        "target",
+			CreatedAt:   toTime(createdAt),
+			UpdatedAt:   toTime(updatedAt),
 		}
 
 		var prerequisites []Concept
@@ -223,7 +302,8 @@ func (c *Client) GetConceptInfo(ctx context.Context, conceptID string) (*Concept
 							ID:          toString(prereqMap["id"]),
 							Name:        toString(prereqMap["name"]),
 							Description: toString(prereqMap["description"]),
-							Type:        "prerequisite",
+							Type:        types.ConceptRolePrerequisite,
+							Roles:       []string{types.ConceptRolePrerequisite},
 						})
 					}
 				}
@@ -239,13 +319,20 @@ func (c *Client) GetConceptInfo(ctx context.Context, conceptID string) (*Concept
 							ID:          toString(nextMap["id"]),
 							Name:        toString(nextMap["name"]),
 							Description: toString(nextMap["description"]),
-							Type:        "next_concept",
+							Type:        types.ConceptRoleNext,
+							Roles:       []string{types.ConceptRoleNext},
 						})
 					}
 				}
 			}
 		}
 
+		concept.Roles = detailSubjectRoles(len(prerequisites) > 0, len(leadsTo) > 0)
+		concept.Type = "concept"
+		if len(concept.Roles) > 0 {
+			concept.Type = concept.Roles[0]
+		}
+
 		return &ConceptDetailResult{
 			Concept:       concept,
 			Prerequisites: prerequisites,
@@ -260,29 +347,106 @@ func (c *Client) GetConceptInfo(ctx context.Context, conceptID string) (*Concept
 	return result.(*ConceptDetailResult), nil
 }
 
-func (c *Client) FindPrerequisitePath(ctx context.Context, targetConcepts []string) ([]Concept, error) {
+// detailSubjectRoles are the roles of a concept detail's subject relative to its
+// neighbours: a prerequisite of the concepts it leads to and the next concept of its
+// own prerequisites. It is never a target; no query asked for it.
+func detailSubjectRoles(hasPrerequisites, leadsToOthers bool) []string {
+	var roles []string
+	if leadsToOthers {
+		roles = append(roles, types.ConceptRolePrerequisite)
+	}
+	if hasPrerequisites {
+		roles = append(roles, types.ConceptRoleNext)
+	}
+	return roles
+}
+
+// pathConceptRole is the role of the i-th of n concepts on a prerequisite path: the
+// last one is the target and every concept before it a prerequisite of the next.
+func pathConceptRole(i, n int) string {
+	if i == n-1 {
+		return types.ConceptRoleTarget
+	}
+	return types.ConceptRolePrerequisite
+}
+
+// FindConceptIDs resolves many concept names in one round trip, using the same matching
+// rules as FindConceptID. Names that match no concept are absent from the result.
+func (c *Client) FindConceptIDs(ctx context.Context, conceptNames []string) (map[string]string, error) {
+	if len(conceptNames) == 0 {
+		return map[string]string{}, nil
+	}
+
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	query := `
+		UNWIND $conceptNames as conceptName
+		CALL {
+			WITH conceptName
+			MATCH (c:Concept)
+			WHERE toLower(c.name) CONTAINS toLower(conceptName)
+			   OR toLower(c.id) = toLower(conceptName)
+			RETURN c.id as id
+			LIMIT 1
+		}
+		RETURN conceptName, id
+	`
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		records, err := tx.Run(ctx, query, map[string]interface{}{
+			"conceptNames": conceptNames,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make(map[string]string)
+		for records.Next(ctx) {
+			record := records.Record()
+			name, _ := record.Get("conceptName")
+			id, _ := record.Get("id")
+			ids[toString(name)] = toString(id)
+		}
+		return ids, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find concept IDs: %w", err)
+	}
+
+	return result.(map[string]string), nil
+}
+
+// FindPrerequisitePath returns the target concepts and all their prerequisites, along
+// with the target names that are not in the graph. Targets whose lookup failed are
+// skipped without being reported as unresolved.
+func (c *Client) FindPrerequisitePath(ctx context.Context, targetConcepts []string) ([]Concept, []string, error) {
 	if len(targetConcepts) == 0 {
-		return []Concept{}, nil
+		return []Concept{}, nil, nil
 	}
 
 	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
 	defer session.Close(ctx)
 
 	var targetIDs []string
+	var unresolved []string
 	for _, concept := range targetConcepts {
 		id, err := c.FindConceptID(ctx, concept)
 		if err != nil {
 			c.logger.Warn("Failed to find concept", zap.String("concept", concept), zap.Error(err))
 			continue
 		}
-		if id != nil {
-			targetIDs = append(targetIDs, *id)
+		if id == nil {
+			unresolved = append(unresolved, concept)
+			continue
 		}
+		targetIDs = append(targetIDs, *id)
 	}
 
 	if len(targetIDs) == 0 {
-		c.logger.Warn("No target concepts found in knowledge graph")
-		return []Concept{}, nil
+		c.logger.Warn("No target concepts found in knowledge graph", zap.Strings("unresolved", unresolved))
+		return []Concept{}, unresolved, nil
 	}
 
 	query := `
@@ -294,14 +458,17 @@ func (c *Client) FindPrerequisitePath(ctx context.Context, targetConcepts []stri
 		UNWIND (prerequisites + targets) as concept
 		RETURN DISTINCT concept.id as id, concept.name as name, 
 		       concept.description as description,
-		       CASE WHEN concept.id IN $targetIDs THEN 'target' ELSE 'prerequisite' END as type
+		       CASE WHEN concept.id IN $targetIDs THEN $targetRole ELSE $prerequisiteRole END as type,
+		       concept IN prerequisites as is_prerequisite
 		ORDER BY 
 		  CASE WHEN concept.id IN $targetIDs THEN 1 ELSE 0 END,
 		  concept.name
 	`
 	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
 		records, err := tx.Run(ctx, query, map[string]interface{}{
-			"targetIDs": targetIDs,
+			"targetIDs":        targetIDs,
+			"targetRole":       types.ConceptRoleTarget,
+			"prerequisiteRole": types.ConceptRolePrerequisite,
 		})
 		if err != nil {
 			return nil, err
@@ -315,12 +482,20 @@ func (c *Client) FindPrerequisitePath(ctx context.Context, targetConcepts []stri
 			name, _ := record.Get("name")
 			description, _ := record.Get("description")
 			conceptType, _ := record.Get("type")
+			isPrerequisite, _ := record.Get("is_prerequisite")
+
+			// A target can also be a prerequisite of another target
+			roles := []string{toString(conceptType)}
+			if prereq, _ := isPrerequisite.(bool); prereq && roles[0] != types.ConceptRolePrerequisite {
+				roles = append(roles, types.ConceptRolePrerequisite)
+			}
 
 			concept := Concept{
 				ID:          toString(id),
 				Name:        toString(name),
 				Description: toString(description),
 				Type:        toString(conceptType),
+				Roles:       roles,
 			}
 			concepts = append(concepts, concept)
 		}
@@ -328,12 +503,410 @@ func (c *Client) FindPrerequisitePath(ctx context.Context, targetConcepts []stri
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to find prerequisite path: %w", err)
+		return nil, nil, fmt.Errorf("failed to find prerequisite path: %w", err)
 	}
 	concepts := result.([]Concept)
-	c.logger.Info("Found learning path", zap.Int("concepts", len(concepts)))
+	c.logger.Info("Found learning path",
+		zap.Int("concepts", len(concepts)),
+		zap.Strings("unresolved", unresolved))
 
-	return concepts, nil
+	return concepts, unresolved, nil
+}
+
+// GetPrerequisiteEdges returns the PREREQUISITE_FOR edges whose endpoints are both in conceptIDs
+func (c *Client) GetPrerequisiteEdges(ctx context.Context, conceptIDs []string) ([]Edge, error) {
+	if len(conceptIDs) == 0 {
+		return []Edge{}, nil
+	}
+
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (source:Concept)-[:PREREQUISITE_FOR]->(target:Concept)
+		WHERE source.id IN $conceptIDs AND target.id IN $conceptIDs
+		RETURN source.id as source, target.id as target
+		ORDER BY source, target
+	`
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		records, err := tx.Run(ctx, query, map[string]interface{}{
+			"conceptIDs": conceptIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		edges := []Edge{}
+		for records.Next(ctx) {
+			record := records.Record()
+			source, _ := record.Get("source")
+			target, _ := record.Get("target")
+			edges = append(edges, Edge{
+				Source: toString(source),
+				Target: toString(target),
+			})
+		}
+		return edges, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prerequisite edges: %w", err)
+	}
+
+	return result.([]Edge), nil
+}
+
+// GetNextConcepts returns the direct successors of a concept (matched by ID or name),
+// ranked by how many concepts each of them is a prerequisite for. A concept with no
+// successors yields an empty slice; an unknown concept returns ErrConceptNotFound.
+func (c *Client) GetNextConcepts(ctx context.Context, conceptID string, limit int) ([]Concept, error) {
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (c:Concept)
+		WHERE c.id = $conceptId OR c.name = $conceptId
+		WITH c LIMIT 1
+		OPTIONAL MATCH (c)-[:PREREQUISITE_FOR]->(next:Concept)
+		OPTIONAL MATCH (next)-[:PREREQUISITE_FOR]->(unlocked:Concept)
+		WITH next, count(DISTINCT unlocked) as unlocks
+		RETURN next.id as id, next.name as name, next.description as description, unlocks
+		ORDER BY unlocks DESC, name
+		LIMIT $limit
+	`
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		records, err := tx.Run(ctx, query, map[string]interface{}{
+			"conceptId": conceptID,
+			"limit":     limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		concepts := []Concept{}
+		for records.Next(ctx) {
+			found = true
+			record := records.Record()
+			id, _ := record.Get("id")
+			if id == nil {
+				continue // the concept has no successors
+			}
+			name, _ := record.Get("name")
+			description, _ := record.Get("description")
+			concepts = append(concepts, Concept{
+				ID:          toString(id),
+				Name:        toString(name),
+				Description: toString(description),
+				Type:        types.ConceptRoleNext,
+				Roles:       []string{types.ConceptRoleNext},
+			})
+		}
+		if err := records.Err(); err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrConceptNotFound, conceptID)
+		}
+		return concepts, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next concepts: %w", err)
+	}
+
+	return result.([]Concept), nil
+}
+
+// GetConceptsWithoutDescription returns concepts whose description is missing or blank
+func (c *Client) GetConceptsWithoutDescription(ctx context.Context, limit int) ([]Concept, error) {
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (c:Concept)
+		WHERE c.description IS NULL OR trim(c.description) = ''
+		RETURN c.id as id, c.name as name
+		ORDER BY c.name
+		LIMIT $limit
+	`
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		records, err := tx.Run(ctx, query, map[string]interface{}{
+			"limit": limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var concepts []Concept
+		for records.Next(ctx) {
+			record := records.Record()
+
+			id, _ := record.Get("id")
+			name, _ := record.Get("name")
+
+			concepts = append(concepts, Concept{
+				ID:   toString(id),
+				Name: toString(name),
+				Type: "concept",
+			})
+		}
+		return concepts, nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get concepts without description: %w", err)
+	}
+
+	return result.([]Concept), nil
+}
+
+// Graph writes below run through ExecuteWrite, which retries the transaction function on
+// transient errors. Every function is MERGE-based so a retried or repeated write never
+// creates duplicate nodes or edges. Writes stamp concepts with created_at when created and
+// updated_at whenever the concept or one of its prerequisite edges changes.
+
+// CreateConcept creates the concept or updates its name and description if it already exists.
+// A blank description never overwrites an existing one.
+func (c *Client) CreateConcept(ctx context.Context, concept Concept) error {
+	if concept.ID == "" {
+		return fmt.Errorf("concept id is required")
+	}
+
+	session := c.newWriteSession(ctx)
+	defer session.Close(ctx)
+
+	query := `
+		MERGE (c:Concept {id: $id})
+		ON CREATE SET c.created_at = datetime()
+		SET c.name = $name,
+		    c.description = CASE WHEN $description <> '' THEN $description ELSE c.description END,
+		    c.updated_at = datetime()
+	`
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		result, err := tx.Run(ctx, query, map[string]interface{}{
+			"id":          concept.ID,
+			"name":        concept.Name,
+			"description": concept.Description,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result.Consume(ctx)
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to create concept: %w", err)
+	}
+
+	return nil
+}
+
+// EnsureConcept creates a stub concept marked source "auto" when no concept with the id
+// exists, leaving existing concepts untouched. It reports whether a stub was created.
+func (c *Client) EnsureConcept(ctx context.Context, conceptID, name string) (bool, error) {
+	if conceptID == "" {
+		return false, fmt.Errorf("concept id is required")
+	}
+
+	session := c.newWriteSession(ctx)
+	defer session.Close(ctx)
+
+	query := `
+		OPTIONAL MATCH (existing:Concept {id: $id})
+		WITH existing IS NULL as created
+		MERGE (c:Concept {id: $id})
+		ON CREATE SET c.name = $name, c.description = '', c.source = 'auto',
+		              c.created_at = datetime(), c.updated_at = datetime()
+		RETURN created
+	`
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		record, err := tx.Run(ctx, query, map[string]interface{}{
+			"id":   conceptID,
+			"name": name,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if record.Next(ctx) {
+			created, _ := record.Record().Get("created")
+			if value, ok := created.(bool); ok {
+				return value, nil
+			}
+		}
+		return false, nil
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("failed to ensure concept: %w", err)
+	}
+
+	return result.(bool), nil
+}
+
+// CreatePrerequisite records that prerequisiteID is a prerequisite for conceptID.
+// Both concepts must already exist.
+func (c *Client) CreatePrerequisite(ctx context.Context, prerequisiteID, conceptID string) error {
+	if prerequisiteID == conceptID {
+		return fmt.Errorf("concept %s cannot be its own prerequisite", conceptID)
+	}
+
+	session := c.newWriteSession(ctx)
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (prerequisite:Concept {id: $prerequisiteId})
+		MATCH (concept:Concept {id: $conceptId})
+		MERGE (prerequisite)-[r:PREREQUISITE_FOR]->(concept)
+		ON CREATE SET r.created_at = datetime(),
+		              prerequisite.updated_at = datetime(),
+		              concept.updated_at = datetime()
+		RETURN count(*) as matched
+	`
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		record, err := tx.Run(ctx, query, map[string]interface{}{
+			"prerequisiteId": prerequisiteID,
+			"conceptId":      conceptID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if record.Next(ctx) {
+			matched, _ := record.Record().Get("matched")
+			if count, ok := matched.(int64); ok {
+				return count > 0, nil
+			}
+		}
+		return false, nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to create prerequisite: %w", err)
+	}
+	if !result.(bool) {
+		return fmt.Errorf("%w: %s or %s", ErrConceptNotFound, prerequisiteID, conceptID)
+	}
+
+	return nil
+}
+
+// MergeConcepts folds duplicateID into keepID: the duplicate's edges are re-pointed at the
+// kept concept, its description fills a blank one, and the duplicate is deleted. Merging a
+// duplicate that no longer exists is a no-op.
+func (c *Client) MergeConcepts(ctx context.Context, keepID, duplicateID string) error {
+	if keepID == duplicateID {
+		return fmt.Errorf("cannot merge concept %s into itself", keepID)
+	}
+
+	session := c.newWriteSession(ctx)
+	defer session.Close(ctx)
+
+	statements := []string{
+		`MATCH (dup:Concept {id: $duplicateId})-[:PREREQUISITE_FOR]->(next:Concept)
+		 WHERE next.id <> $keepId
+		 MATCH (keep:Concept {id: $keepId})
+		 MERGE (keep)-[r:PREREQUISITE_FOR]->(next)
+		 ON CREATE SET r.created_at = datetime(), next.updated_at = datetime()`,
+		`MATCH (prev:Concept)-[:PREREQUISITE_FOR]->(dup:Concept {id: $duplicateId})
+		 WHERE prev.id <> $keepId
+		 MATCH (keep:Concept {id: $keepId})
+		 MERGE (prev)-[r:PREREQUISITE_FOR]->(keep)
+		 ON CREATE SET r.created_at = datetime(), prev.updated_at = datetime()`,
+		`MATCH (dup:Concept {id: $duplicateId})
+		 MATCH (keep:Concept {id: $keepId})
+		 WHERE keep.description IS NULL OR trim(keep.description) = ''
+		 SET keep.description = dup.description`,
+		`MATCH (keep:Concept {id: $keepId})
+		 SET keep.updated_at = datetime()`,
+		`MATCH (dup:Concept {id: $duplicateId})
+		 DETACH DELETE dup`,
+	}
+	params := map[string]interface{}{
+		"keepId":      keepID,
+		"duplicateId": duplicateID,
+	}
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		record, err := tx.Run(ctx, `MATCH (keep:Concept {id: $keepId}) RETURN count(keep) as found`, params)
+		if err != nil {
+			return nil, err
+		}
+		found := int64(0)
+		if record.Next(ctx) {
+			value, _ := record.Record().Get("found")
+			found, _ = value.(int64)
+		}
+		if found == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrConceptNotFound, keepID)
+		}
+
+		for _, statement := range statements {
+			result, err := tx.Run(ctx, statement, params)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := result.Consume(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to merge concepts: %w", err)
+	}
+
+	c.logger.Info("Merged concepts",
+		zap.String("kept", keepID),
+		zap.String("merged", duplicateID))
+
+	return nil
+}
+
+// SetDescriptionIfBlank writes a description only when the concept still has none,
+// so concurrent or repeated runs never overwrite curated text
+func (c *Client) SetDescriptionIfBlank(ctx context.Context, conceptID, description string) (bool, error) {
+	session := c.newWriteSession(ctx)
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (c:Concept {id: $conceptId})
+		WHERE c.description IS NULL OR trim(c.description) = ''
+		SET c.description = $description, c.updated_at = datetime()
+		RETURN count(c) as updated
+	`
+
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		record, err := tx.Run(ctx, query, map[string]interface{}{
+			"conceptId":   conceptID,
+			"description": description,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if record.Next(ctx) {
+			updated, _ := record.Record().Get("updated")
+			if count, ok := updated.(int64); ok {
+				return count > 0, nil
+			}
+		}
+		return false, nil
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("failed to set concept description: %w", err)
+	}
+
+	return result.(bool), nil
 }
 
 func (c *Client) GetAllConcepts(ctx context.Context) ([]Concept, error) {
@@ -342,7 +915,8 @@ func (c *Client) GetAllConcepts(ctx context.Context) ([]Concept, error) {
 
 	query := `
 		MATCH (c:Concept)
-		RETURN c.id as id, c.name as name, c.description as description
+		RETURN c.id as id, c.name as name, c.description as description,
+		       c.created_at as created_at, c.updated_at as updated_at
 		ORDER BY c.name
 	`
 
@@ -359,12 +933,16 @@ func (c *Client) GetAllConcepts(ctx context.Context) ([]Concept, error) {
 			id, _ := record.Get("id")
 			name, _ := record.Get("name")
 			description, _ := record.Get("description")
+			createdAt, _ := record.Get("created_at")
+			updatedAt, _ := record.Get("updated_at")
 
 			concept := Concept{
 				ID:          toString(id),
 				Name:        toString(name),
 				Description: toString(description),
 				Type:        "concept",
+				CreatedAt:   toTime(createdAt),
+				UpdatedAt:   toTime(updatedAt),
 			}
 			concepts = append(concepts, concept)
 		}
@@ -378,3 +956,83 @@ func (c *Client) GetAllConcepts(ctx context.Context) ([]Concept, error) {
 
 	return result.([]Concept), nil
 }
+
+// IsPrerequisiteOf checks whether a PREREQUISITE_FOR path leads from conceptA to conceptB.
+// When a path exists, the ordered concepts along the shortest path (from A to B inclusive)
+// are returned. A missing path is not an error; unresolved names return ErrConceptNotFound.
+func (c *Client) IsPrerequisiteOf(ctx context.Context, conceptA, conceptB string) (bool, []Concept, error) {
+	fromID, err := c.FindConceptID(ctx, conceptA)
+	if err != nil {
+		return false, nil, err
+	}
+	if fromID == nil {
+		return false, nil, fmt.Errorf("%w: %s", ErrConceptNotFound, conceptA)
+	}
+
+	toID, err := c.FindConceptID(ctx, conceptB)
+	if err != nil {
+		return false, nil, err
+	}
+	if toID == nil {
+		return false, nil, fmt.Errorf("%w: %s", ErrConceptNotFound, conceptB)
+	}
+
+	if *fromID == *toID {
+		return false, []Concept{}, nil
+	}
+
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (a:Concept {id: $fromId}), (b:Concept {id: $toId})
+		MATCH path = shortestPath((a)-[:PREREQUISITE_FOR*]->(b))
+		RETURN [n IN nodes(path) | {id: n.id, name: n.name, description: n.description}] as concepts
+	`
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		record, err := tx.Run(ctx, query, map[string]interface{}{
+			"fromId": *fromID,
+			"toId":   *toID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !record.Next(ctx) {
+			return nil, nil
+		}
+
+		conceptsRaw, _ := record.Record().Get("concepts")
+
+		var concepts []Concept
+		if conceptsList, ok := conceptsRaw.([]interface{}); ok {
+			for i, conceptRaw := range conceptsList {
+				if conceptMap, ok := conceptRaw.(map[string]interface{}); ok {
+					role := pathConceptRole(i, len(conceptsList))
+					concepts = append(concepts, Concept{
+						ID:          toString(conceptMap["id"]),
+						Name:        toString(conceptMap["name"]),
+						Description: toString(conceptMap["description"]),
+						Type:        role,
+						Roles:       []string{role},
+					})
+				}
+			}
+		}
+		return concepts, nil
+	})
+
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to check prerequisite relationship: %w", err)
+	}
+
+	if result == nil {
+		c.logger.Info("No prerequisite path between concepts",
+			zap.String("from", *fromID),
+			zap.String("to", *toID))
+		return false, []Concept{}, nil
+	}
+
+	return true, result.([]Concept), nil
+}
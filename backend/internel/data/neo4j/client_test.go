@@ -0,0 +1,82 @@
+package neo4j
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mathprereq/internel/types"
+)
+
+func TestDetailSubjectRoles(t *testing.T) {
+	tests := []struct {
+		name             string
+		hasPrerequisites bool
+		leadsToOthers    bool
+		want             []string
+	}{
+		{name: "isolated concept", want: nil},
+		{name: "foundation", leadsToOthers: true, want: []string{types.ConceptRolePrerequisite}},
+		{name: "leaf", hasPrerequisites: true, want: []string{types.ConceptRoleNext}},
+		{
+			name:             "both prerequisite and next concept",
+			hasPrerequisites: true,
+			leadsToOthers:    true,
+			want:             []string{types.ConceptRolePrerequisite, types.ConceptRoleNext},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detailSubjectRoles(tt.hasPrerequisites, tt.leadsToOthers); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detailSubjectRoles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathConceptRole(t *testing.T) {
+	tests := []struct {
+		name string
+		i, n int
+		want string
+	}{
+		{name: "start of path", i: 0, n: 3, want: types.ConceptRolePrerequisite},
+		{name: "middle of path", i: 1, n: 3, want: types.ConceptRolePrerequisite},
+		{name: "end of path", i: 2, n: 3, want: types.ConceptRoleTarget},
+		{name: "direct prerequisite", i: 0, n: 2, want: types.ConceptRolePrerequisite},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pathConceptRole(tt.i, tt.n); got != tt.want {
+				t.Errorf("pathConceptRole(%d, %d) = %q, want %q", tt.i, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphWritesRejectInvalidInput(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		write func() error
+		want  string
+	}{
+		{"concept without id", func() error { return c.CreateConcept(ctx, Concept{Name: "Limits"}) }, "concept id is required"},
+		{"self prerequisite", func() error { return c.CreatePrerequisite(ctx, "limits", "limits") }, "cannot be its own prerequisite"},
+		{"self merge", func() error { return c.MergeConcepts(ctx, "limits", "limits") }, "cannot merge concept limits into itself"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.write()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package neo4j
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v6/neo4j"
+)
+
+var (
+	// ErrDiagnosticsDisabled is returned when diagnostic queries are not enabled in config
+	ErrDiagnosticsDisabled = errors.New("diagnostic queries are disabled")
+	// ErrWriteQuery is returned when a diagnostic query contains a write clause
+	ErrWriteQuery = errors.New("query is not read-only")
+	// ErrInvalidQuery is returned when Neo4j rejects a diagnostic query as a bad
+	// statement (syntax, semantics or parameters)
+	ErrInvalidQuery = errors.New("invalid query")
+)
+
+var (
+	// cypherStringOrComment matches string literals, quoted identifiers and comments so
+	// keywords inside them are ignored
+	cypherStringOrComment = regexp.MustCompile(`'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"|` + "`[^`]*`" + `|//[^\n]*|/\*[\s\S]*?\*/`)
+	// cypherWriteClause matches clauses that modify the graph or schema. Procedure calls
+	// are rejected too since procedures can write; CALL { ... } subqueries are allowed.
+	// Keywords used as property names (n.set) are not clauses and are skipped.
+	cypherWriteClause = regexp.MustCompile(`(?i)(?:^|[^.\w])(?:(CREATE|MERGE|DELETE|DETACH|SET|REMOVE|DROP|FOREACH|LOAD\s+CSV)\b|(CALL)\s+[A-Za-z_])`)
+)
+
+// validateReadOnlyCypher rejects queries containing write clauses. The session is
+// opened in read mode as well, so this is a first line of defence with clear errors.
+func validateReadOnlyCypher(cypher string) error {
+	if strings.TrimSpace(cypher) == "" {
+		return fmt.Errorf("%w: query is empty", ErrWriteQuery)
+	}
+	stripped := cypherStringOrComment.ReplaceAllString(cypher, " ")
+	if match := cypherWriteClause.FindStringSubmatch(stripped); match != nil {
+		clause := match[1] + match[2]
+		return fmt.Errorf("%w: %s is not allowed", ErrWriteQuery, strings.ToUpper(strings.Fields(clause)[0]))
+	}
+	return nil
+}
+
+// ExecuteReadQuery runs an operator-supplied read-only query and returns its rows as
+// generic maps, capped at the configured row limit. Only read sessions are used.
+func (c *Client) ExecuteReadQuery(ctx context.Context, cypher string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	if !c.diagnostics.enabled {
+		return nil, ErrDiagnosticsDisabled
+	}
+	if err := validateReadOnlyCypher(cypher); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.diagnostics.timeout)
+	defer cancel()
+
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	result, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		records, err := tx.Run(ctx, cypher, params)
+		if err != nil {
+			return nil, err
+		}
+
+		rows := []map[string]interface{}{}
+		for records.Next(ctx) {
+			if len(rows) >= c.diagnostics.maxRows {
+				break
+			}
+			rows = append(rows, records.Record().AsMap())
+		}
+		return rows, records.Err()
+	})
+	if err != nil {
+		var neo4jErr *neo4j.Neo4jError
+		if errors.As(err, &neo4jErr) && neo4jErr.Classification() == "ClientError" && neo4jErr.Category() == "Statement" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidQuery, neo4jErr.Msg)
+		}
+		return nil, fmt.Errorf("diagnostic query failed: %w", err)
+	}
+
+	return result.([]map[string]interface{}), nil
+}
@@ -0,0 +1,194 @@
+package neo4j
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v6/neo4j"
+	"go.uber.org/zap"
+)
+
+// fakeGraph is an in-memory graph behind fake write sessions. Its Cypher support is just
+// what the graph writes issue: MERGE keeps one node or edge per key, CREATE always adds.
+type fakeGraph struct {
+	nodes []fakeNode
+	edges [][2]string
+	// transientFailures is how many statements fail with a retryable error after
+	// staging their change
+	transientFailures int
+	attempts          int
+}
+
+type fakeNode struct {
+	id, name, description string
+}
+
+func (g *fakeGraph) session(ctx context.Context) writeSession {
+	return &fakeSession{graph: g}
+}
+
+// fakeSession retries a transaction function on retryable errors like ExecuteWrite,
+// discarding the failed attempt's changes
+type fakeSession struct {
+	graph *fakeGraph
+}
+
+func (s *fakeSession) ExecuteWrite(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	for attempt := 1; ; attempt++ {
+		s.graph.attempts++
+		tx := &fakeTx{
+			graph: s.graph,
+			nodes: append([]fakeNode(nil), s.graph.nodes...),
+			edges: append([][2]string(nil), s.graph.edges...),
+		}
+		result, err := work(tx)
+		if err == nil {
+			s.graph.nodes, s.graph.edges = tx.nodes, tx.edges
+			return result, nil
+		}
+		if !neo4j.IsRetryable(err) || attempt == 5 {
+			return nil, err
+		}
+	}
+}
+
+func (s *fakeSession) Close(ctx context.Context) error { return nil }
+
+// fakeTx stages changes until its transaction function succeeds
+type fakeTx struct {
+	graph *fakeGraph
+	nodes []fakeNode
+	edges [][2]string
+}
+
+func (tx *fakeTx) Run(ctx context.Context, cypher string, params map[string]any) (neo4j.Result, error) {
+	var records []*neo4j.Record
+	switch {
+	case strings.Contains(cypher, "MERGE (c:Concept {id: $id})"), strings.Contains(cypher, "CREATE (c:Concept {id: $id})"):
+		node := fakeNode{id: params["id"].(string), name: params["name"].(string), description: params["description"].(string)}
+		i := tx.findNode(node.id)
+		if i < 0 || strings.Contains(cypher, "CREATE (c:Concept") {
+			tx.nodes = append(tx.nodes, node)
+		} else {
+			tx.nodes[i].name = node.name
+			if node.description != "" {
+				tx.nodes[i].description = node.description
+			}
+		}
+	case strings.Contains(cypher, "MERGE (prerequisite)-[r:PREREQUISITE_FOR]->(concept)"), strings.Contains(cypher, "CREATE (prerequisite)"):
+		edge := [2]string{params["prerequisiteId"].(string), params["conceptId"].(string)}
+		matched := int64(0)
+		if tx.findNode(edge[0]) >= 0 && tx.findNode(edge[1]) >= 0 {
+			matched = 1
+			if strings.Contains(cypher, "CREATE (prerequisite)") || !tx.hasEdge(edge) {
+				tx.edges = append(tx.edges, edge)
+			}
+		}
+		records = append(records, &neo4j.Record{Keys: []string{"matched"}, Values: []any{matched}})
+	}
+
+	if tx.graph.transientFailures > 0 {
+		tx.graph.transientFailures--
+		return nil, &neo4j.Neo4jError{Code: "Neo.TransientError.Transaction.DeadlockDetected", Msg: "deadlock detected"}
+	}
+	return &fakeResult{records: records}, nil
+}
+
+func (tx *fakeTx) findNode(id string) int {
+	for i, node := range tx.nodes {
+		if node.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (tx *fakeTx) hasEdge(edge [2]string) bool {
+	for _, e := range tx.edges {
+		if e == edge {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeResult serves fixed records; methods the graph writes do not call stay unimplemented
+type fakeResult struct {
+	neo4j.Result
+	records []*neo4j.Record
+	current *neo4j.Record
+}
+
+func (r *fakeResult) Next(ctx context.Context) bool {
+	if len(r.records) == 0 {
+		return false
+	}
+	r.current, r.records = r.records[0], r.records[1:]
+	return true
+}
+
+func (r *fakeResult) Record() *neo4j.Record { return r.current }
+
+func (r *fakeResult) Consume(ctx context.Context) (neo4j.ResultSummary, error) { return nil, nil }
+
+func TestGraphWritesRetryWithoutDuplicates(t *testing.T) {
+	tests := []struct {
+		name              string
+		transientFailures int
+		repeats           int
+	}{
+		{name: "no failure", repeats: 1},
+		{name: "transient failure is retried", transientFailures: 1, repeats: 1},
+		{name: "several transient failures", transientFailures: 3, repeats: 1},
+		{name: "repeated writes after a failure", transientFailures: 1, repeats: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := &fakeGraph{}
+			c := &Client{logger: zap.NewNop(), openWriteSession: graph.session}
+			ctx := context.Background()
+
+			for i := 0; i < tt.repeats; i++ {
+				graph.transientFailures = tt.transientFailures
+				if err := c.CreateConcept(ctx, Concept{ID: "limits", Name: "Limits", Description: "Approaching values"}); err != nil {
+					t.Fatalf("CreateConcept: %v", err)
+				}
+				if err := c.CreateConcept(ctx, Concept{ID: "derivatives", Name: "Derivatives"}); err != nil {
+					t.Fatalf("CreateConcept: %v", err)
+				}
+				graph.transientFailures = tt.transientFailures
+				if err := c.CreatePrerequisite(ctx, "limits", "derivatives"); err != nil {
+					t.Fatalf("CreatePrerequisite: %v", err)
+				}
+			}
+
+			if len(graph.nodes) != 2 {
+				t.Errorf("nodes = %+v, want limits and derivatives once each", graph.nodes)
+			}
+			if len(graph.edges) != 1 || graph.edges[0] != [2]string{"limits", "derivatives"} {
+				t.Errorf("edges = %v, want one limits -> derivatives edge", graph.edges)
+			}
+			if graph.nodes[0].description != "Approaching values" {
+				t.Errorf("limits description = %q", graph.nodes[0].description)
+			}
+			if wantMin := 3*tt.repeats + 2*tt.transientFailures; graph.attempts < wantMin {
+				t.Errorf("attempts = %d, want at least %d (failures retried)", graph.attempts, wantMin)
+			}
+		})
+	}
+}
+
+func TestCreatePrerequisiteMissingConcept(t *testing.T) {
+	graph := &fakeGraph{transientFailures: 1}
+	c := &Client{logger: zap.NewNop(), openWriteSession: graph.session}
+
+	err := c.CreatePrerequisite(context.Background(), "limits", "derivatives")
+	if err == nil || !strings.Contains(err.Error(), ErrConceptNotFound.Error()) {
+		t.Fatalf("error = %v, want ErrConceptNotFound", err)
+	}
+	if len(graph.edges) != 0 {
+		t.Errorf("edges = %v, want none", graph.edges)
+	}
+}
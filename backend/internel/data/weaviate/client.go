@@ -2,14 +2,20 @@ package weaviate
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"mathprereq/internel/core/config"
 	"mathprereq/pkg/logger"
+	"mathprereq/pkg/metrics"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/google/uuid"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate/auth"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/filters"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate/graphql"
 	"github.com/weaviate/weaviate/entities/models"
 
@@ -19,7 +25,62 @@ import (
 type Client struct {
 	client *weaviate.Client
 	logger *zap.Logger
-	class  string
+	// class is the default class; classes holds every class the client may use
+	class   string
+	classes map[string]bool
+	// conceptClasses routes a lowercased concept to the class holding its chunks
+	conceptClasses map[string]string
+
+	batchConcurrency int
+	batchTimeout     time.Duration
+
+	// keywordFallback serves searches with BM25 when the vectorizer upstream fails
+	keywordFallback bool
+	// pending holds chunks rejected by the vectorizer, at most retryQueueSize of them
+	retryQueueSize int
+	pendingMu      sync.Mutex
+	pending        []pendingChunk
+	stopRetries    chan struct{}
+	closeOnce      sync.Once
+}
+
+// ErrUnknownClass is returned when a method is called with a class that is not configured
+var ErrUnknownClass = errors.New("unknown weaviate class")
+
+// startupCheckTimeout bounds a single startup health check and schema initialization
+const startupCheckTimeout = 15 * time.Second
+
+// ErrNeverHealthy is returned when Weaviate is still not ready after every startup attempt
+var ErrNeverHealthy = errors.New("weaviate never became healthy")
+
+// GraphQLError is returned when Weaviate answers a GraphQL query with errors in the
+// response body, e.g. for a property missing from the schema. Weaviate reports these
+// with a successful HTTP status, so they would otherwise read as empty results.
+type GraphQLError struct {
+	Messages []string
+}
+
+func (e *GraphQLError) Error() string {
+	return "weaviate graphql errors: " + strings.Join(e.Messages, "; ")
+}
+
+// graphQLError returns the errors in a GraphQL response body as a *GraphQLError, or nil
+func graphQLError(result *models.GraphQLResponse) error {
+	if result == nil || len(result.Errors) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(result.Errors))
+	for _, gqlErr := range result.Errors {
+		if gqlErr == nil {
+			continue
+		}
+		message := gqlErr.Message
+		if len(gqlErr.Path) > 0 {
+			message += " (path " + strings.Join(gqlErr.Path, ".") + ")"
+		}
+		messages = append(messages, message)
+	}
+	return &GraphQLError{Messages: messages}
 }
 
 type Source struct {
@@ -75,44 +136,182 @@ func NewClient(cfg config.WeaviateConfig) (*Client, error) {
 		className = "MathChunk" // Default fallback
 	}
 
+	classes := map[string]bool{className: true}
+	for _, name := range cfg.ClassNames {
+		if name != "" {
+			classes[name] = true
+		}
+	}
+	conceptClasses := make(map[string]string)
+	for name, concepts := range cfg.ConceptClasses {
+		if name == "" {
+			continue
+		}
+		classes[name] = true
+		for _, concept := range concepts {
+			conceptClasses[strings.ToLower(strings.TrimSpace(concept))] = name
+		}
+	}
+
 	client := &Client{
-		client: weaviateClient,
-		logger: logger,
-		class:  className,
+		client:           weaviateClient,
+		logger:           logger,
+		class:            className,
+		classes:          classes,
+		conceptClasses:   conceptClasses,
+		batchConcurrency: cfg.BatchSearchConcurrency,
+		batchTimeout:     cfg.BatchSearchTimeout,
+		keywordFallback:  cfg.KeywordFallback,
+		retryQueueSize:   cfg.IngestRetryQueueSize,
+		stopRetries:      make(chan struct{}),
+	}
+	if client.batchConcurrency <= 0 {
+		client.batchConcurrency = 4
+	}
+	if client.batchTimeout <= 0 {
+		client.batchTimeout = 10 * time.Second
 	}
 
-	// Test connection
-	if !client.IsHealthy(context.Background()) {
-		return nil, fmt.Errorf("weaviate is not healthy at %s://%s", cfg.Scheme, cfg.Host)
+	if err := client.waitUntilReady(context.Background(), cfg); err != nil {
+		return nil, err
 	}
 
-	// Initialize schema
-	if err := client.initSchema(context.Background()); err != nil {
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	if client.retryQueueSize > 0 {
+		interval := cfg.IngestRetryInterval
+		if interval <= 0 {
+			interval = time.Minute
+		}
+		go client.runIngestRetries(interval)
 	}
 
 	logger.Info("Weaviate client initialized successfully",
 		zap.String("host", cfg.Host),
-		zap.String("class", className))
+		zap.String("class", className),
+		zap.Int("classes", len(classes)),
+		zap.Bool("keyword_fallback", client.keywordFallback))
 
 	return client, nil
 }
 
-func (c *Client) initSchema(ctx context.Context) error {
+// waitUntilReady checks health and initializes the schema of every class, retrying up
+// to StartupAttempts times with a backoff from StartupInterval doubling to at most
+// StartupMaxInterval, so a Weaviate that boots alongside the app has time to come up
+func (c *Client) waitUntilReady(ctx context.Context, cfg config.WeaviateConfig) error {
+	attempts := cfg.StartupAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	delay := cfg.StartupInterval
+	if delay <= 0 {
+		delay = 2 * time.Second
+	}
+	maxDelay := cfg.StartupMaxInterval
+	if maxDelay < delay {
+		maxDelay = delay
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, startupCheckTimeout)
+		lastErr = c.initialize(attemptCtx)
+		cancel()
+		if lastErr == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		c.logger.Warn("Weaviate not ready, retrying",
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", attempts),
+			zap.Duration("retry_in", delay),
+			zap.Error(lastErr))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+
+	return fmt.Errorf("%w at %s://%s after %d attempts: %v", ErrNeverHealthy, cfg.Scheme, cfg.Host, attempts, lastErr)
+}
+
+// initialize checks the connection and creates any missing class schemas
+func (c *Client) initialize(ctx context.Context) error {
+	if !c.IsHealthy(ctx) {
+		return errors.New("health check failed")
+	}
+
+	for name := range c.classes {
+		if err := c.initSchema(ctx, name); err != nil {
+			return fmt.Errorf("failed to initialize schema for class %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// MissingClasses returns the configured classes that do not exist in Weaviate
+func (c *Client) MissingClasses(ctx context.Context) ([]string, error) {
+	var missing []string
+	for _, class := range c.Classes() {
+		exists, err := c.client.Schema().ClassExistenceChecker().WithClassName(class).Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check class %s: %w", class, err)
+		}
+		if !exists {
+			missing = append(missing, class)
+		}
+	}
+	return missing, nil
+}
+
+// resolveClass returns the default class for an empty name and rejects unconfigured classes
+func (c *Client) resolveClass(class string) (string, error) {
+	if class == "" {
+		return c.class, nil
+	}
+	if !c.classes[class] {
+		return "", fmt.Errorf("%w: %s", ErrUnknownClass, class)
+	}
+	return class, nil
+}
+
+// ClassForConcept returns the class holding a concept's chunks, or "" for the default class
+func (c *Client) ClassForConcept(concept string) string {
+	return c.conceptClasses[strings.ToLower(strings.TrimSpace(concept))]
+}
+
+// Classes returns the configured class names, default class first
+func (c *Client) Classes() []string {
+	names := []string{c.class}
+	for name := range c.classes {
+		if name != c.class {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (c *Client) initSchema(ctx context.Context, class string) error {
 	// Check if class already exists
-	exists, err := c.client.Schema().ClassExistenceChecker().WithClassName(c.class).Do(ctx)
+	exists, err := c.client.Schema().ClassExistenceChecker().WithClassName(class).Do(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check class existence: %w", err)
 	}
 
 	if exists {
-		c.logger.Info("Schema class already exists", zap.String("class", c.class))
+		c.logger.Info("Schema class already exists", zap.String("class", class))
 		return nil
 	}
 
 	// Create class schema
 	classObj := &models.Class{
-		Class:      c.class,
+		Class:      class,
 		Vectorizer: "text2vec-openai",
 		Properties: []*models.Property{
 			{
@@ -148,63 +347,114 @@ func (c *Client) initSchema(ctx context.Context) error {
 		return fmt.Errorf("failed to create class: %w", err)
 	}
 
-	c.logger.Info("Created schema class", zap.String("class", c.class))
+	c.logger.Info("Created schema class", zap.String("class", class))
 	return nil
 }
 
-func (c *Client) SemanticSearch(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+// SemanticSearch searches class, or the default class when class is empty. When the
+// vectorizer upstream fails and keyword fallback is enabled, it degrades to a BM25 search
+// whose results carry search_mode "keyword" and degraded true in their metadata.
+func (c *Client) SemanticSearch(ctx context.Context, class, query string, limit int) ([]SearchResult, error) {
+	class, err := c.resolveClass(class)
+	if err != nil {
+		return nil, err
+	}
+
 	c.logger.Info("Performing semantic search",
+		zap.String("class", class),
 		zap.String("query", query),
 		zap.Int("limit", limit))
 
+	searchResults, err := c.vectorSearch(ctx, class, query, limit)
+	if err == nil {
+		metrics.VectorSearches.WithLabelValues(metrics.SearchModeVector, "true").Inc()
+		c.logger.Info("Semantic search completed",
+			zap.Int("results", len(searchResults)))
+		return searchResults, nil
+	}
+	metrics.VectorSearches.WithLabelValues(metrics.SearchModeVector, "false").Inc()
+
+	if !errors.Is(err, ErrVectorizerUnavailable) {
+		return nil, fmt.Errorf("semantic search failed: %w", err)
+	}
+	metrics.VectorizerFailures.WithLabelValues("search").Inc()
+	if !c.keywordFallback {
+		return nil, fmt.Errorf("semantic search failed: %w", err)
+	}
+
+	c.logger.Warn("Vectorizer unavailable, falling back to keyword search",
+		zap.String("class", class),
+		zap.Error(err))
+
+	searchResults, kwErr := c.keywordSearch(ctx, class, query, limit)
+	if kwErr != nil {
+		metrics.VectorSearches.WithLabelValues(metrics.SearchModeKeyword, "false").Inc()
+		return nil, fmt.Errorf("semantic search failed: %w", errors.Join(err, kwErr))
+	}
+	metrics.VectorSearches.WithLabelValues(metrics.SearchModeKeyword, "true").Inc()
+
+	c.logger.Info("Keyword fallback search completed",
+		zap.Int("results", len(searchResults)))
+
+	return searchResults, nil
+}
+
+// vectorSearch runs a nearText search; vectorizer failures wrap ErrVectorizerUnavailable
+func (c *Client) vectorSearch(ctx context.Context, class, query string, limit int) ([]SearchResult, error) {
 	// Build the nearText argument
 	nearText := c.client.GraphQL().NearTextArgBuilder().
 		WithConcepts([]string{query})
 
-	// Build fields using the proper field builders
-	fields := []graphql.Field{
-		{Name: "content"},
-		{Name: "concept"},
-		{Name: "chapter"},
-		{
-			Name: "_additional",
-			Fields: []graphql.Field{
-				{Name: "certainty"},
-			},
-		},
-	}
-
 	// Build the GraphQL query
 	result, err := c.client.GraphQL().Get().
-		WithClassName(c.class).
-		WithFields(fields...).
+		WithClassName(class).
+		WithFields(searchFields("certainty")...).
 		WithNearText(nearText).
 		WithLimit(limit).
 		Do(ctx)
-
+	if err == nil {
+		err = graphQLError(result)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("semantic search failed: %w", err)
+		return nil, classifyError(err)
 	}
 
-	// Parse results
+	searchResults := parseSearchResults(result, class, func(additional map[string]interface{}) float32 {
+		certainty, _ := additional["certainty"].(float64)
+		return float32(certainty)
+	})
+	for i := range searchResults {
+		searchResults[i].Metadata["search_mode"] = metrics.SearchModeVector
+	}
+	return searchResults, nil
+}
+
+// parseSearchResults reads the objects of class from a Get response, scoring each with
+// scoreOf applied to its _additional fields
+func parseSearchResults(result *models.GraphQLResponse, class string, scoreOf func(map[string]interface{}) float32) []SearchResult {
 	var searchResults []SearchResult
 
 	if result.Data != nil {
 		if get, ok := result.Data["Get"].(map[string]interface{}); ok {
-			if classData, ok := get[c.class].([]interface{}); ok {
+			if classData, ok := get[class].([]interface{}); ok {
 				for _, item := range classData {
 					if obj, ok := item.(map[string]interface{}); ok {
 						searchResult := SearchResult{
 							Content: getStringField(obj, "content"),
-							Concept: getStringField(obj, "concept"),
+							Concept: strings.TrimSpace(getStringField(obj, "concept")),
 							Chapter: getStringField(obj, "chapter"),
+							Metadata: map[string]interface{}{
+								"chapter": getStringField(obj, "chapter"),
+								"source":  getStringField(obj, "source"),
+							},
+						}
+						if chunkIndex, ok := obj["chunkIndex"].(float64); ok {
+							searchResult.Metadata["chunk_index"] = int(chunkIndex)
 						}
 
-						// Extract certainty score from _additional
+						// Extract the score from _additional
 						if additional, ok := obj["_additional"].(map[string]interface{}); ok {
-							if certainty, ok := additional["certainty"].(float64); ok {
-								searchResult.Score = float32(certainty)
-							}
+							searchResult.Score = scoreOf(additional)
 						}
 
 						searchResults = append(searchResults, searchResult)
@@ -214,14 +464,20 @@ func (c *Client) SemanticSearch(ctx context.Context, query string, limit int) ([
 		}
 	}
 
-	c.logger.Info("Semantic search completed",
-		zap.Int("results", len(searchResults)))
-
-	return searchResults, nil
+	return searchResults
 }
 
-func (c *Client) AddContent(ctx context.Context, content []ContentChunk) error {
+// AddContent inserts content into class, or the default class when class is empty.
+// Chunks the vectorizer upstream rejects are queued for re-ingestion when the retry
+// queue is enabled, rather than dropped.
+func (c *Client) AddContent(ctx context.Context, class string, content []ContentChunk) error {
+	class, err := c.resolveClass(class)
+	if err != nil {
+		return err
+	}
+
 	c.logger.Info("Adding content to vector store",
+		zap.String("class", class),
 		zap.Int("chunks", len(content)))
 
 	if len(content) == 0 {
@@ -229,6 +485,31 @@ func (c *Client) AddContent(ctx context.Context, content []ContentChunk) error {
 		return nil
 	}
 
+	rejected, err := c.insertChunks(ctx, class, content)
+	if err != nil {
+		if !errors.Is(err, ErrVectorizerUnavailable) {
+			return fmt.Errorf("batch insert failed: %w", err)
+		}
+		metrics.VectorizerFailures.WithLabelValues("ingest").Inc()
+		if !c.queueForRetry(class, content) {
+			return fmt.Errorf("batch insert failed: %w", err)
+		}
+		return nil
+	}
+
+	if len(rejected) > 0 {
+		metrics.VectorizerFailures.WithLabelValues("ingest").Inc()
+		c.queueForRetry(class, rejected)
+	}
+
+	c.logger.Info("Successfully added content to vector store",
+		zap.Int("total_chunks", len(content)))
+	return nil
+}
+
+// insertChunks batch-inserts content into class and returns the chunks whose insert
+// failed in the vectorizer. A failure of the whole batch is returned as the error.
+func (c *Client) insertChunks(ctx context.Context, class string, content []ContentChunk) ([]ContentChunk, error) {
 	// Batch insert for better performance
 	batcher := c.client.Batch().ObjectsBatcher()
 
@@ -254,7 +535,7 @@ func (c *Client) AddContent(ctx context.Context, content []ContentChunk) error {
 		uuidValue := uuid.New().String()
 
 		obj := &models.Object{
-			Class:      c.class,
+			Class:      class,
 			ID:         strfmt.UUID(uuidValue),
 			Properties: properties,
 		}
@@ -265,31 +546,37 @@ func (c *Client) AddContent(ctx context.Context, content []ContentChunk) error {
 	// Execute batch
 	batchResult, err := batcher.Do(ctx)
 	if err != nil {
-		return fmt.Errorf("batch insert failed: %w", err)
-	}
-
-	// Check for errors in batch result
-	if batchResult != nil {
-		errorCount := 0
-		for i, result := range batchResult {
-			if result.Result.Errors != nil && len(result.Result.Errors.Error) > 0 {
-				errorCount++
-				c.logger.Warn("Error adding content chunk",
-					zap.Int("chunk_index", i),
-					zap.Any("errors", result.Result.Errors.Error))
+		return nil, classifyError(err)
+	}
+
+	// Check for errors in batch result; results are in insertion order
+	var rejected []ContentChunk
+	errorCount := 0
+	for i, result := range batchResult {
+		if result.Result == nil || result.Result.Errors == nil || len(result.Result.Errors.Error) == 0 {
+			continue
+		}
+		errorCount++
+		c.logger.Warn("Error adding content chunk",
+			zap.Int("chunk_index", i),
+			zap.Any("errors", result.Result.Errors.Error))
+
+		for _, itemErr := range result.Result.Errors.Error {
+			if itemErr != nil && isVectorizerMessage(itemErr.Message) && i < len(content) {
+				rejected = append(rejected, content[i])
+				break
 			}
 		}
+	}
 
-		if errorCount > 0 {
-			c.logger.Warn("Some content chunks failed to insert",
-				zap.Int("total_chunks", len(content)),
-				zap.Int("failed_chunks", errorCount))
-		}
+	if errorCount > 0 {
+		c.logger.Warn("Some content chunks failed to insert",
+			zap.Int("total_chunks", len(content)),
+			zap.Int("failed_chunks", errorCount),
+			zap.Int("vectorizer_failures", len(rejected)))
 	}
 
-	c.logger.Info("Successfully added content to vector store",
-		zap.Int("total_chunks", len(content)))
-	return nil
+	return rejected, nil
 }
 
 func (c *Client) IsHealthy(ctx context.Context) bool {
@@ -302,10 +589,40 @@ func (c *Client) IsHealthy(ctx context.Context) bool {
 	return result
 }
 
-func (c *Client) GetStats(ctx context.Context) (map[string]interface{}, error) {
-	// Get object count for the class
+// GetStats reports the object count of class, or the default class when class is empty
+func (c *Client) GetStats(ctx context.Context, class string) (map[string]interface{}, error) {
+	class, err := c.resolveClass(class)
+	if err != nil {
+		return nil, err
+	}
+
+	totalChunks, err := c.Count(ctx, class)
+	if err != nil {
+		c.logger.Warn("Failed to get Weaviate stats", zap.Error(err))
+		return map[string]interface{}{
+			"total_chunks": int64(0),
+			"status":       "unhealthy",
+			"error":        err.Error(),
+		}, err
+	}
+
+	return map[string]interface{}{
+		"total_chunks":   totalChunks,
+		"status":         "healthy",
+		"class":          class,
+		"pending_chunks": c.PendingContentCount(),
+	}, nil
+}
+
+// Count returns the number of objects stored in class, or the default class when class is empty
+func (c *Client) Count(ctx context.Context, class string) (int64, error) {
+	class, err := c.resolveClass(class)
+	if err != nil {
+		return 0, err
+	}
+
 	result, err := c.client.GraphQL().Aggregate().
-		WithClassName(c.class).
+		WithClassName(class).
 		WithFields(graphql.Field{
 			Name: "meta",
 			Fields: []graphql.Field{
@@ -313,20 +630,17 @@ func (c *Client) GetStats(ctx context.Context) (map[string]interface{}, error) {
 			},
 		}).
 		Do(ctx)
-
+	if err == nil {
+		err = graphQLError(result)
+	}
 	if err != nil {
-		c.logger.Warn("Failed to get Weaviate stats", zap.Error(err))
-		return map[string]interface{}{
-			"total_chunks": int64(0),
-			"status":       "unhealthy",
-			"error":        err.Error(),
-		}, err
+		return 0, fmt.Errorf("failed to aggregate objects: %w", err)
 	}
 
 	totalChunks := int64(0)
 	if result.Data != nil {
 		if aggregate, ok := result.Data["Aggregate"].(map[string]interface{}); ok {
-			if classData, ok := aggregate[c.class]; ok {
+			if classData, ok := aggregate[class]; ok {
 				if objects, ok := classData.([]interface{}); ok && len(objects) > 0 {
 					if objMap, ok := objects[0].(map[string]interface{}); ok {
 						if meta, exists := objMap["meta"]; exists {
@@ -344,25 +658,104 @@ func (c *Client) GetStats(ctx context.Context) (map[string]interface{}, error) {
 		}
 	}
 
-	return map[string]interface{}{
-		"total_chunks": totalChunks,
-		"status":       "healthy",
-		"class":        c.class,
-	}, nil
+	return totalChunks, nil
+}
+
+// conceptGroupLimit bounds the distinct concept values one concept's Equal filter may
+// match; the concept property is tokenized, so "limits" also matches "one-sided limits"
+const conceptGroupLimit = 100
+
+// CountByConcept counts the chunks in class (or the default class when empty) whose
+// concept value equals each of the given concepts, ignoring case. Keys are the stored
+// concept values lowercased; concepts without chunks are absent.
+func (c *Client) CountByConcept(ctx context.Context, class string, concepts []string) (map[string]int64, error) {
+	class, err := c.resolveClass(class)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(concepts))
+	counted := make(map[string]bool)
+	for _, concept := range concepts {
+		if concept == "" {
+			continue
+		}
+		groups, err := c.countConceptGroups(ctx, class, concept)
+		if err != nil {
+			return nil, err
+		}
+		// The filter matches on tokens, so keep only the values that are the concept
+		for value, count := range groups {
+			if strings.EqualFold(value, concept) && !counted[value] {
+				counted[value] = true
+				counts[strings.ToLower(value)] += count
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// countConceptGroups counts the chunks of each stored concept value matching concept
+// with an Equal filter
+func (c *Client) countConceptGroups(ctx context.Context, class, concept string) (map[string]int64, error) {
+	result, err := c.client.GraphQL().Aggregate().
+		WithClassName(class).
+		WithWhere(filters.Where().
+			WithPath([]string{"concept"}).
+			WithOperator(filters.Equal).
+			WithValueText(concept)).
+		WithGroupBy("concept").
+		WithLimit(conceptGroupLimit).
+		WithFields(
+			graphql.Field{Name: "groupedBy", Fields: []graphql.Field{{Name: "value"}}},
+			graphql.Field{Name: "meta", Fields: []graphql.Field{{Name: "count"}}},
+		).
+		Do(ctx)
+	if err == nil {
+		err = graphQLError(result)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate concept counts: %w", err)
+	}
+
+	counts := make(map[string]int64)
+	aggregate, _ := result.Data["Aggregate"].(map[string]interface{})
+	groups, _ := aggregate[class].([]interface{})
+	for _, group := range groups {
+		groupMap, ok := group.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		groupedBy, _ := groupMap["groupedBy"].(map[string]interface{})
+		meta, _ := groupMap["meta"].(map[string]interface{})
+		value, _ := groupedBy["value"].(string)
+		count, _ := meta["count"].(float64)
+		if value != "" {
+			counts[value] += int64(count)
+		}
+	}
+	return counts, nil
 }
 
-func (c *Client) DeleteAll(ctx context.Context) error {
-	c.logger.Info("Deleting all content from vector store")
+// DeleteAll drops and recreates class, or the default class when class is empty
+func (c *Client) DeleteAll(ctx context.Context, class string) error {
+	class, err := c.resolveClass(class)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("Deleting all content from vector store", zap.String("class", class))
 
 	// Delete the entire class
-	err := c.client.Schema().ClassDeleter().WithClassName(c.class).Do(ctx)
+	err = c.client.Schema().ClassDeleter().WithClassName(class).Do(ctx)
 	if err != nil {
 		c.logger.Error("Failed to delete class", zap.Error(err))
 		return fmt.Errorf("failed to delete class: %w", err)
 	}
 
 	// Recreate the schema
-	if err := c.initSchema(ctx); err != nil {
+	if err := c.initSchema(ctx, class); err != nil {
 		return fmt.Errorf("failed to recreate schema: %w", err)
 	}
 
@@ -370,14 +763,78 @@ func (c *Client) DeleteAll(ctx context.Context) error {
 	return nil
 }
 
-// Search method to match repository interface expectations
+// Search method to match repository interface expectations; a query naming a routed
+// concept searches that concept's class
 func (c *Client) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
-	return c.SemanticSearch(ctx, query, limit)
+	return c.SemanticSearch(ctx, c.ClassForConcept(query), query, limit)
+}
+
+// BatchSearch runs a semantic search for each query concurrently under a shared deadline.
+// results[i] holds the matches for queries[i], searched in the class its concept is
+// routed to; a query that fails gets an empty result.
+// An error is returned only when every query failed.
+func (c *Client) BatchSearch(ctx context.Context, queries []string, limit int) ([][]SearchResult, error) {
+	results := make([][]SearchResult, len(queries))
+	if len(queries) == 0 {
+		return results, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.batchTimeout)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []error
+		sem      = make(chan struct{}, c.batchConcurrency)
+	)
+
+	for i, query := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				mu.Lock()
+				failures = append(failures, ctx.Err())
+				mu.Unlock()
+				results[i] = []SearchResult{}
+				return
+			}
+
+			found, err := c.SemanticSearch(ctx, c.ClassForConcept(query), query, limit)
+			if err != nil {
+				c.logger.Warn("Batch search query failed",
+					zap.String("query", query),
+					zap.Error(err))
+				mu.Lock()
+				failures = append(failures, err)
+				mu.Unlock()
+				found = []SearchResult{}
+			}
+			results[i] = found
+		}()
+	}
+	wg.Wait()
+
+	if len(failures) == len(queries) {
+		return results, fmt.Errorf("all %d batch searches failed: %w", len(queries), errors.Join(failures...))
+	}
+
+	return results, nil
 }
 
 // Close method for graceful shutdown
 func (c *Client) Close() error {
-	// Weaviate client doesn't require explicit closing
+	// Weaviate client doesn't require explicit closing; stop re-ingesting pending chunks
+	c.closeOnce.Do(func() { close(c.stopRetries) })
+	if pending := c.PendingContentCount(); pending > 0 {
+		c.logger.Warn("Weaviate client closed with content chunks still pending re-ingestion",
+			zap.Int("pending", pending))
+	}
 	c.logger.Info("Weaviate client closed")
 	return nil
 }
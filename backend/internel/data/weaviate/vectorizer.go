@@ -0,0 +1,217 @@
+package weaviate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mathprereq/pkg/metrics"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/graphql"
+	"go.uber.org/zap"
+)
+
+// ErrVectorizerUnavailable is returned when the vectorizer upstream (e.g. OpenAI behind
+// text2vec-openai) failed to embed a query or object
+var ErrVectorizerUnavailable = errors.New("vectorizer unavailable")
+
+// vectorizerErrorMarkers are substrings of Weaviate errors raised by the vectorizer module
+var vectorizerErrorMarkers = []string{"vectoriz", "text2vec", "openai", "remote client"}
+
+// isVectorizerMessage reports whether a Weaviate error message comes from the vectorizer
+func isVectorizerMessage(message string) bool {
+	message = strings.ToLower(message)
+	for _, marker := range vectorizerErrorMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyError wraps err with ErrVectorizerUnavailable when it came from the vectorizer
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, ErrVectorizerUnavailable) || !isVectorizerMessage(err.Error()) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrVectorizerUnavailable, err)
+}
+
+// bm25HalfScore is the BM25 score mapped to 0.5 by keywordScore
+const bm25HalfScore = 5.0
+
+// keywordScore maps an unbounded BM25 score into [0, 1) on a fixed scale, so a weak
+// best hit stays weak instead of being stretched to 1
+func keywordScore(bm25 float32) float32 {
+	if bm25 <= 0 {
+		return 0
+	}
+	return bm25 / (bm25 + bm25HalfScore)
+}
+
+// keywordSearch runs a BM25 search over content and concept, which needs no vectorizer.
+// Scores are mapped into the certainty range by keywordScore.
+func (c *Client) keywordSearch(ctx context.Context, class, query string, limit int) ([]SearchResult, error) {
+	bm25 := c.client.GraphQL().Bm25ArgBuilder().
+		WithQuery(query).
+		WithProperties("content", "concept")
+
+	result, err := c.client.GraphQL().Get().
+		WithClassName(class).
+		WithFields(searchFields("score")...).
+		WithBM25(bm25).
+		WithLimit(limit).
+		Do(ctx)
+	if err == nil {
+		err = graphQLError(result)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("keyword search failed: %w", err)
+	}
+
+	searchResults := parseSearchResults(result, class, func(additional map[string]interface{}) float32 {
+		// BM25 scores come back as strings
+		raw, _ := additional["score"].(string)
+		score, _ := strconv.ParseFloat(raw, 32)
+		return float32(score)
+	})
+
+	for i := range searchResults {
+		searchResults[i].Metadata["bm25_score"] = searchResults[i].Score
+		searchResults[i].Metadata["search_mode"] = metrics.SearchModeKeyword
+		searchResults[i].Metadata["degraded"] = true
+		searchResults[i].Score = keywordScore(searchResults[i].Score)
+	}
+	return searchResults, nil
+}
+
+// pendingChunk is a chunk the vectorizer rejected, waiting to be re-ingested into class
+type pendingChunk struct {
+	class string
+	chunk ContentChunk
+}
+
+// queueForRetry keeps chunks rejected by the vectorizer for a later RetryPendingContent,
+// dropping the oldest once the queue is full. It reports whether queueing is enabled.
+func (c *Client) queueForRetry(class string, chunks []ContentChunk) bool {
+	if c.retryQueueSize <= 0 {
+		return false
+	}
+
+	c.pendingMu.Lock()
+	for _, chunk := range chunks {
+		c.pending = append(c.pending, pendingChunk{class: class, chunk: chunk})
+	}
+	dropped := 0
+	if overflow := len(c.pending) - c.retryQueueSize; overflow > 0 {
+		dropped = overflow
+		c.pending = append([]pendingChunk(nil), c.pending[overflow:]...)
+	}
+	queued := len(c.pending)
+	c.pendingMu.Unlock()
+
+	metrics.PendingIngestChunks.Set(float64(queued))
+	c.logger.Warn("Queued content chunks for re-ingestion after vectorizer failure",
+		zap.String("class", class),
+		zap.Int("chunks", len(chunks)),
+		zap.Int("pending", queued),
+		zap.Int("dropped_oldest", dropped))
+	return true
+}
+
+// PendingContentCount returns the number of chunks waiting to be re-ingested
+func (c *Client) PendingContentCount() int {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	return len(c.pending)
+}
+
+// RetryPendingContent re-ingests the queued chunks, returning how many were stored.
+// Chunks the vectorizer rejects again go back on the queue.
+func (c *Client) RetryPendingContent(ctx context.Context) (int, error) {
+	c.pendingMu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.pendingMu.Unlock()
+	metrics.PendingIngestChunks.Set(0)
+
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	byClass := make(map[string][]ContentChunk)
+	var classes []string
+	for _, p := range pending {
+		if _, ok := byClass[p.class]; !ok {
+			classes = append(classes, p.class)
+		}
+		byClass[p.class] = append(byClass[p.class], p.chunk)
+	}
+
+	stored := 0
+	var errs []error
+	for _, class := range classes {
+		chunks := byClass[class]
+		rejected, err := c.insertChunks(ctx, class, chunks)
+		if err != nil {
+			if !errors.Is(err, ErrVectorizerUnavailable) {
+				errs = append(errs, fmt.Errorf("class %s: %w", class, err))
+			}
+			// Keep the chunks whatever the failure; they were already accepted once
+			c.queueForRetry(class, chunks)
+			continue
+		}
+		if len(rejected) > 0 {
+			c.queueForRetry(class, rejected)
+		}
+		stored += len(chunks) - len(rejected)
+	}
+
+	c.logger.Info("Retried pending content chunks",
+		zap.Int("pending", len(pending)),
+		zap.Int("stored", stored),
+		zap.Int("still_pending", c.PendingContentCount()))
+
+	return stored, errors.Join(errs...)
+}
+
+// runIngestRetries calls RetryPendingContent every interval until Close
+func (c *Client) runIngestRetries(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopRetries:
+			return
+		case <-ticker.C:
+			if c.PendingContentCount() == 0 {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if _, err := c.RetryPendingContent(ctx); err != nil {
+				c.logger.Warn("Failed to re-ingest pending content", zap.Error(err))
+			}
+			cancel()
+		}
+	}
+}
+
+// searchFields are the fields returned by searches, with scoreField from _additional
+func searchFields(scoreField string) []graphql.Field {
+	return []graphql.Field{
+		{Name: "content"},
+		{Name: "concept"},
+		{Name: "chapter"},
+		{Name: "source"},
+		{Name: "chunkIndex"},
+		{
+			Name: "_additional",
+			Fields: []graphql.Field{
+				{Name: scoreField},
+			},
+		},
+	}
+}
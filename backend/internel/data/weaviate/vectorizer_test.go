@@ -0,0 +1,59 @@
+package weaviate
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestKeywordScore(t *testing.T) {
+	tests := []struct {
+		bm25 float32
+		want float32
+	}{
+		{0, 0},
+		{-1, 0},
+		{bm25HalfScore, 0.5},
+		{15, 0.75},
+	}
+	for _, tt := range tests {
+		if got := keywordScore(tt.bm25); got != tt.want {
+			t.Errorf("keywordScore(%v) = %v, want %v", tt.bm25, got, tt.want)
+		}
+	}
+
+	// A weak best hit must not be stretched to a perfect score
+	if got := keywordScore(0.4); got >= 0.1 {
+		t.Errorf("keywordScore(0.4) = %v, want a weak score", got)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	gqlErr := &GraphQLError{Messages: []string{"text2vec-openai: rate limited"}}
+
+	tests := []struct {
+		name           string
+		err            error
+		wantVectorizer bool
+	}{
+		{"vectorizer graphql error", gqlErr, true},
+		{"vectorizer message", errors.New("remote client vectorize: 500"), true},
+		{"unrelated error", errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(tt.err)
+			if got := errors.Is(err, ErrVectorizerUnavailable); got != tt.wantVectorizer {
+				t.Errorf("vectorizer = %v, want %v", got, tt.wantVectorizer)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("classified error %v lost the original", err)
+			}
+		})
+	}
+
+	var target *GraphQLError
+	if !errors.As(classifyError(fmt.Errorf("search failed: %w", gqlErr)), &target) {
+		t.Error("classifyError dropped the *GraphQLError")
+	}
+}
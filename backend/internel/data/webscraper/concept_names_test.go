@@ -0,0 +1,54 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestFilterConceptNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []string
+		wantValid   []string
+		wantSkipped []string
+	}{
+		{
+			name:      "valid names are trimmed and kept in order",
+			input:     []string{" Limits ", "Chain Rule"},
+			wantValid: []string{"Limits", "Chain Rule"},
+		},
+		{
+			name:        "blank names",
+			input:       []string{"", "   ", "Series"},
+			wantValid:   []string{"Series"},
+			wantSkipped: []string{"", "   "},
+		},
+		{
+			name:        "names without letters or digits",
+			input:       []string{"???", "--", "Integrals"},
+			wantValid:   []string{"Integrals"},
+			wantSkipped: []string{"???", "--"},
+		},
+		{
+			name:        "duplicates after normalization",
+			input:       []string{"Chain Rule", "chain-rule", "CHAIN RULE!"},
+			wantValid:   []string{"Chain Rule"},
+			wantSkipped: []string{"chain-rule", "CHAIN RULE!"},
+		},
+	}
+
+	s := &EducationalWebScraper{logger: zap.NewNop()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, skipped := s.FilterConceptNames(tt.input)
+			if !reflect.DeepEqual(valid, tt.wantValid) {
+				t.Errorf("valid = %q, want %q", valid, tt.wantValid)
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %q, want %q", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package scraper
+
+import "testing"
+
+func TestAssessDifficulty(t *testing.T) {
+	tests := []struct {
+		name        string
+		title       string
+		description string
+		prior       string
+		want        string
+	}{
+		{"beginner keywords win", "Intro to Limits", "a simple first look", "advanced", "beginner"},
+		{"advanced keywords win", "Rigorous Proof of the Chain Rule", "", "beginner", "advanced"},
+		{"description counts", "Limits", "graduate level theorem", "intermediate", "advanced"},
+		{"case-insensitive", "BASIC DERIVATIVES", "", "intermediate", "beginner"},
+		{"no keywords keeps the prior", "Limits", "", "beginner", "beginner"},
+		{"tie keeps the prior", "Basic Proof", "", "intermediate", "intermediate"},
+	}
+
+	s := &EducationalWebScraper{config: ScraperConfig{
+		BeginnerKeywords: DefaultBeginnerKeywords,
+		AdvancedKeywords: DefaultAdvancedKeywords,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.assessDifficulty(tt.title, tt.description, tt.prior); got != tt.want {
+				t.Errorf("assessDifficulty(%q, %q, %q) = %q, want %q", tt.title, tt.description, tt.prior, got, tt.want)
+			}
+		})
+	}
+}
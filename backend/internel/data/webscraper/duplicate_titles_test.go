@@ -0,0 +1,88 @@
+package scraper
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestNormalizeTitleForComparison(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"The Chain Rule Explained", "chain rule explained"},
+		{"Chain Rule: Explained!", "chain rule explained"},
+		{"An Intro to a Limit", "intro to limit"},
+		{"Theorem 2.1", "theorem 2 1"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := normalizeTitleForComparison(tt.in); got != tt.want {
+				t.Errorf("normalizeTitleForComparison(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropSimilarTitles(t *testing.T) {
+	video := func(conceptID, title, url string, score float64) EducationalResource {
+		return EducationalResource{ConceptID: conceptID, ResourceType: "video", Title: title, URL: url, QualityScore: score}
+	}
+
+	tests := []struct {
+		name      string
+		resources []EducationalResource
+		wantURLs  []string
+	}{
+		{
+			name: "leading article does not hide a duplicate",
+			resources: []EducationalResource{
+				video("chain_rule", "The Chain Rule Explained", "https://a.org", 0.6),
+				video("chain_rule", "Chain Rule Explained", "https://b.org", 0.5),
+			},
+			wantURLs: []string{"https://a.org"},
+		},
+		{
+			name: "higher score replaces the earlier duplicate",
+			resources: []EducationalResource{
+				video("chain_rule", "Chain Rule Explained", "https://a.org", 0.5),
+				video("chain_rule", "The Chain Rule, Explained", "https://b.org", 0.9),
+			},
+			wantURLs: []string{"https://b.org"},
+		},
+		{
+			name: "different concepts are kept",
+			resources: []EducationalResource{
+				video("chain_rule", "Chain Rule Explained", "https://a.org", 0.5),
+				video("derivatives", "Chain Rule Explained", "https://b.org", 0.5),
+			},
+			wantURLs: []string{"https://a.org", "https://b.org"},
+		},
+		{
+			name: "dissimilar titles are kept",
+			resources: []EducationalResource{
+				video("chain_rule", "Chain Rule Explained", "https://a.org", 0.5),
+				video("chain_rule", "Chain Rule Practice Problems", "https://b.org", 0.5),
+			},
+			wantURLs: []string{"https://a.org", "https://b.org"},
+		},
+	}
+
+	s := &EducationalWebScraper{config: ScraperConfig{DuplicateTitleThreshold: 0.8}, logger: zap.NewNop()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []string
+			for _, resource := range s.dropSimilarTitles(context.Background(), tt.resources) {
+				urls = append(urls, resource.URL)
+			}
+			if !reflect.DeepEqual(urls, tt.wantURLs) {
+				t.Errorf("kept %v, want %v", urls, tt.wantURLs)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package scraper
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// Supported export formats
+const (
+	ExportFormatJSON = "json" // newline-delimited JSON
+	ExportFormatCSV  = "csv"
+)
+
+// ErrUnsupportedExportFormat is returned for export formats other than json and csv
+var ErrUnsupportedExportFormat = errors.New("unsupported export format")
+
+// ResourceQueryFilter narrows a resource query; zero values match everything
+type ResourceQueryFilter struct {
+	ConceptID       string
+	SourceDomain    string
+	ResourceType    string
+	DifficultyLevel string
+	MinQuality      *float64
+	IsVerified      *bool
+}
+
+// toBSON builds the MongoDB filter document for the set fields
+func (f ResourceQueryFilter) toBSON() bson.M {
+	filter := bson.M{}
+	if f.ConceptID != "" {
+		filter["concept_id"] = f.ConceptID
+	}
+	if f.SourceDomain != "" {
+		filter["source_domain"] = f.SourceDomain
+	}
+	if f.ResourceType != "" {
+		filter["resource_type"] = f.ResourceType
+	}
+	if f.DifficultyLevel != "" {
+		filter["difficulty_level"] = f.DifficultyLevel
+	}
+	if f.MinQuality != nil {
+		filter["quality_score"] = bson.M{"$gte": *f.MinQuality}
+	}
+	if f.IsVerified != nil {
+		filter["is_verified"] = *f.IsVerified
+	}
+	return filter
+}
+
+// resourceCSVColumns is the stable column order of CSV exports
+var resourceCSVColumns = []string{
+	"id", "concept_id", "concept_name", "title", "url", "description",
+	"resource_type", "source_domain", "difficulty_level", "quality_score",
+	"language", "duration", "thumbnail_url", "view_count", "rating",
+	"author_channel", "published_at", "tags", "is_verified", "scraped_at",
+}
+
+// ExportResources streams resources matching filter to w as newline-delimited JSON or CSV.
+// Documents are read from a cursor one at a time, so memory use does not grow with the
+// dataset size.
+func (s *EducationalWebScraper) ExportResources(ctx context.Context, filter ResourceQueryFilter, w io.Writer, format string) error {
+	var writeResource func(resource *EducationalResource) error
+	var flush func() error
+
+	switch format {
+	case ExportFormatJSON:
+		encoder := json.NewEncoder(w)
+		writeResource = func(resource *EducationalResource) error {
+			return encoder.Encode(resource)
+		}
+		flush = func() error { return nil }
+	case ExportFormatCSV:
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(resourceCSVColumns); err != nil {
+			return fmt.Errorf("failed to write csv header: %w", err)
+		}
+		writeResource = func(resource *EducationalResource) error {
+			return csvWriter.Write(resourceCSVRecord(resource))
+		}
+		flush = func() error {
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedExportFormat, format)
+	}
+
+	// Sort by _id so repeated exports list resources in the same order
+	opts := options.Find().SetSort(bson.D{{"_id", 1}})
+	cursor, err := s.collection.Find(ctx, filter.toBSON(), opts)
+	if err != nil {
+		return fmt.Errorf("failed to query resources: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	exported := 0
+	for cursor.Next(ctx) {
+		var resource EducationalResource
+		if err := cursor.Decode(&resource); err != nil {
+			return fmt.Errorf("failed to decode resource: %w", err)
+		}
+		if err := writeResource(&resource); err != nil {
+			return fmt.Errorf("failed to write resource: %w", err)
+		}
+		exported++
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("resource cursor failed: %w", err)
+	}
+	if err := flush(); err != nil {
+		return fmt.Errorf("failed to flush export: %w", err)
+	}
+
+	s.logger.Info("Exported resources",
+		zap.String("format", format),
+		zap.Int("count", exported))
+
+	return nil
+}
+
+// resourceCSVRecord renders a resource in resourceCSVColumns order
+func resourceCSVRecord(r *EducationalResource) []string {
+	optionalString := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+
+	var viewCount, rating, publishedAt string
+	if r.ViewCount != nil {
+		viewCount = strconv.FormatInt(*r.ViewCount, 10)
+	}
+	if r.Rating != nil {
+		rating = strconv.FormatFloat(*r.Rating, 'f', -1, 64)
+	}
+	if r.PublishedAt != nil {
+		publishedAt = r.PublishedAt.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		r.ID.Hex(),
+		r.ConceptID,
+		r.ConceptName,
+		r.Title,
+		r.URL,
+		r.Description,
+		r.ResourceType,
+		r.SourceDomain,
+		r.DifficultyLevel,
+		strconv.FormatFloat(r.QualityScore, 'f', -1, 64),
+		r.Language,
+		optionalString(r.Duration),
+		optionalString(r.ThumbnailURL),
+		viewCount,
+		rating,
+		optionalString(r.AuthorChannel),
+		publishedAt,
+		strings.Join(r.Tags, "|"),
+		strconv.FormatBool(r.IsVerified),
+		r.ScrapedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,309 @@
+package scraper
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"go.uber.org/zap"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// maxFeedEntries caps the resources taken from a single feed
+	maxFeedEntries = 20
+	// maxFeedBytes caps the size of a downloaded feed
+	maxFeedBytes = 5 << 20
+)
+
+// feedDateLayouts are the publish date formats seen in RSS and Atom feeds
+var feedDateLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	"2006-01-02",
+}
+
+// rssDocument covers RSS 2.0 (items under channel) and RSS 1.0/RDF (items at the root)
+type rssDocument struct {
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Author      string `xml:"author"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+type atomDocument struct {
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+// feedEntry is a feed item normalized across RSS and Atom
+type feedEntry struct {
+	title       string
+	link        string
+	description string
+	author      string
+	published   string
+}
+
+// ScrapeFeed fetches an RSS or Atom feed and maps its entries to resources for a concept.
+// Entries without a title or link are skipped; relative links resolve against the feed URL.
+func (s *EducationalWebScraper) ScrapeFeed(ctx context.Context, feedURL, conceptID, conceptName string) ([]EducationalResource, error) {
+	if !s.allowedByRobots(ctx, feedURL) {
+		return nil, nil
+	}
+
+	if err := s.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := s.waitForDomain(ctx, feedURL); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Fetching feed", zap.String("concept", conceptName), zap.String("feed", feedURL))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", s.config.UserAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+
+	resp, err := s.doWithRetry(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed %s returned status %d", feedURL, resp.StatusCode)
+	}
+
+	entries, err := parseFeed(io.LimitReader(resp.Body, maxFeedBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse feed %s: %w", feedURL, err)
+	}
+
+	base, err := url.Parse(feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feed URL %s: %w", feedURL, err)
+	}
+
+	var resources []EducationalResource
+	for _, entry := range entries {
+		if len(resources) >= maxFeedEntries {
+			break
+		}
+
+		title := cleanText(htmlToText(entry.title))
+		ref, err := url.Parse(strings.TrimSpace(entry.link))
+		if title == "" || err != nil || ref.String() == "" {
+			continue
+		}
+		parsed := base.ResolveReference(ref)
+		if parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			continue
+		}
+		link := parsed.String()
+
+		description := s.truncateString(cleanText(htmlToText(entry.description)), 300)
+
+		resource := EducationalResource{
+			ConceptID:       conceptID,
+			ConceptName:     conceptName,
+			Title:           title,
+			URL:             link,
+			Description:     description,
+			ResourceType:    "article",
+			SourceDomain:    strings.TrimPrefix(parsed.Host, "www."),
+			DifficultyLevel: s.assessDifficulty(title, description, "intermediate"),
+			QualityScore:    0.75,
+			ContentPreview:  s.truncateString(description, 150),
+			ScrapedAt:       time.Now(),
+			Language:        "en",
+			PublishedAt:     parseFeedDate(entry.published),
+			Tags:            []string{"feed", "article"},
+		}
+		if author := cleanText(entry.author); author != "" {
+			resource.AuthorChannel = &author
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
+// searchFeeds scrapes the feeds configured for a concept, matched by concept ID or by
+// lowercased name. A failing feed is logged and skipped.
+func (s *EducationalWebScraper) searchFeeds(ctx context.Context, conceptID, conceptName string) ([]EducationalResource, error) {
+	var resources []EducationalResource
+	for _, feedURL := range s.feedsForConcept(conceptID, conceptName) {
+		found, err := s.ScrapeFeed(ctx, feedURL, conceptID, conceptName)
+		if err != nil {
+			if ctx.Err() != nil {
+				return resources, ctx.Err()
+			}
+			s.logger.Warn("Feed scrape failed", zap.String("feed", feedURL), zap.Error(err))
+			continue
+		}
+		resources = append(resources, found...)
+	}
+	return resources, nil
+}
+
+// feedsForConcept returns the configured feed URLs for a concept
+func (s *EducationalWebScraper) feedsForConcept(conceptID, conceptName string) []string {
+	if feeds, ok := s.config.ConceptFeeds[conceptID]; ok {
+		return feeds
+	}
+	return s.config.ConceptFeeds[strings.ToLower(strings.TrimSpace(conceptName))]
+}
+
+// parseFeed decodes an RSS 2.0, RSS 1.0 or Atom document, detected by its root element
+func parseFeed(r io.Reader) ([]feedEntry, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(start.Name.Local) {
+		case "rss", "rdf":
+			var doc rssDocument
+			if err := decoder.DecodeElement(&doc, &start); err != nil {
+				return nil, err
+			}
+			items := append(doc.Channel.Items, doc.Items...)
+			entries := make([]feedEntry, 0, len(items))
+			for _, item := range items {
+				entry := feedEntry{
+					title:       item.Title,
+					link:        item.Link,
+					description: firstNonEmpty(item.Description, item.Content),
+					author:      firstNonEmpty(item.Creator, item.Author),
+					published:   firstNonEmpty(item.PubDate, item.Date),
+				}
+				entries = append(entries, entry)
+			}
+			return entries, nil
+		case "feed":
+			var doc atomDocument
+			if err := decoder.DecodeElement(&doc, &start); err != nil {
+				return nil, err
+			}
+			entries := make([]feedEntry, 0, len(doc.Entries))
+			for _, item := range doc.Entries {
+				entry := feedEntry{
+					title:       item.Title,
+					link:        atomEntryLink(item.Links),
+					description: firstNonEmpty(item.Summary, item.Content),
+					published:   firstNonEmpty(item.Published, item.Updated),
+				}
+				if len(item.Authors) > 0 {
+					entry.author = item.Authors[0].Name
+				}
+				entries = append(entries, entry)
+			}
+			return entries, nil
+		default:
+			return nil, fmt.Errorf("unsupported feed format %q", start.Name.Local)
+		}
+	}
+}
+
+// atomEntryLink prefers the entry's alternate link, which points at the content itself
+func atomEntryLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+// parseFeedDate parses a feed publish date, returning nil when it is missing or unrecognized
+func parseFeedDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range feedDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			parsed = parsed.UTC()
+			return &parsed
+		}
+	}
+	return nil
+}
+
+// htmlToText strips markup from feed fields, which frequently carry HTML
+func htmlToText(value string) string {
+	if !strings.Contains(value, "<") {
+		return value
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(value))
+	if err != nil {
+		return value
+	}
+	return doc.Text()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package scraper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxRetryAfter caps the wait a 429 or 503 Retry-After header may ask for
+const maxRetryAfter = 30 * time.Second
+
+// isRetryableStatus reports whether a response status may succeed on a later attempt
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// doWithRetry sends a GET request, retrying network errors, 429s and 5xx responses up
+// to MaxRetries times. The wait starts at RetryDelay and doubles, or follows the
+// server's Retry-After hint when longer, and never outlasts ctx. Other responses,
+// including 4xx, are returned to the caller as is; the last retryable response is
+// returned once the retries are used up.
+func (s *EducationalWebScraper) doWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
+	backoff := s.config.RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		resp, err := s.httpClient.Do(req.Clone(ctx))
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+		if attempt >= s.config.MaxRetries {
+			return resp, err
+		}
+
+		wait := backoff
+		fields := []zap.Field{
+			zap.String("url", req.URL.String()),
+			zap.Int("attempt", attempt+1),
+		}
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		} else {
+			if hint, ok := retryAfter(resp); ok && hint > wait {
+				wait = hint
+			}
+			fields = append(fields, zap.Int("status", resp.StatusCode))
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+		s.logger.Warn("Fetch failed, retrying", append(fields, zap.Duration("retry_in", wait))...)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		if err := s.waitForDomain(ctx, req.URL.String()); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		wait = time.Until(at)
+	} else {
+		return 0, false
+	}
+
+	if wait <= 0 {
+		return 0, false
+	}
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait, true
+}
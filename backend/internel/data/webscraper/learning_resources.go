@@ -0,0 +1,75 @@
+package scraper
+
+import (
+	"context"
+	"math"
+	"mathprereq/internel/domain/entities"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LearningResourceStore persists resources in the domain's LearningResource model;
+// repositories.ResourceRepository satisfies it.
+//
+// EducationalResource remains the system of record: it carries the scrape-specific
+// fields (reports, verification, video statistics) that ranking and curation need.
+// LearningResource is the domain projection of it, written through the repository
+// after every successful store so domain consumers never read the scraper's collection.
+type LearningResourceStore interface {
+	SaveBatch(ctx context.Context, resources []*entities.LearningResource) error
+}
+
+// SetLearningResourceStore mirrors every stored resource into store as a LearningResource
+func (s *EducationalWebScraper) SetLearningResourceStore(store LearningResourceStore) {
+	s.learningResources = store
+}
+
+// toLearningResource projects a scraped resource onto the domain model. The ID is new;
+// the repository keeps the first ID stored for a (concept, URL) pair.
+func toLearningResource(resource EducationalResource) *entities.LearningResource {
+	learning := entities.NewLearningResource(resource.ConceptID, resource.Title, resource.URL, resource.ResourceType)
+	learning.Difficulty = resource.DifficultyLevel
+	learning.Quality = resource.QualityScore
+	learning.Source = resource.SourceDomain
+	learning.Description = resource.Description
+	if len(resource.Tags) > 0 {
+		learning.Tags = append([]string(nil), resource.Tags...)
+	}
+	if !resource.ScrapedAt.IsZero() {
+		learning.CreatedAt = resource.ScrapedAt
+		learning.UpdatedAt = resource.ScrapedAt
+	}
+	if resource.Duration != nil {
+		if length := parseVideoDuration(*resource.Duration); length > 0 {
+			learning.Duration = int(math.Ceil(length.Minutes()))
+		}
+	}
+	return learning
+}
+
+// mirrorLearningResources writes resources through the LearningResourceStore, if set.
+// A failure is only logged: the scraper's own collection already holds the resources.
+func (s *EducationalWebScraper) mirrorLearningResources(ctx context.Context, resources []EducationalResource) {
+	if s.learningResources == nil || len(resources) == 0 {
+		return
+	}
+
+	learning := make([]*entities.LearningResource, len(resources))
+	for i, resource := range resources {
+		learning[i] = toLearningResource(resource)
+		learning[i].URL = canonicalizeURL(resource.URL)
+	}
+
+	start := time.Now()
+	if err := s.learningResources.SaveBatch(ctx, learning); err != nil {
+		s.logger.Warn("Failed to mirror resources into the learning resource repository",
+			zap.Int("resources", len(learning)),
+			zap.Error(err))
+		return
+	}
+
+	s.log(ctx).Debug("Mirrored resources into the learning resource repository",
+		zap.Int("resources", len(learning)),
+		zap.Duration("duration", time.Since(start)))
+}
@@ -0,0 +1,92 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// backfillSampleSize is how many concept ID changes are logged for verification
+const backfillSampleSize = 10
+
+// BackfillConceptIDs recomputes the canonical concept ID of every stored resource from
+// its concept_name and rewrites the ones that differ, returning how many were updated.
+// A resource whose URL is already stored under the canonical ID is a duplicate and is
+// removed instead. Names that cannot be resolved are skipped, so re-running is safe.
+func (s *EducationalWebScraper) BackfillConceptIDs(ctx context.Context) (int64, error) {
+	names, err := s.collection.Distinct(ctx, "concept_name", bson.M{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list concept names: %w", err)
+	}
+
+	var updated, removed int64
+	sampled := 0
+
+	for _, raw := range names {
+		conceptName, ok := raw.(string)
+		if !ok || conceptName == "" {
+			continue
+		}
+
+		canonicalID, err := s.resolveConceptID(ctx, conceptName)
+		if err != nil {
+			s.logger.Warn("Skipping concept during backfill, resolution failed",
+				zap.String("concept", conceptName),
+				zap.Error(err))
+			continue
+		}
+
+		cursor, err := s.collection.Find(ctx, bson.M{
+			"concept_name": conceptName,
+			"concept_id":   bson.M{"$ne": canonicalID},
+		})
+		if err != nil {
+			return updated, fmt.Errorf("failed to find resources for %q: %w", conceptName, err)
+		}
+
+		var stale []struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			ConceptID string             `bson:"concept_id"`
+			URL       string             `bson:"url"`
+		}
+		err = cursor.All(ctx, &stale)
+		if err != nil {
+			return updated, fmt.Errorf("failed to decode resources for %q: %w", conceptName, err)
+		}
+
+		for _, doc := range stale {
+			_, err := s.collection.UpdateByID(ctx, doc.ID, bson.M{"$set": bson.M{"concept_id": canonicalID}})
+			if mongo.IsDuplicateKeyError(err) {
+				if _, err := s.collection.DeleteOne(ctx, bson.M{"_id": doc.ID}); err != nil {
+					return updated, fmt.Errorf("failed to remove duplicate resource %s: %w", doc.URL, err)
+				}
+				removed++
+				continue
+			}
+			if err != nil {
+				return updated, fmt.Errorf("failed to update resource %s: %w", doc.URL, err)
+			}
+
+			updated++
+			if sampled < backfillSampleSize {
+				sampled++
+				s.logger.Info("Backfilled concept ID",
+					zap.String("concept", conceptName),
+					zap.String("url", doc.URL),
+					zap.String("old_concept_id", doc.ConceptID),
+					zap.String("new_concept_id", canonicalID))
+			}
+		}
+	}
+
+	s.logger.Info("Concept ID backfill completed",
+		zap.Int("concept_names", len(names)),
+		zap.Int64("updated", updated),
+		zap.Int64("duplicates_removed", removed))
+
+	return updated, nil
+}
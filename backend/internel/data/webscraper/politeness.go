@@ -0,0 +1,175 @@
+package scraper
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultCrawlDelay is the minimum time between requests to the same domain when no
+// delay is configured for it
+const DefaultCrawlDelay = time.Second
+
+const (
+	robotsFetchTimeout = 10 * time.Second
+	// maxRobotsCrawlDelay caps a robots.txt Crawl-delay so one site cannot stall a scrape
+	maxRobotsCrawlDelay = time.Minute
+	maxRobotsBodyBytes  = 512 * 1024
+)
+
+// domainLimiter spaces requests to the same domain at least its crawl delay apart,
+// independently of requests to other domains
+type domainLimiter struct {
+	delays       map[string]time.Duration
+	defaultDelay time.Duration
+	// robotsDelay looks up a host's robots.txt Crawl-delay; nil when robots.txt is not consulted
+	robotsDelay func(ctx context.Context, scheme, host string) (time.Duration, bool)
+
+	mu    sync.Mutex
+	hosts map[string]*hostPacing
+}
+
+// hostPacing is the next free request slot of one host
+type hostPacing struct {
+	once  sync.Once
+	delay time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newDomainLimiter(delays map[string]time.Duration, defaultDelay time.Duration) *domainLimiter {
+	normalized := make(map[string]time.Duration, len(delays))
+	for domain, delay := range delays {
+		normalized[normalizeHost(domain)] = delay
+	}
+	return &domainLimiter{
+		delays:       normalized,
+		defaultDelay: defaultDelay,
+		hosts:        make(map[string]*hostPacing),
+	}
+}
+
+// Wait blocks until a request to rawURL's host may be sent, reserving the slot after it
+func (l *domainLimiter) Wait(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return nil
+	}
+	host := normalizeHost(parsed.Hostname())
+
+	l.mu.Lock()
+	pacing, ok := l.hosts[host]
+	if !ok {
+		pacing = &hostPacing{}
+		l.hosts[host] = pacing
+	}
+	l.mu.Unlock()
+
+	pacing.once.Do(func() {
+		pacing.delay = l.delayFor(ctx, parsed.Scheme, parsed.Host, host)
+	})
+
+	pacing.mu.Lock()
+	start := time.Now()
+	if pacing.next.After(start) {
+		start = pacing.next
+	}
+	pacing.next = start.Add(pacing.delay)
+	pacing.mu.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Backoff holds off further requests to rawURL's host for at least d, e.g. when an API
+// asks its clients to back off
+func (l *domainLimiter) Backoff(rawURL string, d time.Duration) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || d <= 0 {
+		return
+	}
+	host := normalizeHost(parsed.Hostname())
+
+	l.mu.Lock()
+	pacing, ok := l.hosts[host]
+	if !ok {
+		pacing = &hostPacing{}
+		l.hosts[host] = pacing
+	}
+	l.mu.Unlock()
+
+	pacing.mu.Lock()
+	if until := time.Now().Add(d); until.After(pacing.next) {
+		pacing.next = until
+	}
+	pacing.mu.Unlock()
+}
+
+// delayFor picks the crawl delay of a host: the configured delay of the host or its
+// closest configured parent domain, otherwise the larger of the robots.txt Crawl-delay
+// and the default
+func (l *domainLimiter) delayFor(ctx context.Context, scheme, hostPort, host string) time.Duration {
+	for domain := host; domain != ""; {
+		if delay, ok := l.delays[domain]; ok {
+			return delay
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found || !strings.Contains(parent, ".") {
+			break
+		}
+		domain = parent
+	}
+
+	delay := l.defaultDelay
+	if l.robotsDelay != nil {
+		if robots, ok := l.robotsDelay(ctx, scheme, hostPort); ok && robots > delay {
+			delay = robots
+		}
+	}
+	return delay
+}
+
+// waitForDomain applies the per-domain crawl delay before a request to rawURL
+func (s *EducationalWebScraper) waitForDomain(ctx context.Context, rawURL string) error {
+	return s.domainLimiter.Wait(ctx, rawURL)
+}
+
+// robotsCrawlDelay returns the robots.txt Crawl-delay that applies to the scraper's
+// user agent on a host, if any
+func (s *EducationalWebScraper) robotsCrawlDelay(ctx context.Context, scheme, host string) (time.Duration, bool) {
+	rules := s.robots.rules(ctx, scheme, host)
+	if !rules.hasCrawlDelay {
+		return 0, false
+	}
+
+	delay := rules.crawlDelay
+	if delay > maxRobotsCrawlDelay {
+		delay = maxRobotsCrawlDelay
+	}
+
+	s.logger.Info("Using robots.txt crawl delay",
+		zap.String("host", host),
+		zap.Duration("crawl_delay", delay))
+	return delay, true
+}
+
+// normalizeHost lowercases a host and drops a leading "www."
+func normalizeHost(host string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(host)), "www.")
+}
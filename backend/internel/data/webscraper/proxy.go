@@ -0,0 +1,57 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// proxySchemes are the proxy URL schemes net/http can dial through
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+// proxyRotator hands out the configured proxies round-robin, one per outbound request
+type proxyRotator struct {
+	proxies []*url.URL
+	next    atomic.Uint64
+	logger  *zap.Logger
+}
+
+// newProxyRotator parses the scraper's proxies: ProxyRotation when set, else ProxyURL.
+// It returns nil when no proxy is configured.
+func newProxyRotator(config ScraperConfig, logger *zap.Logger) (*proxyRotator, error) {
+	rawProxies := config.ProxyRotation
+	if len(rawProxies) == 0 && config.ProxyURL != "" {
+		rawProxies = []string{config.ProxyURL}
+	}
+
+	var proxies []*url.URL
+	for _, raw := range rawProxies {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		proxy, err := url.Parse(raw)
+		if err != nil || !proxySchemes[proxy.Scheme] || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid scraper proxy %q: want http(s):// or socks5://host:port", maskConnectionString(raw))
+		}
+		proxies = append(proxies, proxy)
+	}
+	if len(proxies) == 0 {
+		return nil, nil
+	}
+
+	return &proxyRotator{proxies: proxies, logger: logger}, nil
+}
+
+// Proxy is an http.Transport Proxy function picking the next proxy for req
+func (p *proxyRotator) Proxy(req *http.Request) (*url.URL, error) {
+	proxy := p.proxies[(p.next.Add(1)-1)%uint64(len(p.proxies))]
+	p.logger.Debug("Routing scraper request through proxy",
+		zap.String("host", req.URL.Host),
+		zap.String("proxy", maskConnectionString(proxy.String())))
+	return proxy, nil
+}
@@ -0,0 +1,94 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// resourcesOfTypes returns n resources of each type for one concept, scored so that
+// earlier types rank first
+func resourcesOfTypes(n int, types ...string) []EducationalResource {
+	var resources []EducationalResource
+	for t, resourceType := range types {
+		for i := 0; i < n; i++ {
+			resources = append(resources, EducationalResource{
+				ConceptID:    "limits",
+				Title:        fmt.Sprintf("%s %d", resourceType, i),
+				ResourceType: resourceType,
+				QualityScore: 0.9 - float64(t)*0.05 - float64(i)*0.001,
+			})
+		}
+	}
+	return resources
+}
+
+func TestFilterQualityResourcesCaps(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     ScraperConfig
+		resources  []EducationalResource
+		wantCounts map[string]int
+	}{
+		{
+			name:       "default caps share article and tutorial",
+			resources:  resourcesOfTypes(4, "video", "article", "tutorial"),
+			wantCounts: map[string]int{"video": 3, "article": 3},
+		},
+		{
+			name:       "tutorials fill the shared cap left by articles",
+			resources:  resourcesOfTypes(4, "tutorial", "article"),
+			wantCounts: map[string]int{"tutorial": 3},
+		},
+		{
+			name:       "other types are uncapped by default",
+			resources:  resourcesOfTypes(5, "reference"),
+			wantCounts: map[string]int{"reference": 5},
+		},
+		{
+			name:       "concept cap applies across types",
+			resources:  resourcesOfTypes(4, "video", "reference", "qa"),
+			wantCounts: map[string]int{"video": 3, "reference": 3},
+		},
+		{
+			name:       "other type cap",
+			config:     ScraperConfig{MaxResourcesPerOtherType: 1},
+			resources:  resourcesOfTypes(4, "reference", "qa", "course"),
+			wantCounts: map[string]int{"reference": 1, "qa": 1, "course": 1},
+		},
+		{
+			name:       "configured caps",
+			config:     ScraperConfig{MaxResourcesPerType: map[string]int{"video": 1, "qa": 0, "course + interactive": 2}},
+			resources:  resourcesOfTypes(3, "video", "qa", "course", "interactive"),
+			wantCounts: map[string]int{"video": 1, "course": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			if config.MaxResourcesPerType == nil {
+				config.MaxResourcesPerType = DefaultMaxResourcesPerType
+			}
+			config.MaxResourcesPerConcept = 6
+			minQualityScore := DefaultMinQualityScore
+			config.MinQualityScore = &minQualityScore
+			s := &EducationalWebScraper{config: config, logger: zap.NewNop()}
+
+			counts := make(map[string]int)
+			for _, resource := range s.filterQualityResources(context.Background(), tt.resources) {
+				counts[resource.ResourceType]++
+			}
+			if len(counts) != len(tt.wantCounts) {
+				t.Fatalf("kept %v, want %v", counts, tt.wantCounts)
+			}
+			for resourceType, want := range tt.wantCounts {
+				if counts[resourceType] != want {
+					t.Errorf("kept %v, want %v", counts, tt.wantCounts)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestRankScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		boost    float64
+		resource EducationalResource
+		want     float64
+	}{
+		{"unverified", 0.1, EducationalResource{QualityScore: 0.6}, 0.6},
+		{"verified is boosted", 0.1, EducationalResource{QualityScore: 0.6, IsVerified: true}, 0.7},
+		{"zero boost disables", 0, EducationalResource{QualityScore: 0.6, IsVerified: true}, 0.6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &EducationalWebScraper{config: ScraperConfig{VerifiedBoost: tt.boost}}
+			if got := s.RankScore(tt.resource); got < tt.want-1e-9 || got > tt.want+1e-9 {
+				t.Errorf("RankScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterQualityResourcesRanksVerifiedFirst(t *testing.T) {
+	tests := []struct {
+		name      string
+		boost     float64
+		wantFirst string
+	}{
+		{"boost outranks a slightly better unverified resource", 0.1, "https://verified.org"},
+		{"boost smaller than the gap", 0.01, "https://unverified.org"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minQualityScore := DefaultMinQualityScore
+			s := &EducationalWebScraper{
+				config: ScraperConfig{
+					VerifiedBoost:          tt.boost,
+					MinQualityScore:        &minQualityScore,
+					MaxResourcesPerConcept: 6,
+					MaxResourcesPerType:    DefaultMaxResourcesPerType,
+				},
+				logger: zap.NewNop(),
+			}
+			resources := []EducationalResource{
+				{ConceptID: "limits", ResourceType: "article", Title: "Limits notes", URL: "https://unverified.org", QualityScore: 0.75},
+				{ConceptID: "limits", ResourceType: "article", Title: "Continuity guide", URL: "https://verified.org", QualityScore: 0.7, IsVerified: true},
+			}
+
+			filtered := s.filterQualityResources(context.Background(), resources)
+			if len(filtered) != 2 || filtered[0].URL != tt.wantFirst {
+				t.Errorf("filtered = %+v, want %s first", filtered, tt.wantFirst)
+			}
+		})
+	}
+}
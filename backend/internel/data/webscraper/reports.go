@@ -0,0 +1,91 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidResourceReport is returned when a report has no usable reason
+var ErrInvalidResourceReport = errors.New("invalid resource report")
+
+const (
+	// maxReportReasonLength caps the stored length of a single report reason
+	maxReportReasonLength = 500
+	// maxStoredReports keeps only the most recent reports on each resource
+	maxStoredReports = 20
+	// reportDemotedQualityScore is the highest quality a demoted resource keeps
+	reportDemotedQualityScore = 0.1
+)
+
+// ResourceReport is a learner's complaint that a resource is broken or wrong
+type ResourceReport struct {
+	Reason     string    `bson:"reason" json:"reason"`
+	ReportedAt time.Time `bson:"reported_at" json:"reported_at"`
+}
+
+// ReportResource records a report against every stored copy of the resource with the
+// given URL. Once a resource's report count reaches the demotion threshold it is marked
+// unverified and its quality score is capped so it sinks in rankings.
+func (s *EducationalWebScraper) ReportResource(ctx context.Context, resourceURL string, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("%w: reason is required", ErrInvalidResourceReport)
+	}
+	reason = s.truncateString(reason, maxReportReasonLength)
+
+	canonical := canonicalizeURL(resourceURL)
+	report := ResourceReport{Reason: reason, ReportedAt: time.Now()}
+
+	result, err := s.collection.UpdateMany(ctx,
+		bson.M{"url": canonical},
+		bson.M{
+			"$inc": bson.M{"report_count": 1},
+			"$push": bson.M{"reports": bson.M{
+				"$each":  bson.A{report},
+				"$slice": -maxStoredReports,
+			}},
+		})
+	if err != nil {
+		return fmt.Errorf("failed to report resource: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: %s", ErrResourceNotFound, resourceURL)
+	}
+
+	demoted, err := s.applyReportDemotion(ctx, []string{canonical})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Resource reported",
+		zap.String("url", canonical),
+		zap.String("reason", reason),
+		zap.Int64("demoted", demoted))
+
+	return nil
+}
+
+// applyReportDemotion demotes the resources with the given URLs whose report count has
+// reached the threshold. It also runs after re-scraping so a refresh cannot undo it.
+func (s *EducationalWebScraper) applyReportDemotion(ctx context.Context, urls []string) (int64, error) {
+	result, err := s.collection.UpdateMany(ctx,
+		bson.M{
+			"url":          bson.M{"$in": urls},
+			"report_count": bson.M{"$gte": s.config.ReportDemotionThreshold},
+		},
+		bson.M{
+			"$set": bson.M{"is_verified": false},
+			"$min": bson.M{"quality_score": reportDemotedQualityScore},
+		})
+	if err != nil {
+		return 0, fmt.Errorf("failed to demote reported resources: %w", err)
+	}
+	return result.ModifiedCount, nil
+}
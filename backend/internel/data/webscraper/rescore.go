@@ -0,0 +1,118 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+const (
+	// rescoreBatchSize is how many resources are read and rewritten per round trip
+	rescoreBatchSize = 500
+	// qualityOverriddenField marks resources whose quality score a curator set by hand
+	qualityOverriddenField = "quality_overridden"
+)
+
+// RescoreAllResources recomputes the quality score of every stored YouTube video with
+// the current scoring weights, from its stored title, channel, duration and view count,
+// and rewrites the scores that changed, returning how many were updated. Other sources
+// are scored with a fixed per-source value at scrape time, so they have nothing to
+// recompute. Curator-set scores are skipped and report demotion still caps the result,
+// so re-running is safe.
+func (s *EducationalWebScraper) RescoreAllResources(ctx context.Context) (int64, error) {
+	filter := bson.M{
+		"source_domain":        "youtube.com",
+		"resource_type":        "video",
+		qualityOverriddenField: bson.M{"$ne": true},
+	}
+	cursor, err := s.collection.Find(ctx, filter, options.Find().SetBatchSize(rescoreBatchSize))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list resources to rescore: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var scanned, updated int64
+	var writes []mongo.WriteModel
+
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+		result, err := s.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return fmt.Errorf("failed to update rescored resources: %w", err)
+		}
+		updated += result.ModifiedCount
+		writes = writes[:0]
+
+		s.logger.Info("Rescoring resources",
+			zap.Int64("scanned", scanned),
+			zap.Int64("updated", updated))
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var resource EducationalResource
+		if err := cursor.Decode(&resource); err != nil {
+			s.logger.Warn("Skipping undecodable resource during rescore", zap.Error(err))
+			continue
+		}
+		scanned++
+
+		score := s.rescoreVideo(resource)
+		if math.Abs(score-resource.QualityScore) > 1e-9 {
+			writes = append(writes, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": resource.ID}).
+				SetUpdate(bson.M{"$set": bson.M{"quality_score": score}}))
+		}
+
+		if len(writes) >= rescoreBatchSize {
+			if err := flush(); err != nil {
+				return updated, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return updated, fmt.Errorf("failed to read resources to rescore: %w", err)
+	}
+	if err := flush(); err != nil {
+		return updated, err
+	}
+
+	s.logger.Info("Resource rescore completed",
+		zap.Int64("scanned", scanned),
+		zap.Int64("updated", updated))
+
+	return updated, nil
+}
+
+// rescoreVideo scores a stored video as the scorer would have when it was scraped,
+// capped like applyReportDemotion once reports reach the threshold
+func (s *EducationalWebScraper) rescoreVideo(resource EducationalResource) float64 {
+	video := YouTubeVideoData{
+		Title:       resource.Title,
+		Description: resource.Description,
+	}
+	if resource.AuthorChannel != nil {
+		video.Channel = *resource.AuthorChannel
+	}
+	if resource.Duration != nil {
+		video.Duration = *resource.Duration
+	}
+	if resource.ViewCount != nil {
+		video.ViewCount = strconv.FormatInt(*resource.ViewCount, 10) + " views"
+	}
+
+	resource.QualityScore = 0
+	score := s.scorer.Score(resource, video)
+	if resource.ReportCount >= s.config.ReportDemotionThreshold && score > reportDemotedQualityScore {
+		score = reportDemotedQualityScore
+	}
+	return score
+}
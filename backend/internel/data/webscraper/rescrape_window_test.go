@@ -0,0 +1,42 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestRecentScrapeFilterWindow(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		interval   time.Duration
+		scrapedAt  time.Time
+		wantRecent bool
+	}{
+		{"inside default window", DefaultRescrapeInterval, now.Add(-23 * time.Hour), true},
+		{"outside default window", DefaultRescrapeInterval, now.Add(-25 * time.Hour), false},
+		{"on the window edge", 6 * time.Hour, now.Add(-6 * time.Hour), true},
+		{"outside short window", 6 * time.Hour, now.Add(-7 * time.Hour), false},
+		{"inside long window", 7 * 24 * time.Hour, now.Add(-6 * 24 * time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &EducationalWebScraper{
+				config: ScraperConfig{RescrapeInterval: tt.interval},
+				now:    func() time.Time { return now },
+			}
+
+			filter := s.recentScrapeFilter("limits")
+			if filter["concept_id"] != "limits" {
+				t.Errorf("concept_id = %v, want limits", filter["concept_id"])
+			}
+			since := filter["scraped_at"].(bson.M)["$gte"].(time.Time)
+			if recent := !tt.scrapedAt.Before(since); recent != tt.wantRecent {
+				t.Errorf("scraped at %v counted recent = %v, want %v (since %v)", tt.scrapedAt, recent, tt.wantRecent, since)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package scraper
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultRobotsTTL is how long a fetched robots.txt is trusted before it is fetched again
+const DefaultRobotsTTL = 24 * time.Hour
+
+// robotsErrorTTL is how long a host whose robots.txt could not be fetched stays
+// disallowed before the fetch is tried again
+const robotsErrorTTL = time.Minute
+
+// robotsRules are the rules of the robots.txt group that applies to the scraper
+type robotsRules struct {
+	allow    []string
+	disallow []string
+	// disallowAll is set when robots.txt was unreachable, which RFC 9309 treats as a
+	// full disallow
+	disallowAll bool
+
+	crawlDelay    time.Duration
+	hasCrawlDelay bool
+}
+
+// allowed reports whether path (with its query) may be fetched: the longest matching
+// rule wins, Allow winning ties, and a path no rule matches is allowed
+func (r *robotsRules) allowed(path string) bool {
+	if r.disallowAll {
+		return false
+	}
+
+	longestAllow, longestDisallow := -1, -1
+	for _, pattern := range r.allow {
+		if len(pattern) > longestAllow && robotsPatternMatches(pattern, path) {
+			longestAllow = len(pattern)
+		}
+	}
+	for _, pattern := range r.disallow {
+		if len(pattern) > longestDisallow && robotsPatternMatches(pattern, path) {
+			longestDisallow = len(pattern)
+		}
+	}
+	return longestDisallow < 0 || longestAllow >= longestDisallow
+}
+
+// robotsPatternMatches matches a robots.txt path pattern, where * matches any run of
+// characters and a trailing $ anchors the end of the path
+func robotsPatternMatches(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	if !anchored {
+		// An unanchored pattern is a prefix match
+		pattern += "*"
+	}
+	return matchWildcard(pattern, path)
+}
+
+// matchWildcard matches path against the whole of pattern, where * matches any run of characters
+func matchWildcard(pattern, path string) bool {
+	star := strings.IndexByte(pattern, '*')
+	if star < 0 {
+		return pattern == path
+	}
+	if !strings.HasPrefix(path, pattern[:star]) {
+		return false
+	}
+	rest := pattern[star+1:]
+	for i := star; i <= len(path); i++ {
+		if matchWildcard(rest, path[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRobots returns the rules of the robots.txt groups naming the user agent's
+// product token, falling back to the "*" groups
+func parseRobots(r io.Reader, userAgent string) *robotsRules {
+	token := strings.ToLower(userAgent)
+	if i := strings.IndexAny(token, "/ "); i >= 0 {
+		token = token[:i]
+	}
+
+	var (
+		agents             []string
+		inRules            bool
+		specific, wildcard robotsRules
+		hasSpecific        bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		field, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+
+		if field == "user-agent" {
+			// A user-agent line after rules starts a new group
+			if inRules {
+				agents = nil
+				inRules = false
+			}
+			agents = append(agents, strings.ToLower(value))
+			continue
+		}
+		inRules = true
+
+		for _, agent := range agents {
+			var rules *robotsRules
+			switch {
+			case agent == "*":
+				rules = &wildcard
+			case agent != "" && strings.HasPrefix(token, agent):
+				rules, hasSpecific = &specific, true
+			default:
+				continue
+			}
+
+			switch field {
+			case "allow":
+				if value != "" {
+					rules.allow = append(rules.allow, value)
+				}
+			case "disallow":
+				// An empty Disallow allows everything
+				if value != "" {
+					rules.disallow = append(rules.disallow, value)
+				}
+			case "crawl-delay":
+				seconds, err := strconv.ParseFloat(value, 64)
+				if err == nil && seconds >= 0 {
+					rules.crawlDelay = time.Duration(seconds * float64(time.Second))
+					rules.hasCrawlDelay = true
+				}
+			}
+		}
+	}
+
+	if hasSpecific {
+		return &specific
+	}
+	return &wildcard
+}
+
+// robotsEntry is a host's cached robots.txt rules
+type robotsEntry struct {
+	once      sync.Once
+	rules     *robotsRules
+	expiresAt time.Time
+}
+
+// robotsChecker fetches and caches the robots.txt rules of each host
+type robotsChecker struct {
+	fetch func(ctx context.Context, scheme, host string) (*robotsRules, time.Duration)
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+func newRobotsChecker(ttl time.Duration, fetch func(ctx context.Context, scheme, host string) (*robotsRules, time.Duration)) *robotsChecker {
+	if ttl <= 0 {
+		ttl = DefaultRobotsTTL
+	}
+	return &robotsChecker{
+		fetch:   fetch,
+		ttl:     ttl,
+		entries: make(map[string]*robotsEntry),
+	}
+}
+
+// rules returns the cached rules of host, fetching them once per TTL
+func (c *robotsChecker) rules(ctx context.Context, scheme, host string) *robotsRules {
+	key := scheme + "://" + strings.ToLower(host)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) && !entry.expiresAt.IsZero() {
+		entry = &robotsEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		rules, ttl := c.fetch(ctx, scheme, host)
+		if ttl <= 0 {
+			ttl = c.ttl
+		}
+		c.mu.Lock()
+		entry.rules = rules
+		entry.expiresAt = time.Now().Add(ttl)
+		c.mu.Unlock()
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return entry.rules
+}
+
+// Allowed reports whether robots.txt lets the scraper fetch rawURL
+func (c *robotsChecker) Allowed(ctx context.Context, rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return true
+	}
+
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if parsed.RawQuery != "" {
+		path += "?" + parsed.RawQuery
+	}
+	return c.rules(ctx, parsed.Scheme, parsed.Host).allowed(path)
+}
+
+// allowedByRobots reports whether rawURL may be scraped; it is always true when
+// RespectRobotsTxt is off. Disallowed URLs are logged at debug level.
+func (s *EducationalWebScraper) allowedByRobots(ctx context.Context, rawURL string) bool {
+	if !s.config.RespectRobotsTxt || s.robots.Allowed(ctx, rawURL) {
+		return true
+	}
+	s.logger.Debug("Skipping URL disallowed by robots.txt", zap.String("url", rawURL))
+	return false
+}
+
+// fetchRobots fetches and parses a host's robots.txt. A missing file (4xx) allows
+// everything; an unreachable one (network error or 5xx) disallows everything until
+// robotsErrorTTL passes.
+func (s *EducationalWebScraper) fetchRobots(ctx context.Context, scheme, host string) (*robotsRules, time.Duration) {
+	if scheme == "" {
+		scheme = "https"
+	}
+	robotsURL := fmt.Sprintf("%s://%s/robots.txt", scheme, host)
+
+	// Not bound to the caller: the result is cached and shared with other requests
+	fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), robotsFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(fetchCtx, "GET", robotsURL, nil)
+	if err != nil {
+		return &robotsRules{}, 0
+	}
+	req.Header.Set("User-Agent", s.config.UserAgent)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		s.logger.Debug("Failed to fetch robots.txt", zap.String("url", robotsURL), zap.Error(err))
+		return &robotsRules{disallowAll: true}, robotsErrorTTL
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return &robotsRules{disallowAll: true}, robotsErrorTTL
+	case resp.StatusCode != http.StatusOK:
+		return &robotsRules{}, 0
+	}
+
+	return parseRobots(io.LimitReader(resp.Body, maxRobotsBodyBytes), s.config.UserAgent), 0
+}
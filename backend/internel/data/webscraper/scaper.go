@@ -3,15 +3,21 @@ package scraper
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"mathprereq/pkg/logger"
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/PuerkitoBio/goquery"
 	"go.mongodb.org/mongo-driver/bson"
@@ -23,7 +29,11 @@ import (
 	"golang.org/x/time/rate"
 )
 
-// EducationalResource represents a scraped educational resource
+// EducationalResource represents a scraped educational resource.
+//
+// A resource document is scoped to a single concept: the same URL may be stored once
+// per concept it is relevant to, and the (concept_id, url) pair is unique. This keeps
+// per-concept fields such as quality score and difficulty independent.
 type EducationalResource struct {
 	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	ConceptID       string             `bson:"concept_id" json:"concept_id"`
@@ -31,7 +41,7 @@ type EducationalResource struct {
 	Title           string             `bson:"title" json:"title"`
 	URL             string             `bson:"url" json:"url"`
 	Description     string             `bson:"description" json:"description"`
-	ResourceType    string             `bson:"resource_type" json:"resource_type"` // video, article, tutorial, example, practice
+	ResourceType    string             `bson:"resource_type" json:"resource_type"` // video, article, tutorial, example, practice, reference, discussion
 	SourceDomain    string             `bson:"source_domain" json:"source_domain"`
 	DifficultyLevel string             `bson:"difficulty_level" json:"difficulty_level"` // beginner, intermediate, advanced
 	QualityScore    float64            `bson:"quality_score" json:"quality_score"`       // 0.0 to 1.0
@@ -46,21 +56,207 @@ type EducationalResource struct {
 	PublishedAt     *time.Time         `bson:"published_at,omitempty" json:"published_at,omitempty"`
 	Tags            []string           `bson:"tags" json:"tags"`
 	IsVerified      bool               `bson:"is_verified" json:"is_verified"`
+	// Report fields are omitted when empty so re-scraping does not reset them
+	ReportCount int              `bson:"report_count,omitempty" json:"report_count"`
+	Reports     []ResourceReport `bson:"reports,omitempty" json:"reports,omitempty"`
+}
+
+// Errors returned by resource curation methods
+var (
+	ErrResourceNotFound      = errors.New("resource not found")
+	ErrInvalidResourceUpdate = errors.New("invalid resource update")
+)
+
+// allowedDifficultyLevels lists the difficulty values a resource may carry
+var allowedDifficultyLevels = map[string]bool{
+	"beginner":     true,
+	"intermediate": true,
+	"advanced":     true,
+}
+
+// ResourceUpdate holds the curator-editable fields of a resource; nil fields are left unchanged
+type ResourceUpdate struct {
+	Title           *string   `json:"title,omitempty"`
+	Description     *string   `json:"description,omitempty"`
+	DifficultyLevel *string   `json:"difficulty_level,omitempty"`
+	QualityScore    *float64  `json:"quality_score,omitempty"`
+	Tags            *[]string `json:"tags,omitempty"`
+	IsVerified      *bool     `json:"is_verified,omitempty"`
 }
 
 // ScraperConfig holds configuration for the scraper
 type ScraperConfig struct {
-	MaxConcurrentRequests int           `json:"max_concurrent_requests"`
-	RequestTimeout        time.Duration `json:"request_timeout"`
-	RateLimit             float64       `json:"rate_limit"` // requests per second
-	UserAgent             string        `json:"user_agent"`
-	MongoURI              string        `json:"mongo_uri"`
-	DatabaseName          string        `json:"database_name"`
-	CollectionName        string        `json:"collection_name"`
-	MaxRetries            int           `json:"max_retries"`
-	RetryDelay            time.Duration `json:"retry_delay"`
+	// MaxConcurrentConcepts concepts are scraped at once, each querying up to
+	// MaxConcurrentSourcesPerConcept sources at once, so at most their product of
+	// searches run together (all still paced by RateLimit). MaxConcurrentConcepts
+	// defaults to 10; MaxConcurrentSourcesPerConcept 0 queries every source at once.
+	MaxConcurrentConcepts          int `json:"max_concurrent_concepts"`
+	MaxConcurrentSourcesPerConcept int `json:"max_concurrent_sources_per_concept"`
+
+	RequestTimeout time.Duration `json:"request_timeout"`
+	RateLimit      float64       `json:"rate_limit"` // requests per second
+	UserAgent      string        `json:"user_agent"`
+	MongoURI       string        `json:"mongo_uri"`
+	DatabaseName   string        `json:"database_name"`
+	CollectionName string        `json:"collection_name"`
+	MaxRetries     int           `json:"max_retries"`
+	RetryDelay     time.Duration `json:"retry_delay"`
+
+	// StopWords are stripped, as whole case-sensitive words, from multi-word concepts to
+	// build an extra search term. Defaults to DefaultStopWords when empty.
+	StopWords []string `json:"stop_words"`
+	// PreserveStopWords lists concept IDs whose names must be searched verbatim
+	PreserveStopWords []string `json:"preserve_stop_words"`
+	// SearchTermTemplates maps a concept ID (or "*" for every concept) to extra search
+	// terms; "{concept}" is replaced with the normalized concept name,
+	// e.g. "{concept} site:khanacademy.org"
+	SearchTermTemplates map[string][]string `json:"search_term_templates"`
+
+	// BeginnerKeywords and AdvancedKeywords drive difficulty assessment of titles and
+	// snippets; they default to DefaultBeginnerKeywords and DefaultAdvancedKeywords
+	BeginnerKeywords []string `json:"beginner_keywords"`
+	AdvancedKeywords []string `json:"advanced_keywords"`
+
+	// EnsureConceptNodes creates a stub graph concept (source "auto") before storing
+	// resources for a concept the graph does not know, when the resolver supports it
+	EnsureConceptNodes bool `json:"ensure_concept_nodes"`
+
+	// ReportDemotionThreshold is the number of learner reports after which a resource
+	// is demoted
+	ReportDemotionThreshold int `json:"report_demotion_threshold"`
+
+	// ConceptFeeds maps a concept ID (or lowercased concept name) to RSS/Atom feed URLs
+	// scraped alongside the search sources
+	ConceptFeeds map[string][]string `json:"concept_feeds"`
+
+	// Unforced scrapes are skipped for concepts that already have SufficientResourceCount
+	// resources with a quality score of at least SufficientResourceQuality; 0 disables
+	SufficientResourceCount   int     `json:"sufficient_resource_count"`
+	SufficientResourceQuality float64 `json:"sufficient_resource_quality"`
+
+	// VerifiedBoost is added to a verified resource's quality score when ranking, so it
+	// outranks unverified resources scoring up to VerifiedBoost higher; 0 disables
+	VerifiedBoost float64 `json:"verified_boost"`
+
+	// YouTubeScoring weighs the signals in a YouTube video's quality score; defaults to
+	// DefaultYouTubeScoringWeights when unset
+	YouTubeScoring YouTubeScoringWeights `json:"youtube_scoring"`
+	// Scorer replaces the default YouTube video scoring, which uses YouTubeScoring
+	Scorer QualityScorer `json:"-"`
+
+	// CrawlDelays maps a domain to the minimum time between requests to it (and its
+	// subdomains); other domains wait DefaultCrawlDelay, which defaults to 1 second
+	CrawlDelays       map[string]time.Duration `json:"crawl_delays"`
+	DefaultCrawlDelay time.Duration            `json:"default_crawl_delay"`
+	// RespectRobotsCrawlDelay raises an unconfigured domain's delay to the Crawl-delay
+	// of its robots.txt, fetched once per host
+	RespectRobotsCrawlDelay bool `json:"respect_robots_crawl_delay"`
+	// RespectRobotsTxt skips sources whose robots.txt disallows the scraper's user agent;
+	// robots.txt is fetched again once RobotsTxtTTL (default 24 hours) has passed
+	RespectRobotsTxt bool          `json:"respect_robots_txt"`
+	RobotsTxtTTL     time.Duration `json:"robots_txt_ttl"`
+
+	// YouTubeAPIKey switches YouTube search from scraping the results page to the
+	// YouTube Data API v3; empty keeps the page scraper
+	YouTubeAPIKey string `json:"-"`
+
+	// MaxResourcesPerType caps how many resources of each type are kept per concept when
+	// filtering a scrape (0 drops the type); a key joining types with "+", such as
+	// "article+tutorial", caps them together. Types not listed are capped at
+	// MaxResourcesPerOtherType when it is positive, and MaxResourcesPerConcept caps all
+	// types together. They default to DefaultMaxResourcesPerType, uncapped and 6.
+	MaxResourcesPerType      map[string]int `json:"max_resources_per_type"`
+	MaxResourcesPerOtherType int            `json:"max_resources_per_other_type"`
+	MaxResourcesPerConcept   int            `json:"max_resources_per_concept"`
+
+	// StackExchangeResults is how many Math StackExchange questions are fetched per
+	// concept, highest voted first; defaults to 3
+	StackExchangeResults int `json:"stackexchange_results"`
+
+	// DuplicateTitleThreshold drops a resource whose title is more similar than this
+	// (Jaccard, 0-1) to a kept resource of the same concept and type, keeping the higher
+	// quality one; defaults to 0.8, and 1 keeps all near-duplicates
+	DuplicateTitleThreshold float64 `json:"duplicate_title_threshold"`
+
+	// EducationalDomains are the domains, with their subdomains, whose links general site
+	// searches keep besides those of the searched site; defaults to DefaultEducationalDomains
+	EducationalDomains []string `json:"educational_domains"`
+	// MaxResultsPerSource caps the resources one search of a source ("youtube",
+	// "khan_academy", "mathworld", "wikipedia", "general") returns; "youtube_total" caps
+	// the videos kept across a concept's YouTube searches. Keys not listed use
+	// DefaultMaxResultsPerSource and 0 turns a source off. Math StackExchange questions
+	// are counted by StackExchangeResults.
+	MaxResultsPerSource map[string]int `json:"max_results_per_source"`
+	// MinQualityScore is the lowest quality score a scraped resource may have to be
+	// kept; nil uses DefaultMinQualityScore and 0 keeps every resource
+	MinQualityScore *float64 `json:"min_quality_score,omitempty"`
+
+	// RescrapeInterval is how long after a concept's last scrape unforced scrapes of it
+	// are skipped; defaults to DefaultRescrapeInterval
+	RescrapeInterval time.Duration `json:"rescrape_interval"`
+
+	// ProxyURL routes every scraper request through an http(s):// or socks5:// proxy;
+	// ProxyRotation, when set, is used instead and cycles through its proxies request
+	// by request
+	ProxyURL      string   `json:"-"`
+	ProxyRotation []string `json:"-"`
+}
+
+// DefaultRescrapeInterval is the recent-scrape window used when none is configured
+const DefaultRescrapeInterval = 24 * time.Hour
+
+// DefaultMinQualityScore is the quality floor of kept resources used when none is configured
+const DefaultMinQualityScore = 0.4
+
+// ConceptResolver maps a free-text concept name to the canonical concept ID in the
+// knowledge graph; a nil ID means the concept is not in the graph
+type ConceptResolver interface {
+	FindConceptID(ctx context.Context, conceptName string) (*string, error)
+}
+
+// Default difficulty keywords, matched case-insensitively as substrings
+var (
+	DefaultBeginnerKeywords = []string{"intro", "basic", "beginner", "simple", "easy", "start", "fundamental"}
+	DefaultAdvancedKeywords = []string{"advanced", "complex", "graduate", "proof", "theorem", "rigorous"}
+)
+
+// ConceptEnsurer is optionally implemented by a ConceptResolver to create stub concepts
+// in the graph for resources whose concept is not there yet
+type ConceptEnsurer interface {
+	EnsureConcept(ctx context.Context, conceptID, name string) (bool, error)
+}
+
+// DefaultMaxResourcesPerType are the per-concept caps of the common resource types used
+// when none are configured: articles and tutorials share one cap
+var DefaultMaxResourcesPerType = map[string]int{
+	"video":            3,
+	"article+tutorial": 3,
+}
+
+// DefaultEducationalDomains are the educational domains used when none are configured
+var DefaultEducationalDomains = []string{
+	"youtube.com", "youtu.be", "khanacademy.org", "coursera.org", "edx.org",
+	"mit.edu", "stanford.edu", "mathworld.wolfram.com", "brilliant.org",
+	"mathisfun.com", "paulmscience.com", "tutorial.math.lamar.edu",
+	"mathinsight.org", "betterexplained.com", "patrickjmt.com",
+	"professorleonard.com", "organic-chemistry.com", "symbolab.com",
+	"wikipedia.org", "math.stackexchange.com",
+}
+
+// DefaultMaxResultsPerSource are the per-search result caps of the sources
+var DefaultMaxResultsPerSource = map[string]int{
+	"youtube":      3,
+	"khan_academy": 3,
+	"mathworld":    2,
+	"wikipedia":    3,
+	"general":      4,
+	// Across the YouTube searches of one concept
+	"youtube_total": 5,
 }
 
+// DefaultStopWords are the words removed from multi-word concepts when no list is configured
+var DefaultStopWords = []string{"Basic", "Advanced", "Elementary", "Introduction", "to", "the", "of", "and", "in"}
+
 // EducationalWebScraper scrapes educational content
 type EducationalWebScraper struct {
 	config       ScraperConfig
@@ -72,6 +268,29 @@ type EducationalWebScraper struct {
 	scrapedURLs  sync.Map // Thread-safe cache of scraped URLs
 	sharedClient bool     // Whether we're using a shared MongoDB client
 
+	stopWordPattern   *regexp.Regexp
+	preserveStopWords map[string]bool
+
+	// attempts holds one ScrapeAttempt per concept ID
+	attempts *mongo.Collection
+
+	// domainLimiter enforces the per-domain crawl delay on top of the global rate limit
+	domainLimiter *domainLimiter
+	// robots caches the robots.txt rules of each host
+	robots *robotsChecker
+
+	// conceptResolver is optional; without it resources are keyed by the normalized name
+	conceptResolver ConceptResolver
+
+	// learningResources is optional; when set, stored resources are mirrored into it
+	learningResources LearningResourceStore
+
+	// scorer computes the quality score of YouTube videos
+	scorer QualityScorer
+
+	// now is the clock of the rescrape window, swappable in tests
+	now func() time.Time
+
 	// Educational domains to target
 	educationalDomains []string
 }
@@ -93,8 +312,11 @@ func New(config ScraperConfig, mongoClient *mongo.Client) (*EducationalWebScrape
 	logger := logger.MustGetLogger()
 
 	// Set defaults
-	if config.MaxConcurrentRequests == 0 {
-		config.MaxConcurrentRequests = 10
+	if config.MaxConcurrentConcepts <= 0 {
+		config.MaxConcurrentConcepts = 10
+	}
+	if config.MaxConcurrentSourcesPerConcept < 0 {
+		config.MaxConcurrentSourcesPerConcept = 0
 	}
 	if config.RequestTimeout == 0 {
 		config.RequestTimeout = 30 * time.Second
@@ -111,6 +333,65 @@ func New(config ScraperConfig, mongoClient *mongo.Client) (*EducationalWebScrape
 	if config.RetryDelay == 0 {
 		config.RetryDelay = 2 * time.Second
 	}
+	if config.MaxResourcesPerType == nil {
+		config.MaxResourcesPerType = DefaultMaxResourcesPerType
+	}
+	if config.MaxResourcesPerConcept <= 0 {
+		config.MaxResourcesPerConcept = 6
+	}
+	if config.StackExchangeResults <= 0 {
+		config.StackExchangeResults = 3
+	} else if config.StackExchangeResults > 100 {
+		config.StackExchangeResults = 100 // the API's page size limit
+	}
+	if config.RescrapeInterval <= 0 {
+		config.RescrapeInterval = DefaultRescrapeInterval
+	}
+	if len(config.EducationalDomains) == 0 {
+		config.EducationalDomains = DefaultEducationalDomains
+	}
+	if config.MinQualityScore == nil {
+		minQualityScore := DefaultMinQualityScore
+		config.MinQualityScore = &minQualityScore
+	}
+	if config.DuplicateTitleThreshold <= 0 {
+		config.DuplicateTitleThreshold = 0.8
+	}
+	if config.DefaultCrawlDelay <= 0 {
+		config.DefaultCrawlDelay = DefaultCrawlDelay
+	}
+	if config.ReportDemotionThreshold <= 0 {
+		config.ReportDemotionThreshold = 3
+	}
+	if config.SufficientResourceQuality <= 0 {
+		config.SufficientResourceQuality = 0.6
+	}
+	if config.YouTubeScoring == (YouTubeScoringWeights{}) {
+		config.YouTubeScoring = DefaultYouTubeScoringWeights
+	}
+	if len(config.StopWords) == 0 {
+		config.StopWords = DefaultStopWords
+	}
+	if len(config.BeginnerKeywords) == 0 {
+		config.BeginnerKeywords = DefaultBeginnerKeywords
+	}
+	if len(config.AdvancedKeywords) == 0 {
+		config.AdvancedKeywords = DefaultAdvancedKeywords
+	}
+
+	quotedStopWords := make([]string, len(config.StopWords))
+	for i, word := range config.StopWords {
+		quotedStopWords[i] = regexp.QuoteMeta(word)
+	}
+	stopWordPattern, err := regexp.Compile(`\b(` + strings.Join(quotedStopWords, "|") + `)\b`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stop word list: %w", err)
+	}
+
+	preserveStopWords := make(map[string]bool, len(config.PreserveStopWords))
+	for _, conceptID := range config.PreserveStopWords {
+		preserveStopWords[conceptID] = true
+	}
 
 	// Create HTTP client with connection pooling
 	transport := &http.Transport{
@@ -119,6 +400,16 @@ func New(config ScraperConfig, mongoClient *mongo.Client) (*EducationalWebScrape
 		IdleConnTimeout:     90 * time.Second,
 	}
 
+	// Every source shares this client, so the proxy covers all of them
+	proxies, err := newProxyRotator(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	if proxies != nil {
+		transport.Proxy = proxies.Proxy
+		logger.Info("Scraper requests go through a proxy", zap.Int("proxies", len(proxies.proxies)))
+	}
+
 	httpClient := &http.Client{
 		Transport: transport,
 		Timeout:   config.RequestTimeout,
@@ -140,12 +431,11 @@ func New(config ScraperConfig, mongoClient *mongo.Client) (*EducationalWebScrape
 		}
 	}
 
-	educationalDomains := []string{
-		"youtube.com", "youtu.be", "khanacademy.org", "coursera.org", "edx.org",
-		"mit.edu", "stanford.edu", "mathworld.wolfram.com", "brilliant.org",
-		"mathisfun.com", "paulmscience.com", "tutorial.math.lamar.edu",
-		"mathinsight.org", "betterexplained.com", "patrickjmt.com",
-		"professorleonard.com", "organic-chemistry.com", "symbolab.com",
+	educationalDomains := make([]string, 0, len(config.EducationalDomains))
+	for _, domain := range config.EducationalDomains {
+		if domain = normalizeHost(domain); domain != "" {
+			educationalDomains = append(educationalDomains, domain)
+		}
 	}
 
 	scraper := &EducationalWebScraper{
@@ -154,28 +444,84 @@ func New(config ScraperConfig, mongoClient *mongo.Client) (*EducationalWebScrape
 		limiter:            limiter,
 		mongoClient:        mongoClient,
 		collection:         collection,
+		attempts:           mongoClient.Database(config.DatabaseName).Collection("scrape_attempts"),
 		logger:             logger,
 		educationalDomains: educationalDomains,
 		sharedClient:       true, // This is now always true
+		stopWordPattern:    stopWordPattern,
+		preserveStopWords:  preserveStopWords,
+		domainLimiter:      newDomainLimiter(config.CrawlDelays, config.DefaultCrawlDelay),
+		now:                time.Now,
+	}
+	scraper.robots = newRobotsChecker(config.RobotsTxtTTL, scraper.fetchRobots)
+	scraper.scorer = config.Scorer
+	if scraper.scorer == nil {
+		scraper.scorer = defaultQualityScorer{weights: config.YouTubeScoring}
+	}
+	if config.RespectRobotsCrawlDelay {
+		scraper.domainLimiter.robotsDelay = scraper.robotsCrawlDelay
 	}
 
 	logger.Info("Educational web scraper initialized",
-		zap.Int("max_concurrent", config.MaxConcurrentRequests),
+		zap.Int("max_concurrent_concepts", config.MaxConcurrentConcepts),
+		zap.Int("max_concurrent_sources", config.MaxConcurrentSourcesPerConcept),
 		zap.Float64("rate_limit", config.RateLimit),
+		zap.Duration("default_crawl_delay", config.DefaultCrawlDelay),
+		zap.Int("crawl_delay_overrides", len(config.CrawlDelays)),
+		zap.Bool("respect_robots_txt", config.RespectRobotsTxt),
+		zap.Bool("youtube_data_api", config.YouTubeAPIKey != ""),
 		zap.String("database", config.DatabaseName))
 
 	return scraper, nil
 }
 
+// legacyURLIndexName is the former unique index on url alone, which blocked storing
+// a URL under more than one concept
+const legacyURLIndexName = "url_1"
+
+// requiredIndexName is the index resource upserts depend on; CheckIndexes looks for it
+const requiredIndexName = "concept_id_url_unique"
+
+// CheckIndexes verifies the resource collection has the indexes createIndexes builds
+func (s *EducationalWebScraper) CheckIndexes(ctx context.Context) error {
+	cursor, err := s.collection.Indexes().List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list indexes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var index struct {
+			Name string `bson:"name"`
+		}
+		if err := cursor.Decode(&index); err == nil && index.Name == requiredIndexName {
+			return nil
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to read indexes: %w", err)
+	}
+	return fmt.Errorf("index %s is missing from %s", requiredIndexName, s.collection.Name())
+}
+
 // createIndexes creates MongoDB indexes for efficient queries
 func createIndexes(ctx context.Context, collection *mongo.Collection) error {
+	// Migrate away from the url-only unique index; a missing index is not an error
+	if _, err := collection.Indexes().DropOne(ctx, legacyURLIndexName); err != nil && !isIndexNotFoundError(err) {
+		return fmt.Errorf("failed to drop legacy url index: %w", err)
+	}
+
 	indexes := []mongo.IndexModel{
 		{
 			Keys: bson.D{{"concept_id", 1}},
 		},
+		{
+			Keys:    bson.D{{"concept_id", 1}, {"url", 1}},
+			Options: options.Index().SetUnique(true).SetName("concept_id_url_unique"),
+		},
 		{
 			Keys:    bson.D{{"url", 1}},
-			Options: options.Index().SetUnique(true),
+			Options: options.Index().SetName("url_lookup"),
 		},
 		{
 			Keys: bson.D{{"quality_score", -1}},
@@ -201,6 +547,16 @@ func createIndexes(ctx context.Context, collection *mongo.Collection) error {
 	return nil
 }
 
+// isIndexNotFoundError reports whether err means the index or collection does not exist
+func isIndexNotFoundError(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) {
+		// 26: NamespaceNotFound, 27: IndexNotFound
+		return cmdErr.Code == 26 || cmdErr.Code == 27
+	}
+	return false
+}
+
 // Close closes the scraper and its connections
 func (s *EducationalWebScraper) Close(ctx context.Context) error {
 	// Only close the MongoDB client if we created it ourselves
@@ -210,82 +566,148 @@ func (s *EducationalWebScraper) Close(ctx context.Context) error {
 	return nil
 }
 
+// ScrapeProgress reports one step of a scrape. A source event (Source set, Done false)
+// is sent as each source finishes searching a concept; a final event with Done set is
+// sent once the concept's resources are stored, with Found holding the stored count.
+type ScrapeProgress struct {
+	Concept string `json:"concept"`
+	Source  string `json:"source,omitempty"`
+	Found   int    `json:"found"`
+	Done    bool   `json:"done"`
+	Skipped bool   `json:"skipped,omitempty"`
+	// Reason explains a skip: recently_scraped or sufficient_resources
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Reasons a concept scrape is skipped
+const (
+	SkipRecentlyScraped     = "recently_scraped"
+	SkipSufficientResources = "sufficient_resources"
+)
+
 // ScrapeResourcesForConcepts scrapes educational resources for given concepts
 func (s *EducationalWebScraper) ScrapeResourcesForConcepts(ctx context.Context, conceptNames []string) error {
-	s.logger.Info("Starting resource scraping", zap.Int("concepts", len(conceptNames)))
+	return s.ScrapeResourcesForConceptsWithProgress(ctx, conceptNames, nil)
+}
 
-	// Process concepts in batches
-	batchSize := 3
-	for i := 0; i < len(conceptNames); i += batchSize {
-		end := i + batchSize
-		if end > len(conceptNames) {
-			end = len(conceptNames)
+// ScrapeResourcesForConceptsWithProgress scrapes resources like ScrapeResourcesForConcepts and
+// sends a ScrapeProgress event to progress as each concept and source completes. progress may
+// be nil; otherwise it is closed when scraping finishes. Sends give up when ctx is cancelled,
+// so a caller that stops reading must cancel ctx.
+func (s *EducationalWebScraper) ScrapeResourcesForConceptsWithProgress(ctx context.Context, conceptNames []string, progress chan<- ScrapeProgress) error {
+	if progress != nil {
+		defer close(progress)
+	}
+	report := func(event ScrapeProgress) {
+		if progress == nil {
+			return
 		}
-
-		batch := conceptNames[i:end]
-		s.logger.Info("Processing batch",
-			zap.Int("batch", i/batchSize+1),
-			zap.Int("total_batches", (len(conceptNames)+batchSize-1)/batchSize))
-
-		if err := s.processBatch(ctx, batch); err != nil {
-			s.logger.Error("Batch processing failed", zap.Error(err))
-			continue
+		select {
+		case progress <- event:
+		case <-ctx.Done():
 		}
-
-		// Rate limiting between batches
-		time.Sleep(2 * time.Second)
 	}
 
-	s.logger.Info("Resource scraping completed", zap.Int("total_concepts", len(conceptNames)))
-	return nil
-}
+	s.log(ctx).Info("Starting resource scraping", zap.Int("concepts", len(conceptNames)))
 
-// processBatch processes a batch of concepts concurrently
-func (s *EducationalWebScraper) processBatch(ctx context.Context, conceptNames []string) error {
-	g, gCtx := errgroup.WithContext(ctx)
-	g.SetLimit(s.config.MaxConcurrentRequests)
+	// At most MaxConcurrentConcepts concepts in flight; request pacing comes from the rate limiter
+	var g errgroup.Group
+	g.SetLimit(s.config.MaxConcurrentConcepts)
+	var failed atomic.Int64
 
 	for _, conceptName := range conceptNames {
-		conceptName := conceptName // Capture for goroutine
+		if ctx.Err() != nil {
+			break
+		}
 		g.Go(func() error {
-			return s.scrapeResourcesForConcept(gCtx, conceptName)
+			if ctx.Err() != nil {
+				return nil
+			}
+			// One failing concept must not abort the rest
+			if err := s.scrapeResourcesForConcept(ctx, conceptName, false, report); err != nil {
+				s.logger.Error("Concept scraping failed",
+					zap.String("concept", conceptName),
+					zap.Error(err))
+				failed.Add(1)
+				report(ScrapeProgress{Concept: conceptName, Done: true, Error: err.Error()})
+			}
+			return nil
 		})
 	}
 
-	return g.Wait()
+	g.Wait()
+
+	s.log(ctx).Info("Resource scraping completed",
+		zap.Int("total_concepts", len(conceptNames)),
+		zap.Int64("failed_concepts", failed.Load()))
+	return ctx.Err()
 }
 
-// scrapeResourcesForConcept scrapes resources for a single concept
-func (s *EducationalWebScraper) scrapeResourcesForConcept(ctx context.Context, conceptName string) error {
-	s.logger.Info("Scraping resources for concept", zap.String("concept", conceptName))
+// scrapeResourcesForConcept scrapes resources for a single concept, reporting progress as
+// each source completes and when the concept is done; failures are reported by the caller.
+// force skips the recent-scrape check.
+func (s *EducationalWebScraper) scrapeResourcesForConcept(ctx context.Context, conceptName string, force bool, report func(ScrapeProgress)) error {
+	s.log(ctx).Info("Scraping resources for concept", zap.String("concept", conceptName))
 
-	conceptID := s.generateConceptID(conceptName)
+	conceptID := s.ResolveConceptID(ctx, conceptName)
 
 	// Check if we've recently scraped this concept
-	if s.isRecentlyScraped(ctx, conceptID) {
-		s.logger.Info("Concept recently scraped, skipping", zap.String("concept", conceptName))
+	if !force && s.isRecentlyScraped(ctx, conceptID) {
+		s.log(ctx).Info("Concept recently scraped, skipping", zap.String("concept", conceptName))
+		report(ScrapeProgress{Concept: conceptName, Done: true, Skipped: true, Reason: SkipRecentlyScraped})
 		return nil
 	}
 
+	// Well-covered concepts are not re-scraped; sparse ones still are
+	if !force {
+		if count, sufficient := s.hasSufficientResources(ctx, conceptID); sufficient {
+			s.log(ctx).Info("Concept has enough quality resources, skipping",
+				zap.String("concept", conceptName),
+				zap.Int64("quality_resources", count),
+				zap.Int("threshold", s.config.SufficientResourceCount))
+			report(ScrapeProgress{Concept: conceptName, Done: true, Skipped: true, Reason: SkipSufficientResources})
+			return nil
+		}
+	}
+
 	var allResources []EducationalResource
 
-	// Search different platforms concurrently
+	// Search different platforms concurrently, at most MaxConcurrentSourcesPerConcept at once
 	g, gCtx := errgroup.WithContext(ctx)
+	if s.config.MaxConcurrentSourcesPerConcept > 0 {
+		g.SetLimit(s.config.MaxConcurrentSourcesPerConcept)
+	}
 	var mu sync.Mutex
 
-	searchFunctions := []func(context.Context, string, string) ([]EducationalResource, error){
-		s.searchYouTube,
-		s.searchKhanAcademy,
-		s.searchMathWorld,
-		s.searchGeneralEducationSites,
+	searchFunctions := []struct {
+		source string
+		search func(context.Context, string, string) ([]EducationalResource, error)
+	}{
+		{"youtube", s.searchYouTube},
+		{"khan_academy", s.searchKhanAcademy},
+		{"mathworld", s.searchMathWorld},
+		{"wikipedia", s.searchWikipedia},
+		{"math_stackexchange", s.searchMathStackExchange},
+		{"general", s.searchGeneralEducationSites},
+	}
+	if len(s.feedsForConcept(conceptID, conceptName)) > 0 {
+		searchFunctions = append(searchFunctions, struct {
+			source string
+			search func(context.Context, string, string) ([]EducationalResource, error)
+		}{"feeds", s.searchFeeds})
 	}
 
 	for _, searchFunc := range searchFunctions {
+		if limit, ok := s.config.MaxResultsPerSource[searchFunc.source]; ok && limit <= 0 {
+			continue // source turned off
+		}
 		searchFunc := searchFunc // Capture for goroutine
 		g.Go(func() error {
-			resources, err := searchFunc(gCtx, conceptID, conceptName)
+			resources, err := searchFunc.search(gCtx, conceptID, conceptName)
 			if err != nil {
-				s.logger.Warn("Search function failed", zap.Error(err))
+				s.logger.Warn("Search function failed", zap.String("source", searchFunc.source), zap.Error(err))
+				report(ScrapeProgress{Concept: conceptName, Source: searchFunc.source, Error: err.Error()})
 				return nil // Don't fail the entire operation
 			}
 
@@ -293,6 +715,7 @@ func (s *EducationalWebScraper) scrapeResourcesForConcept(ctx context.Context, c
 			allResources = append(allResources, resources...)
 			mu.Unlock()
 
+			report(ScrapeProgress{Concept: conceptName, Source: searchFunc.source, Found: len(resources)})
 			return nil
 		})
 	}
@@ -302,25 +725,122 @@ func (s *EducationalWebScraper) scrapeResourcesForConcept(ctx context.Context, c
 	}
 
 	// Post-process resources
-	uniqueResources := s.deduplicateResources(allResources)
-	qualityResources := s.filterQualityResources(uniqueResources)
+	uniqueResources := s.deduplicateResources(ctx, allResources)
+	qualityResources := s.filterQualityResources(ctx, uniqueResources)
 
 	// Store in MongoDB
 	if len(qualityResources) > 0 {
+		s.ensureConceptNode(ctx, conceptID, conceptName)
+
 		if err := s.storeResources(ctx, qualityResources); err != nil {
 			s.logger.Error("Failed to store resources", zap.Error(err))
 			return err
 		}
 	}
 
-	s.logger.Info("Successfully scraped concept",
+	s.log(ctx).Info("Successfully scraped concept",
 		zap.String("concept", conceptName),
 		zap.Int("total_found", len(allResources)),
 		zap.Int("quality_stored", len(qualityResources)))
 
+	report(ScrapeProgress{Concept: conceptName, Found: len(qualityResources), Done: true})
 	return nil
 }
 
+// SetConceptResolver makes resources accrete under the graph's canonical concept ID,
+// so different phrasings of the same concept share one resource bucket
+func (s *EducationalWebScraper) SetConceptResolver(resolver ConceptResolver) {
+	s.conceptResolver = resolver
+}
+
+// ResolveConceptID returns the storage key for a concept name: the canonical graph ID
+// when the concept is known, otherwise the normalized name
+func (s *EducationalWebScraper) ResolveConceptID(ctx context.Context, conceptName string) string {
+	id, err := s.resolveConceptID(ctx, conceptName)
+	if err != nil {
+		s.logger.Warn("Failed to resolve concept ID, using normalized name",
+			zap.String("concept", conceptName),
+			zap.Error(err))
+		return s.generateConceptID(conceptName)
+	}
+	return id
+}
+
+// resolveConceptID is ResolveConceptID without the fallback on resolver errors, for
+// callers that must not store a normalized name when the graph was unreachable
+func (s *EducationalWebScraper) resolveConceptID(ctx context.Context, conceptName string) (string, error) {
+	if s.conceptResolver != nil {
+		id, err := s.conceptResolver.FindConceptID(ctx, conceptName)
+		if err != nil {
+			return "", err
+		}
+		if id != nil && *id != "" {
+			return *id, nil
+		}
+	}
+	return s.generateConceptID(conceptName), nil
+}
+
+// ensureConceptNode makes sure the concept resources are stored under exists in the graph,
+// so resources are never orphaned from it. Failures are logged and do not block storage.
+func (s *EducationalWebScraper) ensureConceptNode(ctx context.Context, conceptID, conceptName string) {
+	if !s.config.EnsureConceptNodes {
+		return
+	}
+	ensurer, ok := s.conceptResolver.(ConceptEnsurer)
+	if !ok {
+		return
+	}
+
+	created, err := ensurer.EnsureConcept(ctx, conceptID, conceptName)
+	if err != nil {
+		s.logger.Warn("Failed to ensure concept node",
+			zap.String("concept_id", conceptID),
+			zap.Error(err))
+		return
+	}
+	if created {
+		s.logger.Info("Created stub concept node for review",
+			zap.String("concept_id", conceptID),
+			zap.String("concept", conceptName))
+	}
+}
+
+// FilterConceptNames drops names that cannot be scraped and stored safely: blank names,
+// names whose generated concept ID has no letters or digits, and names whose ID
+// duplicates an earlier one. Order is preserved; skipped names are logged and returned.
+func (s *EducationalWebScraper) FilterConceptNames(conceptNames []string) (valid, skipped []string) {
+	seen := make(map[string]string, len(conceptNames))
+	for _, name := range conceptNames {
+		trimmed := strings.TrimSpace(name)
+		if trimmed == "" {
+			s.logger.Debug("Skipping blank concept name for scraping")
+			skipped = append(skipped, name)
+			continue
+		}
+
+		id := strings.Trim(s.generateConceptID(trimmed), "_")
+		if id == "" {
+			s.logger.Info("Skipping concept with empty normalized ID", zap.String("concept", name))
+			skipped = append(skipped, name)
+			continue
+		}
+
+		if first, ok := seen[id]; ok {
+			s.logger.Info("Skipping duplicate concept after normalization",
+				zap.String("concept", name),
+				zap.String("duplicate_of", first),
+				zap.String("concept_id", id))
+			skipped = append(skipped, name)
+			continue
+		}
+
+		seen[id] = name
+		valid = append(valid, trimmed)
+	}
+	return valid, skipped
+}
+
 // generateConceptID creates a standardized concept ID
 func (s *EducationalWebScraper) generateConceptID(conceptName string) string {
 	id := strings.ToLower(conceptName)
@@ -352,17 +872,25 @@ func (s *EducationalWebScraper) normalizeConceptForSearch(concept string) string
 // generateSearchTerms creates multiple search variations for better results
 func (s *EducationalWebScraper) generateSearchTerms(concept string) []string {
 	normalized := s.normalizeConceptForSearch(concept)
+	conceptID := s.generateConceptID(concept)
 
-	terms := []string{
-		normalized,                    // "Basic Functions"
-		normalized + " mathematics",   // "Basic Functions mathematics"
-		normalized + " math tutorial", // "Basic Functions math tutorial"
+	terms := []string{normalized} // "Basic Functions"
+
+	// Operator-configured templates take priority over the generic variations
+	templates := append(append([]string{}, s.config.SearchTermTemplates["*"]...), s.config.SearchTermTemplates[conceptID]...)
+	for _, template := range templates {
+		terms = append(terms, strings.ReplaceAll(template, "{concept}", normalized))
 	}
 
+	terms = append(terms,
+		normalized+" mathematics",   // "Basic Functions mathematics"
+		normalized+" math tutorial", // "Basic Functions math tutorial"
+	)
+
 	// Add variations for multi-word concepts
-	if strings.Contains(normalized, " ") {
+	if strings.Contains(normalized, " ") && !s.preserveStopWords[conceptID] {
 		// Remove common words that might confuse search
-		withoutCommon := regexp.MustCompile(`\b(Basic|Advanced|Elementary|Introduction|to|the|of|and|in)\b`).ReplaceAllString(normalized, "")
+		withoutCommon := s.stopWordPattern.ReplaceAllString(normalized, "")
 		withoutCommon = regexp.MustCompile(`\s+`).ReplaceAllString(strings.TrimSpace(withoutCommon), " ")
 
 		if withoutCommon != "" && withoutCommon != normalized {
@@ -373,16 +901,117 @@ func (s *EducationalWebScraper) generateSearchTerms(concept string) []string {
 	return terms
 }
 
-// isRecentlyScraped checks if a concept was scraped recently
-func (s *EducationalWebScraper) isRecentlyScraped(ctx context.Context, conceptID string) bool {
-	// Check if scraped within last 24 hours
-	since := time.Now().Add(-24 * time.Hour)
-	filter := bson.M{
-		"concept_id": conceptID,
-		"scraped_at": bson.M{"$gte": since},
+// RefreshConcept re-scrapes a concept regardless of when it was last scraped and
+// returns the number of quality resources stored
+func (s *EducationalWebScraper) RefreshConcept(ctx context.Context, conceptName string) (int, error) {
+	stored := 0
+	err := s.scrapeResourcesForConcept(ctx, conceptName, true, func(p ScrapeProgress) {
+		if p.Done {
+			stored = p.Found
+		}
+	})
+	return stored, err
+}
+
+// ScrapeAttempt is the most recent scheduled scrape of a concept, whatever it stored
+type ScrapeAttempt struct {
+	ConceptID   string    `bson:"_id"`
+	AttemptedAt time.Time `bson:"attempted_at"`
+	Stored      int       `bson:"stored"`
+	// EmptyStreak counts the consecutive attempts that stored nothing
+	EmptyStreak int `bson:"empty_streak"`
+}
+
+// RecordScrapeAttempt records that a concept was scraped now and stored stored resources
+func (s *EducationalWebScraper) RecordScrapeAttempt(ctx context.Context, conceptID string, stored int) error {
+	update := bson.M{"$set": bson.M{"attempted_at": s.now(), "stored": stored}}
+	if stored > 0 {
+		update["$set"].(bson.M)["empty_streak"] = 0
+	} else {
+		update["$inc"] = bson.M{"empty_streak": 1}
 	}
 
+	_, err := s.attempts.UpdateOne(ctx, bson.M{"_id": conceptID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to record scrape attempt: %w", err)
+	}
+	return nil
+}
+
+// LastScrapeAttempts returns the recorded scrape attempt of each concept ID; concepts
+// never attempted are absent from the result
+func (s *EducationalWebScraper) LastScrapeAttempts(ctx context.Context, conceptIDs []string) (map[string]ScrapeAttempt, error) {
+	cursor, err := s.attempts.Find(ctx, bson.M{"_id": bson.M{"$in": conceptIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scrape attempts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	attempts := make(map[string]ScrapeAttempt, len(conceptIDs))
+	for cursor.Next(ctx) {
+		var attempt ScrapeAttempt
+		if err := cursor.Decode(&attempt); err != nil {
+			continue
+		}
+		attempts[attempt.ConceptID] = attempt
+	}
+
+	return attempts, cursor.Err()
+}
+
+// LastScrapedAt returns the most recent scrape time of each concept ID that has stored
+// resources; concepts never scraped are absent from the result
+func (s *EducationalWebScraper) LastScrapedAt(ctx context.Context, conceptIDs []string) (map[string]time.Time, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"concept_id": bson.M{"$in": conceptIDs}}},
+		{"$group": bson.M{"_id": "$concept_id", "last_scraped": bson.M{"$max": "$scraped_at"}}},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last scrape times: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	lastScraped := make(map[string]time.Time, len(conceptIDs))
+	for cursor.Next(ctx) {
+		var result struct {
+			ConceptID   string    `bson:"_id"`
+			LastScraped time.Time `bson:"last_scraped"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			continue
+		}
+		lastScraped[result.ConceptID] = result.LastScraped
+	}
+
+	return lastScraped, cursor.Err()
+}
+
+// hasSufficientResources reports whether a concept already has at least
+// SufficientResourceCount resources scoring SufficientResourceQuality or more. A zero
+// count disables the check; a failed count lets the scrape go ahead.
+func (s *EducationalWebScraper) hasSufficientResources(ctx context.Context, conceptID string) (int64, bool) {
+	if s.config.SufficientResourceCount <= 0 {
+		return 0, false
+	}
+
+	filter := bson.M{
+		"concept_id":    conceptID,
+		"quality_score": bson.M{"$gte": s.config.SufficientResourceQuality},
+	}
 	count, err := s.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		s.logger.Warn("Failed to count existing resources", zap.Error(err))
+		return 0, false
+	}
+
+	return count, count >= int64(s.config.SufficientResourceCount)
+}
+
+// isRecentlyScraped checks if a concept was scraped within the last RescrapeInterval
+func (s *EducationalWebScraper) isRecentlyScraped(ctx context.Context, conceptID string) bool {
+	count, err := s.collection.CountDocuments(ctx, s.recentScrapeFilter(conceptID))
 	if err != nil {
 		s.logger.Warn("Failed to check recent scraping", zap.Error(err))
 		return false
@@ -391,7 +1020,15 @@ func (s *EducationalWebScraper) isRecentlyScraped(ctx context.Context, conceptID
 	return count > 0
 }
 
-// storeResources stores resources in MongoDB with upsert logic
+// recentScrapeFilter matches a concept's resources scraped within RescrapeInterval
+func (s *EducationalWebScraper) recentScrapeFilter(conceptID string) bson.M {
+	return bson.M{
+		"concept_id": conceptID,
+		"scraped_at": bson.M{"$gte": s.now().Add(-s.config.RescrapeInterval)},
+	}
+}
+
+// storeResources stores resources in MongoDB, upserting on (concept_id, url)
 func (s *EducationalWebScraper) storeResources(ctx context.Context, resources []EducationalResource) error {
 	if len(resources) == 0 {
 		return nil
@@ -399,9 +1036,12 @@ func (s *EducationalWebScraper) storeResources(ctx context.Context, resources []
 
 	// Use bulk write for efficiency
 	var writes []mongo.WriteModel
+	urls := make([]string, 0, len(resources))
 
 	for _, resource := range resources {
-		filter := bson.M{"url": resource.URL}
+		resource.URL = canonicalizeURL(resource.URL)
+		urls = append(urls, resource.URL)
+		filter := bson.M{"concept_id": resource.ConceptID, "url": resource.URL}
 		update := bson.M{"$set": resource}
 
 		upsert := mongo.NewUpdateOneModel().
@@ -412,29 +1052,158 @@ func (s *EducationalWebScraper) storeResources(ctx context.Context, resources []
 		writes = append(writes, upsert)
 	}
 
+	// Upserts are idempotent, so operations that failed transiently, or whose outcome is
+	// unknown, are safely resubmitted; operations that succeeded are not
 	opts := options.BulkWrite().SetOrdered(false)
-	result, err := s.collection.BulkWrite(ctx, writes, opts)
-	if err != nil {
-		return fmt.Errorf("bulk write failed: %w", err)
+	pending := writes
+	backoff := s.config.RetryDelay
+	var inserted, modified, upserted int64
+	retried, failed := 0, 0
+	var writeErr error
+
+	for attempt := 0; ; attempt++ {
+		result, err := s.collection.BulkWrite(ctx, pending, opts)
+		if result != nil {
+			inserted += result.InsertedCount
+			modified += result.ModifiedCount
+			upserted += result.UpsertedCount
+		}
+		if err == nil {
+			break
+		}
+
+		retry, permanent := classifyBulkWriteError(err, pending)
+		failed += permanent
+		if permanent > 0 {
+			writeErr = err
+		}
+		if len(retry) == 0 {
+			break
+		}
+		if attempt >= s.config.MaxRetries || ctx.Err() != nil {
+			failed += len(retry)
+			writeErr = err
+			break
+		}
+
+		retried += len(retry)
+		s.logger.Warn("Bulk write partially failed, retrying failed operations",
+			zap.Int("attempt", attempt+1),
+			zap.Int("retrying", len(retry)),
+			zap.Int("failed", permanent),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		if ctx.Err() != nil {
+			failed += len(retry)
+			writeErr = ctx.Err()
+			break
+		}
+		pending = retry
+		backoff *= 2
 	}
 
-	s.logger.Info("Stored resources in MongoDB",
-		zap.Int64("inserted", result.InsertedCount),
-		zap.Int64("modified", result.ModifiedCount),
-		zap.Int64("upserted", result.UpsertedCount))
+	s.log(ctx).Info("Stored resources in MongoDB",
+		zap.Int64("inserted", inserted),
+		zap.Int64("modified", modified),
+		zap.Int64("upserted", upserted),
+		zap.Int("retried", retried),
+		zap.Int("failed", failed))
 
+	// Re-apply demotion of reported resources that the refresh may have overwritten
+	if _, err := s.applyReportDemotion(ctx, urls); err != nil {
+		s.logger.Warn("Failed to re-apply report demotion", zap.Error(err))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("bulk write failed for %d of %d resources: %w", failed, len(writes), writeErr)
+	}
+
+	s.mirrorLearningResources(ctx, resources)
 	return nil
 }
 
-// GetResourcesForConcept retrieves stored resources for a concept
-func (s *EducationalWebScraper) GetResourcesForConcept(ctx context.Context, conceptID string, limit int) ([]EducationalResource, error) {
+// duplicateKeyCode is the server code of a duplicate key write error. An upsert racing
+// another writer for the same (concept_id, url) gets it; the resource is stored either way.
+const duplicateKeyCode = 11000
+
+// retryableWriteCodes are server codes of transient write errors: unreachable hosts,
+// network timeouts, shutdowns and primary step-downs
+var retryableWriteCodes = map[int]bool{
+	6: true, 7: true, 89: true, 91: true, 189: true, 262: true,
+	9001: true, 10107: true, 11600: true, 11602: true, 13435: true, 13436: true,
+}
+
+// classifyBulkWriteError splits the operations of a failed unordered bulk write into
+// those worth retrying and a count of those that failed permanently. Duplicate key
+// errors are neither.
+func classifyBulkWriteError(err error, models []mongo.WriteModel) ([]mongo.WriteModel, int) {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) {
+		// The whole batch failed, e.g. on a network error; its outcome is unknown
+		if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+			return models, 0
+		}
+		return nil, len(models)
+	}
+
+	var retry []mongo.WriteModel
+	permanent := 0
+	reported := make(map[int]bool, len(bulkErr.WriteErrors))
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Index < 0 || writeErr.Index >= len(models) {
+			continue
+		}
+		reported[writeErr.Index] = true
+		switch {
+		case writeErr.Code == duplicateKeyCode:
+		case retryableWriteCodes[writeErr.Code]:
+			retry = append(retry, models[writeErr.Index])
+		default:
+			permanent++
+		}
+	}
+
+	// Without write errors for them, the other operations' outcome is only uncertain
+	// when the batch as a whole hit a transient failure
+	if bulkErr.WriteConcernError != nil || bulkErr.HasErrorLabel("RetryableWriteError") ||
+		mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		for i, model := range models {
+			if !reported[i] {
+				retry = append(retry, model)
+			}
+		}
+	}
+
+	return retry, permanent
+}
+
+// GetResourcesForConcept retrieves stored resources for a concept, best ranked first
+// (see RankScore); verifiedOnly restricts them to verified sources
+func (s *EducationalWebScraper) GetResourcesForConcept(ctx context.Context, conceptID string, limit int, verifiedOnly bool) ([]EducationalResource, error) {
 	filter := bson.M{"concept_id": conceptID}
+	if verifiedOnly {
+		filter["is_verified"] = true
+	}
 
-	opts := options.Find().
-		SetSort(bson.D{{"quality_score", -1}}).
-		SetLimit(int64(limit))
+	pipeline := mongo.Pipeline{
+		{{"$match", filter}},
+		{{"$addFields", bson.M{"rank_score": bson.M{"$add": bson.A{
+			"$quality_score",
+			bson.M{"$cond": bson.A{"$is_verified", s.config.VerifiedBoost, 0}},
+		}}}}},
+		{{"$sort", bson.D{{"rank_score", -1}, {"quality_score", -1}}}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{"$limit", int64(limit)}})
+	}
+	pipeline = append(pipeline, bson.D{{"$project", bson.M{"rank_score": 0}}})
 
-	cursor, err := s.collection.Find(ctx, filter, opts)
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query resources: %w", err)
 	}
@@ -448,6 +1217,106 @@ func (s *EducationalWebScraper) GetResourcesForConcept(ctx context.Context, conc
 	return resources, nil
 }
 
+// GetResourceCounts counts stored resources per concept ID in a single aggregation;
+// every requested ID is present in the result, with zero when it has no resources
+func (s *EducationalWebScraper) GetResourceCounts(ctx context.Context, conceptIDs []string) (map[string]int64, error) {
+	counts := make(map[string]int64, len(conceptIDs))
+	for _, id := range conceptIDs {
+		counts[id] = 0
+	}
+	if len(conceptIDs) == 0 {
+		return counts, nil
+	}
+
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.M{"concept_id": bson.M{"$in": conceptIDs}}}},
+		{{"$group", bson.D{
+			{"_id", "$concept_id"},
+			{"count", bson.D{{"$sum", 1}}},
+		}}},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count resources: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ConceptID string `bson:"_id"`
+		Count     int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode resource counts: %w", err)
+	}
+
+	for _, row := range rows {
+		counts[row.ConceptID] = row.Count
+	}
+
+	return counts, nil
+}
+
+// UpdateResource applies a partial update of curator-editable fields to every stored
+// copy of the resource with the given URL, across all concepts it is associated with
+func (s *EducationalWebScraper) UpdateResource(ctx context.Context, resourceURL string, updates ResourceUpdate) error {
+	set := bson.M{}
+
+	if updates.Title != nil {
+		title := strings.TrimSpace(*updates.Title)
+		if title == "" {
+			return fmt.Errorf("%w: title cannot be empty", ErrInvalidResourceUpdate)
+		}
+		set["title"] = title
+	}
+	if updates.Description != nil {
+		set["description"] = strings.TrimSpace(*updates.Description)
+	}
+	if updates.DifficultyLevel != nil {
+		difficulty := strings.ToLower(strings.TrimSpace(*updates.DifficultyLevel))
+		if !allowedDifficultyLevels[difficulty] {
+			return fmt.Errorf("%w: difficulty must be one of beginner, intermediate, advanced", ErrInvalidResourceUpdate)
+		}
+		set["difficulty_level"] = difficulty
+	}
+	if updates.QualityScore != nil {
+		if *updates.QualityScore < 0 || *updates.QualityScore > 1 {
+			return fmt.Errorf("%w: quality score must be between 0 and 1", ErrInvalidResourceUpdate)
+		}
+		set["quality_score"] = *updates.QualityScore
+		// RescoreAllResources leaves curator-set scores alone
+		set[qualityOverriddenField] = true
+	}
+	if updates.Tags != nil {
+		set["tags"] = *updates.Tags
+	}
+	if updates.IsVerified != nil {
+		set["is_verified"] = *updates.IsVerified
+	}
+
+	if len(set) == 0 {
+		return fmt.Errorf("%w: no fields to update", ErrInvalidResourceUpdate)
+	}
+
+	result, err := s.collection.UpdateMany(ctx,
+		bson.M{"url": canonicalizeURL(resourceURL)},
+		bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("failed to update resource: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: %s", ErrResourceNotFound, resourceURL)
+	}
+
+	s.logger.Info("Updated resource",
+		zap.String("url", resourceURL),
+		zap.Int64("matched", result.MatchedCount),
+		zap.Int("fields", len(set)))
+
+	return nil
+}
+
 // GetResourceStats returns statistics about stored resources
 func (s *EducationalWebScraper) GetResourceStats(ctx context.Context) (map[string]interface{}, error) {
 	pipeline := mongo.Pipeline{
@@ -471,21 +1340,62 @@ func (s *EducationalWebScraper) GetResourceStats(ctx context.Context) (map[strin
 	}
 	defer cursor.Close(ctx)
 
-	var results []bson.M
+	var results []bson.M
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode results: %w", err)
+	}
+
+	if len(results) == 0 {
+		return map[string]interface{}{
+			"total_concepts":            0,
+			"total_resources":           0,
+			"avg_resources_per_concept": 0.0,
+			"avg_quality_score":         0.0,
+		}, nil
+	}
+
+	return results[0], nil
+}
+
+// GetDifficultyDistribution counts resources per difficulty level for a concept, or
+// across all concepts when conceptID is empty. Resources without a level count as "unknown".
+func (s *EducationalWebScraper) GetDifficultyDistribution(ctx context.Context, conceptID string) (map[string]int64, error) {
+	pipeline := mongo.Pipeline{}
+	if conceptID != "" {
+		pipeline = append(pipeline, bson.D{{"$match", bson.D{{"concept_id", conceptID}}}})
+	}
+	pipeline = append(pipeline, bson.D{{"$group", bson.D{
+		{"_id", bson.D{{"$ifNull", bson.A{"$difficulty_level", ""}}}},
+		{"count", bson.D{{"$sum", 1}}},
+	}}})
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("aggregation failed: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Level string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
 	if err := cursor.All(ctx, &results); err != nil {
 		return nil, fmt.Errorf("failed to decode results: %w", err)
 	}
 
-	if len(results) == 0 {
-		return map[string]interface{}{
-			"total_concepts":            0,
-			"total_resources":           0,
-			"avg_resources_per_concept": 0.0,
-			"avg_quality_score":         0.0,
-		}, nil
+	distribution := make(map[string]int64, len(allowedDifficultyLevels)+1)
+	for level := range allowedDifficultyLevels {
+		distribution[level] = 0
+	}
+	for _, result := range results {
+		level := result.Level
+		if level == "" {
+			level = "unknown"
+		}
+		distribution[level] += result.Count
 	}
 
-	return results[0], nil
+	return distribution, nil
 }
 
 // searchYouTube searches YouTube for educational videos
@@ -494,7 +1404,7 @@ func (s *EducationalWebScraper) searchYouTube(ctx context.Context, conceptID, co
 		return nil, err
 	}
 
-	s.logger.Info("Searching YouTube", zap.String("concept", conceptName))
+	s.log(ctx).Info("Searching YouTube", zap.String("concept", conceptName))
 
 	searchTerms := s.generateSearchTerms(conceptName)
 	var allResources []EducationalResource
@@ -504,13 +1414,33 @@ func (s *EducationalWebScraper) searchYouTube(ctx context.Context, conceptID, co
 			break
 		}
 
-		// Create shorter timeout for individual searches
-		searchCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		var (
+			resources []EducationalResource
+			err       error
+		)
+		if s.config.YouTubeAPIKey != "" {
+			// The Data API is the supported interface, so robots.txt does not apply
+			if err := s.waitForDomain(ctx, youtubeAPIBaseURL); err != nil {
+				return nil, err
+			}
 
-		searchURL := fmt.Sprintf("https://www.youtube.com/results?search_query=%s", url.QueryEscape(searchTerm))
+			searchCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+			resources, err = s.searchYouTubeAPI(searchCtx, searchTerm, conceptID, conceptName)
+			cancel()
+		} else {
+			searchURL := fmt.Sprintf("https://www.youtube.com/results?search_query=%s", url.QueryEscape(searchTerm))
+			if !s.allowedByRobots(ctx, searchURL) {
+				continue
+			}
+			if err := s.waitForDomain(ctx, searchURL); err != nil {
+				return nil, err
+			}
 
-		resources, err := s.scrapeYouTubeResults(searchCtx, searchURL, conceptID, conceptName)
-		cancel()
+			// Create shorter timeout for individual searches
+			searchCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+			resources, err = s.scrapeYouTubeResults(searchCtx, searchURL, conceptID, conceptName)
+			cancel()
+		}
 
 		if err != nil {
 			s.logger.Warn("YouTube search failed",
@@ -520,17 +1450,14 @@ func (s *EducationalWebScraper) searchYouTube(ctx context.Context, conceptID, co
 		}
 
 		allResources = append(allResources, resources...)
-
-		// Rate limiting between searches
-		time.Sleep(time.Second)
 	}
 
 	// Limit results and deduplicate
-	if len(allResources) > 5 {
-		allResources = allResources[:5]
+	if limit := s.maxResults("youtube_total"); len(allResources) > limit {
+		allResources = allResources[:limit]
 	}
 
-	return s.deduplicateResources(allResources), nil
+	return s.deduplicateResources(ctx, allResources), nil
 }
 
 // scrapeYouTubeResults scrapes YouTube search results page
@@ -541,7 +1468,7 @@ func (s *EducationalWebScraper) scrapeYouTubeResults(ctx context.Context, search
 	}
 	req.Header.Set("User-Agent", s.config.UserAgent)
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.doWithRetry(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -573,11 +1500,16 @@ func (s *EducationalWebScraper) scrapeYouTubeResults(ctx context.Context, search
 		}
 	})
 
-	videos := s.extractVideoInfoFromYouTubeData(ytInitialData)
+	return s.videoResources(s.extractVideoInfoFromYouTubeData(ytInitialData), conceptID, conceptName), nil
+}
+
+// videoResources maps the educational videos among a search's results to resources,
+// whichever way the results were fetched
+func (s *EducationalWebScraper) videoResources(videos []YouTubeVideoData, conceptID, conceptName string) []EducationalResource {
 	var resources []EducationalResource
 
 	for _, video := range videos {
-		if len(resources) >= 3 { // Limit results per search
+		if len(resources) >= s.maxResults("youtube") {
 			break
 		}
 
@@ -588,14 +1520,13 @@ func (s *EducationalWebScraper) scrapeYouTubeResults(ctx context.Context, search
 		resource := EducationalResource{
 			ConceptID:       conceptID,
 			ConceptName:     conceptName,
-			Title:           video.Title,
+			Title:           cleanText(video.Title),
 			URL:             fmt.Sprintf("https://www.youtube.com/watch?v=%s", video.VideoID),
-			Description:     s.truncateString(video.Description, 500),
+			Description:     s.truncateString(cleanText(video.Description), 500),
 			ResourceType:    "video",
 			SourceDomain:    "youtube.com",
 			DifficultyLevel: s.assessVideoDifficulty(video),
-			QualityScore:    s.calculateYouTubeQualityScore(video),
-			ContentPreview:  s.truncateString(video.Description, 200),
+			ContentPreview:  s.truncateString(cleanText(video.Description), 200),
 			ScrapedAt:       time.Now(),
 			Language:        "en",
 			Duration:        &video.Duration,
@@ -606,15 +1537,16 @@ func (s *EducationalWebScraper) scrapeYouTubeResults(ctx context.Context, search
 		}
 
 		if video.ViewCount != "" {
-			if viewCount := s.parseViewCount(video.ViewCount); viewCount > 0 {
+			if viewCount := parseViewCount(video.ViewCount); viewCount > 0 {
 				resource.ViewCount = &viewCount
 			}
 		}
+		resource.QualityScore = s.scorer.Score(resource, video)
 
 		resources = append(resources, resource)
 	}
 
-	return resources, nil
+	return resources
 }
 
 // extractVideoInfoFromYouTubeData extracts video information from YouTube's data
@@ -827,23 +1759,27 @@ func (s *EducationalWebScraper) isEducationalVideo(video YouTubeVideoData) bool
 	return hasEducationalKeywords || isEducationalChannel
 }
 
-// assessVideoDifficulty assesses video difficulty level
+// assessVideoDifficulty classifies a YouTube video from its title and description
 func (s *EducationalWebScraper) assessVideoDifficulty(video YouTubeVideoData) string {
-	content := strings.ToLower(fmt.Sprintf("%s %s", video.Title, video.Description))
+	return s.assessDifficulty(video.Title, video.Description, "intermediate")
+}
 
-	beginnerKeywords := []string{"intro", "basic", "beginner", "simple", "easy", "start", "fundamental"}
-	advancedKeywords := []string{"advanced", "complex", "graduate", "proof", "theorem", "rigorous"}
+// assessDifficulty classifies text as beginner, intermediate or advanced by counting
+// configured keywords. The text wins when it leans one way; on a tie the source's prior
+// (e.g. Khan Academy leans beginner, MathWorld leans advanced) decides.
+func (s *EducationalWebScraper) assessDifficulty(title, description, prior string) string {
+	content := strings.ToLower(title + " " + description)
 
 	beginnerScore := 0
-	for _, keyword := range beginnerKeywords {
-		if strings.Contains(content, keyword) {
+	for _, keyword := range s.config.BeginnerKeywords {
+		if strings.Contains(content, strings.ToLower(keyword)) {
 			beginnerScore++
 		}
 	}
 
 	advancedScore := 0
-	for _, keyword := range advancedKeywords {
-		if strings.Contains(content, keyword) {
+	for _, keyword := range s.config.AdvancedKeywords {
+		if strings.Contains(content, strings.ToLower(keyword)) {
 			advancedScore++
 		}
 	}
@@ -853,54 +1789,11 @@ func (s *EducationalWebScraper) assessVideoDifficulty(video YouTubeVideoData) st
 	} else if advancedScore > beginnerScore {
 		return "advanced"
 	}
-	return "intermediate"
-}
-
-// calculateYouTubeQualityScore calculates quality score for YouTube video
-func (s *EducationalWebScraper) calculateYouTubeQualityScore(video YouTubeVideoData) float64 {
-	score := 0.5 // Base score
-
-	// Channel reputation
-	channel := strings.ToLower(video.Channel)
-	reputableChannels := []string{
-		"khan academy", "patrickjmt", "professor leonard",
-		"organic chemistry tutor", "mathologer", "3blue1brown",
-	}
-
-	for _, reputableChannel := range reputableChannels {
-		if strings.Contains(channel, reputableChannel) {
-			score += 0.3
-			break
-		}
-	}
-
-	// Title quality
-	title := strings.ToLower(video.Title)
-	if len(video.Title) > 20 {
-		score += 0.1
-	}
-	if strings.Contains(title, "explained") || strings.Contains(title, "tutorial") {
-		score += 0.1
-	}
-
-	// Duration preference (10-30 minutes for tutorials)
-	if strings.Contains(video.Duration, "1") || strings.Contains(video.Duration, "2") {
-		score += 0.1
-	}
-
-	// View count (if available)
-	if viewCount := s.parseViewCount(video.ViewCount); viewCount > 10000 {
-		score += 0.1
-	}
-
-	if score > 1.0 {
-		return 1.0
-	}
-	return score
+	return prior
 }
 
 // parseViewCount parses view count string to integer
-func (s *EducationalWebScraper) parseViewCount(viewCountStr string) int64 {
+func parseViewCount(viewCountStr string) int64 {
 	if viewCountStr == "" {
 		return 0
 	}
@@ -962,9 +1855,15 @@ func (s *EducationalWebScraper) searchKhanAcademy(ctx context.Context, conceptID
 		return nil, err
 	}
 
-	s.logger.Info("Searching Khan Academy", zap.String("concept", conceptName))
+	s.log(ctx).Info("Searching Khan Academy", zap.String("concept", conceptName))
 
 	searchURL := fmt.Sprintf("https://www.khanacademy.org/search?search_again=1&page_search_query=%s", url.QueryEscape(conceptName))
+	if !s.allowedByRobots(ctx, searchURL) {
+		return nil, nil
+	}
+	if err := s.waitForDomain(ctx, searchURL); err != nil {
+		return nil, err
+	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
 	if err != nil {
@@ -972,7 +1871,7 @@ func (s *EducationalWebScraper) searchKhanAcademy(ctx context.Context, conceptID
 	}
 	req.Header.Set("User-Agent", s.config.UserAgent)
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.doWithRetry(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -991,7 +1890,7 @@ func (s *EducationalWebScraper) searchKhanAcademy(ctx context.Context, conceptID
 
 	// Parse Khan Academy results
 	doc.Find("a[href*='/']").Each(func(i int, sel *goquery.Selection) {
-		if len(resources) >= 3 {
+		if len(resources) >= s.maxResults("khan_academy") {
 			return
 		}
 
@@ -1000,15 +1899,18 @@ func (s *EducationalWebScraper) searchKhanAcademy(ctx context.Context, conceptID
 			return
 		}
 
-		title := strings.TrimSpace(sel.Text())
+		title := cleanText(sel.Text())
 		if title == "" {
 			if ariaLabel, exists := sel.Attr("aria-label"); exists {
-				title = ariaLabel
+				title = cleanText(ariaLabel)
 			}
 		}
 
 		if title != "" && len(title) > 10 {
-			fullURL := s.makeAbsoluteURL("https://www.khanacademy.org", href)
+			fullURL := s.makeAbsoluteURL(searchURL, href)
+			if fullURL == "" {
+				return
+			}
 
 			resource := EducationalResource{
 				ConceptID:       conceptID,
@@ -1018,7 +1920,7 @@ func (s *EducationalWebScraper) searchKhanAcademy(ctx context.Context, conceptID
 				Description:     fmt.Sprintf("Khan Academy lesson on %s", conceptName),
 				ResourceType:    "tutorial",
 				SourceDomain:    "khanacademy.org",
-				DifficultyLevel: "beginner",
+				DifficultyLevel: s.assessDifficulty(title, "", "beginner"),
 				QualityScore:    0.9, // Khan Academy is high quality
 				ContentPreview:  title,
 				ScrapedAt:       time.Now(),
@@ -1040,9 +1942,15 @@ func (s *EducationalWebScraper) searchMathWorld(ctx context.Context, conceptID,
 		return nil, err
 	}
 
-	s.logger.Info("Searching MathWorld", zap.String("concept", conceptName))
+	s.log(ctx).Info("Searching MathWorld", zap.String("concept", conceptName))
 
 	searchURL := fmt.Sprintf("https://mathworld.wolfram.com/search/?query=%s", url.QueryEscape(conceptName))
+	if !s.allowedByRobots(ctx, searchURL) {
+		return nil, nil
+	}
+	if err := s.waitForDomain(ctx, searchURL); err != nil {
+		return nil, err
+	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
 	if err != nil {
@@ -1050,7 +1958,7 @@ func (s *EducationalWebScraper) searchMathWorld(ctx context.Context, conceptID,
 	}
 	req.Header.Set("User-Agent", s.config.UserAgent)
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.doWithRetry(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -1069,7 +1977,7 @@ func (s *EducationalWebScraper) searchMathWorld(ctx context.Context, conceptID,
 
 	// Parse MathWorld results
 	doc.Find("a[href*='/topics/']").Each(func(i int, sel *goquery.Selection) {
-		if len(resources) >= 2 {
+		if len(resources) >= s.maxResults("mathworld") {
 			return
 		}
 
@@ -1078,9 +1986,12 @@ func (s *EducationalWebScraper) searchMathWorld(ctx context.Context, conceptID,
 			return
 		}
 
-		title := strings.TrimSpace(sel.Text())
+		title := cleanText(sel.Text())
 		if title != "" && len(title) > 5 {
-			fullURL := s.makeAbsoluteURL("https://mathworld.wolfram.com", href)
+			fullURL := s.makeAbsoluteURL(searchURL, href)
+			if fullURL == "" {
+				return
+			}
 
 			resource := EducationalResource{
 				ConceptID:       conceptID,
@@ -1090,7 +2001,7 @@ func (s *EducationalWebScraper) searchMathWorld(ctx context.Context, conceptID,
 				Description:     fmt.Sprintf("Mathematical definition and explanation of %s", conceptName),
 				ResourceType:    "reference",
 				SourceDomain:    "mathworld.wolfram.com",
-				DifficultyLevel: "intermediate",
+				DifficultyLevel: s.assessDifficulty(title, "", "advanced"),
 				QualityScore:    0.8,
 				ContentPreview:  title,
 				ScrapedAt:       time.Now(),
@@ -1106,13 +2017,211 @@ func (s *EducationalWebScraper) searchMathWorld(ctx context.Context, conceptID,
 	return resources, nil
 }
 
+// wikipediaSearchResponse is the part of a MediaWiki search-generator query response used
+type wikipediaSearchResponse struct {
+	Query struct {
+		Pages []struct {
+			Title   string `json:"title"`
+			Index   int    `json:"index"`
+			Extract string `json:"extract"`
+			FullURL string `json:"fullurl"`
+		} `json:"pages"`
+	} `json:"query"`
+}
+
+// searchWikipedia searches Wikipedia through the MediaWiki query API for resources, taking
+// each article's plain-text intro as its description
+func (s *EducationalWebScraper) searchWikipedia(ctx context.Context, conceptID, conceptName string) ([]EducationalResource, error) {
+	if err := s.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Searching Wikipedia", zap.String("concept", conceptName))
+
+	// A documented API rather than a crawled page, so robots.txt (which disallows /w/
+	// for crawlers) does not apply; the crawl delay still does
+	searchURL := fmt.Sprintf("https://en.wikipedia.org/w/api.php?action=query&generator=search&gsrsearch=%s&gsrlimit=%d&gsrnamespace=0"+
+		"&prop=extracts|info&exintro&explaintext&exlimit=max&inprop=url&format=json&formatversion=2",
+		url.QueryEscape(conceptName), s.maxResults("wikipedia"))
+	if err := s.waitForDomain(ctx, searchURL); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", s.config.UserAgent)
+
+	resp, err := s.doWithRetry(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Wikipedia returned status %d", resp.StatusCode)
+	}
+
+	var result wikipediaSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Wikipedia response: %w", err)
+	}
+
+	// Pages are not returned in search order; index is the search rank
+	pages := result.Query.Pages
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Index < pages[j].Index })
+
+	var resources []EducationalResource
+	for _, page := range pages {
+		if page.FullURL == "" {
+			continue
+		}
+		title := page.Title
+
+		description := s.truncateString(cleanText(page.Extract), 500)
+		if description == "" {
+			description = fmt.Sprintf("Encyclopedia article on %s", title)
+		}
+
+		resource := EducationalResource{
+			ConceptID:       conceptID,
+			ConceptName:     conceptName,
+			Title:           fmt.Sprintf("%s - Wikipedia", title),
+			URL:             page.FullURL,
+			Description:     description,
+			ResourceType:    "reference",
+			SourceDomain:    "en.wikipedia.org",
+			DifficultyLevel: s.assessDifficulty(title, description, "intermediate"),
+			QualityScore:    0.75,
+			ContentPreview:  s.truncateString(description, 200),
+			ScrapedAt:       time.Now(),
+			Language:        "en",
+			Tags:            []string{"wikipedia", "reference", "definition"},
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
+// stackExchangeSearchResponse is the part of a Stack Exchange API search response used
+type stackExchangeSearchResponse struct {
+	Items []struct {
+		Title        string   `json:"title"`
+		Link         string   `json:"link"`
+		Score        int64    `json:"score"`
+		ViewCount    int64    `json:"view_count"`
+		AnswerCount  int      `json:"answer_count"`
+		IsAnswered   bool     `json:"is_answered"`
+		Tags         []string `json:"tags"`
+		CreationDate int64    `json:"creation_date"`
+	} `json:"items"`
+	// Backoff is the number of seconds the API asks clients to wait before calling it again
+	Backoff int `json:"backoff"`
+}
+
+// searchMathStackExchange searches Math StackExchange through the Stack Exchange API for
+// discussion threads, highest voted first
+func (s *EducationalWebScraper) searchMathStackExchange(ctx context.Context, conceptID, conceptName string) ([]EducationalResource, error) {
+	if err := s.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Searching Math StackExchange", zap.String("concept", conceptName))
+
+	// A documented API, so robots.txt does not apply; the crawl delay (and any backoff
+	// the API asked for) still does
+	searchURL := fmt.Sprintf("https://api.stackexchange.com/2.3/search/advanced?site=math.stackexchange&q=%s&sort=votes&order=desc&pagesize=%d",
+		url.QueryEscape(conceptName), s.config.StackExchangeResults)
+	if err := s.waitForDomain(ctx, searchURL); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", s.config.UserAgent)
+
+	resp, err := s.doWithRetry(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Stack Exchange API returned status %d", resp.StatusCode)
+	}
+
+	var result stackExchangeSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Stack Exchange response: %w", err)
+	}
+
+	// Later calls in this batch wait out the requested backoff in waitForDomain
+	if result.Backoff > 0 {
+		s.logger.Info("Stack Exchange API requested backoff", zap.Int("seconds", result.Backoff))
+		s.domainLimiter.Backoff(searchURL, time.Duration(result.Backoff)*time.Second)
+	}
+
+	var resources []EducationalResource
+	for _, item := range result.Items {
+		if item.Link == "" || item.Title == "" {
+			continue
+		}
+
+		// Titles come HTML-escaped
+		title := cleanText(html.UnescapeString(item.Title))
+		description := fmt.Sprintf("Math StackExchange question with %d answer(s) and a score of %d", item.AnswerCount, item.Score)
+		if len(item.Tags) > 0 {
+			description += fmt.Sprintf(", tagged %s", strings.Join(item.Tags, ", "))
+		}
+
+		quality := 0.6
+		if item.IsAnswered {
+			quality = 0.7
+		}
+
+		viewCount := item.ViewCount
+		rating := float64(item.Score)
+		resource := EducationalResource{
+			ConceptID:       conceptID,
+			ConceptName:     conceptName,
+			Title:           fmt.Sprintf("%s - Math StackExchange", title),
+			URL:             item.Link,
+			Description:     description,
+			ResourceType:    "discussion",
+			SourceDomain:    "math.stackexchange.com",
+			DifficultyLevel: s.assessDifficulty(title, strings.Join(item.Tags, " "), "intermediate"),
+			QualityScore:    quality,
+			ContentPreview:  title,
+			ScrapedAt:       time.Now(),
+			Language:        "en",
+			ViewCount:       &viewCount,
+			Rating:          &rating,
+			Tags:            append([]string{"stackexchange", "discussion"}, item.Tags...),
+			IsVerified:      false,
+		}
+		if item.CreationDate > 0 {
+			published := time.Unix(item.CreationDate, 0)
+			resource.PublishedAt = &published
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
 // searchGeneralEducationSites searches other educational sites
 func (s *EducationalWebScraper) searchGeneralEducationSites(ctx context.Context, conceptID, conceptName string) ([]EducationalResource, error) {
 	if err := s.limiter.Wait(ctx); err != nil {
 		return nil, err
 	}
 
-	s.logger.Info("Searching general education sites", zap.String("concept", conceptName))
+	s.log(ctx).Info("Searching general education sites", zap.String("concept", conceptName))
 
 	sitesToSearch := []struct {
 		domain    string
@@ -1127,6 +2236,12 @@ func (s *EducationalWebScraper) searchGeneralEducationSites(ctx context.Context,
 
 	for _, site := range sitesToSearch {
 		searchURL := fmt.Sprintf(site.searchURL, url.QueryEscape(conceptName))
+		if !s.allowedByRobots(ctx, searchURL) {
+			continue
+		}
+		if err := s.waitForDomain(ctx, searchURL); err != nil {
+			return allResources, err
+		}
 
 		req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
 		if err != nil {
@@ -1135,7 +2250,7 @@ func (s *EducationalWebScraper) searchGeneralEducationSites(ctx context.Context,
 		}
 		req.Header.Set("User-Agent", s.config.UserAgent)
 
-		resp, err := s.httpClient.Do(req)
+		resp, err := s.doWithRetry(ctx, req)
 		if err != nil {
 			s.logger.Warn("Failed to search site", zap.String("site", site.domain), zap.Error(err))
 			continue
@@ -1159,7 +2274,7 @@ func (s *EducationalWebScraper) searchGeneralEducationSites(ctx context.Context,
 
 			// Generic parsing for educational content
 			doc.Find("a[href]").Each(func(i int, sel *goquery.Selection) {
-				if len(allResources) >= 4 { // Limit total results
+				if len(allResources) >= s.maxResults("general") { // Limit total results
 					return
 				}
 
@@ -1168,7 +2283,7 @@ func (s *EducationalWebScraper) searchGeneralEducationSites(ctx context.Context,
 					return
 				}
 
-				text := strings.TrimSpace(sel.Text())
+				text := cleanText(sel.Text())
 				if len(text) < 10 || len(text) > 200 {
 					return
 				}
@@ -1180,7 +2295,12 @@ func (s *EducationalWebScraper) searchGeneralEducationSites(ctx context.Context,
 					return
 				}
 
-				fullURL := s.makeAbsoluteURL(fmt.Sprintf("https://%s", site.domain), href)
+				// Search pages link out to ads and trackers; keep the site's own pages
+				// and those of known educational domains
+				fullURL := s.makeAbsoluteURL(searchURL, href)
+				if fullURL == "" || !sameHost(fullURL, searchURL) && !s.isEducationalDomain(fullURL) {
+					return
+				}
 
 				resource := EducationalResource{
 					ConceptID:       conceptID,
@@ -1190,7 +2310,7 @@ func (s *EducationalWebScraper) searchGeneralEducationSites(ctx context.Context,
 					Description:     fmt.Sprintf("Educational content about %s", conceptName),
 					ResourceType:    "article",
 					SourceDomain:    site.domain,
-					DifficultyLevel: "intermediate",
+					DifficultyLevel: s.assessDifficulty(text, "", "intermediate"),
 					QualityScore:    site.quality,
 					ContentPreview:  text,
 					ScrapedAt:       time.Now(),
@@ -1202,46 +2322,116 @@ func (s *EducationalWebScraper) searchGeneralEducationSites(ctx context.Context,
 				allResources = append(allResources, resource)
 			})
 		}()
-
-		// Rate limiting between sites
-		time.Sleep(time.Second)
 	}
 
 	return allResources, nil
 }
 
-// deduplicateResources removes duplicate resources based on URL
-func (s *EducationalWebScraper) deduplicateResources(resources []EducationalResource) []EducationalResource {
+// deduplicateResources removes duplicate resources based on their concept and canonical
+// URL, then near-duplicates whose titles are too similar to another of the same type
+func (s *EducationalWebScraper) deduplicateResources(ctx context.Context, resources []EducationalResource) []EducationalResource {
 	seen := make(map[string]bool)
 	var unique []EducationalResource
 
 	for _, resource := range resources {
-		if !seen[resource.URL] {
-			seen[resource.URL] = true
+		resource.URL = canonicalizeURL(resource.URL)
+		key := resource.ConceptID + "|" + resource.URL
+		if !seen[key] {
+			seen[key] = true
 			unique = append(unique, resource)
 		}
 	}
 
-	s.logger.Info("Deduplicated resources",
+	distinct := s.dropSimilarTitles(ctx, unique)
+
+	s.log(ctx).Info("Deduplicated resources",
 		zap.Int("original", len(resources)),
-		zap.Int("unique", len(unique)))
+		zap.Int("unique", len(unique)),
+		zap.Int("distinct", len(distinct)))
+
+	return distinct
+}
+
+// dropSimilarTitles keeps one resource out of each group of the same concept and type
+// whose titles are more similar than DuplicateTitleThreshold: the one with the higher
+// quality score, in the position of the first
+func (s *EducationalWebScraper) dropSimilarTitles(ctx context.Context, resources []EducationalResource) []EducationalResource {
+	var kept []EducationalResource
+	var keptTitles []string
+
+	for _, resource := range resources {
+		title := normalizeTitleForComparison(resource.Title)
+
+		duplicate := false
+		for i, other := range kept {
+			if other.ConceptID != resource.ConceptID || other.ResourceType != resource.ResourceType {
+				continue
+			}
+			if s.similarity(title, keptTitles[i]) <= s.config.DuplicateTitleThreshold {
+				continue
+			}
+
+			duplicate = true
+			s.log(ctx).Debug("Dropping near-duplicate resource",
+				zap.String("title", resource.Title),
+				zap.String("similar_to", other.Title))
+			if resource.QualityScore > other.QualityScore {
+				kept[i] = resource
+				keptTitles[i] = title
+			}
+			break
+		}
+
+		if !duplicate {
+			kept = append(kept, resource)
+			keptTitles = append(keptTitles, title)
+		}
+	}
+
+	return kept
+}
+
+// normalizeTitleForComparison lowercases a title and reduces it to its words, without
+// punctuation or articles, so similarity compares what the titles are about
+func normalizeTitleForComparison(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	kept := words[:0]
+	for _, word := range words {
+		switch word {
+		case "a", "an", "the":
+			continue
+		}
+		kept = append(kept, word)
+	}
+	return strings.Join(kept, " ")
+}
 
-	return unique
+// RankScore orders resources for display: the quality score, plus VerifiedBoost for
+// verified sources so they outrank comparable unverified ones. Stored quality scores
+// are left as scraped.
+func (s *EducationalWebScraper) RankScore(resource EducationalResource) float64 {
+	if resource.IsVerified {
+		return resource.QualityScore + s.config.VerifiedBoost
+	}
+	return resource.QualityScore
 }
 
 // filterQualityResources filters resources based on quality
-func (s *EducationalWebScraper) filterQualityResources(resources []EducationalResource) []EducationalResource {
+func (s *EducationalWebScraper) filterQualityResources(ctx context.Context, resources []EducationalResource) []EducationalResource {
 	var filtered []EducationalResource
 	conceptCounts := make(map[string]map[string]int) // concept_id -> resource_type -> count
 
-	// Sort by quality score descending
+	// Sort by rank score descending
 	sortedResources := make([]EducationalResource, len(resources))
 	copy(sortedResources, resources)
 
-	// Simple bubble sort by quality score (descending)
+	// Simple bubble sort by rank score (descending)
 	for i := 0; i < len(sortedResources)-1; i++ {
 		for j := 0; j < len(sortedResources)-i-1; j++ {
-			if sortedResources[j].QualityScore < sortedResources[j+1].QualityScore {
+			if s.RankScore(sortedResources[j]) < s.RankScore(sortedResources[j+1]) {
 				sortedResources[j], sortedResources[j+1] = sortedResources[j+1], sortedResources[j]
 			}
 		}
@@ -1249,7 +2439,7 @@ func (s *EducationalWebScraper) filterQualityResources(resources []EducationalRe
 
 	for _, resource := range sortedResources {
 		// Filter minimum quality threshold
-		if resource.QualityScore < 0.4 {
+		if resource.QualityScore < *s.config.MinQualityScore {
 			continue
 		}
 
@@ -1267,42 +2457,189 @@ func (s *EducationalWebScraper) filterQualityResources(resources []EducationalRe
 		for _, count := range counts {
 			totalCount += count
 		}
-		if totalCount >= 6 {
+		if totalCount >= s.config.MaxResourcesPerConcept {
 			continue
 		}
 
 		// Ensure diversity of resource types
-		if resourceType == "video" && counts["video"] >= 3 {
-			continue
-		}
-		if (resourceType == "article" || resourceType == "tutorial") && counts["article"]+counts["tutorial"] >= 3 {
-			continue
+		if group, limit, capped := s.resourceTypeCap(resourceType); capped {
+			groupCount := 0
+			for _, member := range group {
+				groupCount += counts[member]
+			}
+			if groupCount >= limit {
+				continue
+			}
 		}
 
 		filtered = append(filtered, resource)
 		counts[resourceType]++
 	}
 
-	s.logger.Info("Quality filtered resources",
+	s.log(ctx).Info("Quality filtered resources",
 		zap.Int("original", len(resources)),
 		zap.Int("filtered", len(filtered)))
 
 	return filtered
 }
 
+// resourceTypeCap returns the per-concept cap covering a resource type and the types
+// sharing it; capped is false for unlisted types when MaxResourcesPerOtherType is unset
+func (s *EducationalWebScraper) resourceTypeCap(resourceType string) (group []string, limit int, capped bool) {
+	for key, keyLimit := range s.config.MaxResourcesPerType {
+		members := strings.Split(key, "+")
+		for i, member := range members {
+			members[i] = strings.TrimSpace(member)
+		}
+		for _, member := range members {
+			if member == resourceType {
+				return members, keyLimit, true
+			}
+		}
+	}
+	if s.config.MaxResourcesPerOtherType > 0 {
+		return []string{resourceType}, s.config.MaxResourcesPerOtherType, true
+	}
+	return nil, 0, false
+}
+
+// maxResults is the most resources one search of a source returns
+func (s *EducationalWebScraper) maxResults(source string) int {
+	if limit, ok := s.config.MaxResultsPerSource[source]; ok {
+		return limit
+	}
+	return DefaultMaxResultsPerSource[source]
+}
+
+// isEducationalDomain reports whether rawURL is on one of the educational domains or
+// their subdomains
+func (s *EducationalWebScraper) isEducationalDomain(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := normalizeHost(parsed.Hostname())
+	for _, domain := range s.educationalDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameHost reports whether two URLs are on the same host, ignoring a leading "www."
+func sameHost(a, b string) bool {
+	parsedA, errA := url.Parse(a)
+	parsedB, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return normalizeHost(parsedA.Hostname()) == normalizeHost(parsedB.Hostname())
+}
+
+// log returns the scraper logger, limited to warnings and errors for scrapes started by
+// requests that request log sampling left out
+func (s *EducationalWebScraper) log(ctx context.Context) *zap.Logger {
+	return logger.Sampled(ctx, s.logger)
+}
+
 // Utility functions
 
-// makeAbsoluteURL makes a relative URL absolute
+// makeAbsoluteURL resolves an href against the URL of the page it was found on, without
+// its fragment. It returns "" for hrefs that are malformed, are not http(s) (mailto:,
+// javascript:), or only point back into the page itself.
 func (s *EducationalWebScraper) makeAbsoluteURL(baseURL, relativeURL string) string {
-	if strings.HasPrefix(relativeURL, "http") {
-		return relativeURL
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	ref, err := url.Parse(strings.TrimSpace(relativeURL))
+	if err != nil {
+		return ""
 	}
 
-	if strings.HasPrefix(relativeURL, "/") {
-		return baseURL + relativeURL
+	resolved := base.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" || resolved.Host == "" {
+		return ""
+	}
+
+	resolved.Fragment = ""
+	resolved.RawFragment = ""
+	if ref.Scheme == "" && ref.Host == "" && ref.Path == "" && ref.RawQuery == "" {
+		return "" // fragment-only or empty href
+	}
+	return resolved.String()
+}
+
+// trackingParams are query parameters that never change the target content
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "mc_cid": true,
+	"mc_eid": true, "ref": true, "ref_src": true, "si": true, "feature": true,
+	"ab_channel": true, "pp": true,
+}
+
+// canonicalizeURL normalizes a URL so equivalent forms share one storage key.
+// YouTube links (youtu.be, /embed/, /shorts/, watch?v=) collapse to
+// https://www.youtube.com/watch?v=<id>; other URLs get a lowercase host,
+// no default port, no fragment and no tracking parameters.
+func canonicalizeURL(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	port := parsed.Port()
+	scheme := strings.ToLower(parsed.Scheme)
+
+	if videoID := youTubeVideoID(host, parsed); videoID != "" {
+		return "https://www.youtube.com/watch?v=" + videoID
+	}
+
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		port = ""
+	}
+	if port != "" {
+		host = host + ":" + port
+	}
+
+	query := parsed.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") || trackingParams[strings.ToLower(key)] {
+			query.Del(key)
+		}
+	}
+
+	path := parsed.EscapedPath()
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	canonical := scheme + "://" + host + path
+	if encoded := query.Encode(); encoded != "" {
+		canonical += "?" + encoded
 	}
+	return canonical
+}
 
-	return baseURL + "/" + relativeURL
+// youTubeVideoID extracts the video ID from any of the common YouTube URL forms
+func youTubeVideoID(host string, parsed *url.URL) string {
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "www."), "m.")
+
+	switch host {
+	case "youtu.be":
+		return strings.Split(strings.Trim(parsed.Path, "/"), "/")[0]
+	case "youtube.com", "music.youtube.com", "youtube-nocookie.com":
+		if parsed.Path == "/watch" {
+			return parsed.Query().Get("v")
+		}
+		for _, prefix := range []string{"/embed/", "/shorts/", "/v/", "/live/"} {
+			if strings.HasPrefix(parsed.Path, prefix) {
+				return strings.Split(strings.TrimPrefix(parsed.Path, prefix), "/")[0]
+			}
+		}
+	}
+	return ""
 }
 
 // truncateString truncates a string to a maximum length
@@ -1313,7 +2650,12 @@ func (s *EducationalWebScraper) truncateString(str string, maxLength int) string
 
 	// Try to truncate at word boundary
 	if maxLength > 0 {
-		truncated := str[:maxLength]
+		// Back off to a rune boundary so multi-byte characters are never split
+		cut := maxLength
+		for cut > 0 && !utf8.RuneStart(str[cut]) {
+			cut--
+		}
+		truncated := str[:cut]
 		if lastSpace := strings.LastIndex(truncated, " "); lastSpace > maxLength/2 {
 			return truncated[:lastSpace] + "..."
 		}
@@ -1323,6 +2665,25 @@ func (s *EducationalWebScraper) truncateString(str string, maxLength int) string
 	return ""
 }
 
+// cleanText normalizes scraped text for storage: HTML entities are unescaped, control
+// characters become spaces, invisible format characters (zero-width spaces, BOMs) are
+// dropped, and whitespace runs collapse to a single space
+func cleanText(str string) string {
+	str = html.UnescapeString(str)
+	str = strings.Map(func(r rune) rune {
+		switch {
+		case r == utf8.RuneError:
+			return -1
+		case unicode.IsControl(r):
+			return ' '
+		case unicode.Is(unicode.Cf, r):
+			return -1
+		}
+		return r
+	}, str)
+	return strings.Join(strings.Fields(str), " ")
+}
+
 // similarity calculates simple string similarity (Jaccard similarity)
 func (s *EducationalWebScraper) similarity(str1, str2 string) float64 {
 	words1 := strings.Fields(str1)
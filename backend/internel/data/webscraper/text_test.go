@@ -0,0 +1,50 @@
+package scraper
+
+import "testing"
+
+func TestCleanText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"entities unescaped", "Limits &amp; Continuity &#8211; Part 1", "Limits & Continuity – Part 1"},
+		{"whitespace collapsed", "  Chain\n\n Rule\t Explained  ", "Chain Rule Explained"},
+		{"control characters become spaces", "Integrals\x00by\x07Parts", "Integrals by Parts"},
+		{"zero-width and BOM dropped", "\ufeffDeriv\u200batives", "Derivatives"},
+		{"invalid UTF-8 dropped", "Series\xff Tests", "Series Tests"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanText(tt.in); got != tt.want {
+				t.Errorf("cleanText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		max  int
+		want string
+	}{
+		{"short enough", "Limits", 10, "Limits"},
+		{"word boundary", "Limits and continuity", 15, "Limits and..."},
+		{"no late space", "Differentiation rules", 10, "Differenti..."},
+		{"never splits a rune", "aébc", 2, "a..."},
+		{"zero length", "Limits", 0, ""},
+	}
+
+	s := &EducationalWebScraper{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.truncateString(tt.in, tt.max); got != tt.want {
+				t.Errorf("truncateString(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package scraper
+
+import "testing"
+
+func TestCanonicalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"youtu.be short link", "https://youtu.be/abc123?t=42", "https://www.youtube.com/watch?v=abc123"},
+		{"embed", "https://www.youtube.com/embed/abc123", "https://www.youtube.com/watch?v=abc123"},
+		{"shorts", "https://m.youtube.com/shorts/abc123/", "https://www.youtube.com/watch?v=abc123"},
+		{"watch with extra params", "http://youtube.com/watch?v=abc123&feature=share&list=PL1", "https://www.youtube.com/watch?v=abc123"},
+		{"host case and default port", "HTTPS://Example.COM:443/Calculus/", "https://example.com/Calculus"},
+		{"non-default port kept", "http://example.com:8080/a", "http://example.com:8080/a"},
+		{"fragment and tracking params dropped", "https://example.com/a?utm_source=x&id=7&fbclid=y#intro", "https://example.com/a?id=7"},
+		{"root path kept", "https://example.com/", "https://example.com/"},
+		{"relative URL unchanged", "/wiki/Limit", "/wiki/Limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canonicalizeURL(tt.in); got != tt.want {
+				t.Errorf("canonicalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMakeAbsoluteURL(t *testing.T) {
+	const base = "https://www.example.edu/search/results?q=limits#top"
+	tests := []struct {
+		name string
+		href string
+		want string
+	}{
+		{"absolute", "https://other.org/calculus/limits", "https://other.org/calculus/limits"},
+		{"protocol-relative", "//cdn.example.org/notes.html", "https://cdn.example.org/notes.html"},
+		{"root-relative", "/courses/calculus", "https://www.example.edu/courses/calculus"},
+		{"path-relative", "page2", "https://www.example.edu/search/page2"},
+		{"query-only", "?q=derivatives", "https://www.example.edu/search/results?q=derivatives"},
+		{"fragment dropped", "/notes#section-2", "https://www.example.edu/notes"},
+		{"fragment-only", "#section-2", ""},
+		{"empty", "", ""},
+		{"mailto", "mailto:help@example.edu", ""},
+		{"javascript", "javascript:void(0)", ""},
+	}
+
+	s := &EducationalWebScraper{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.makeAbsoluteURL(base, tt.href); got != tt.want {
+				t.Errorf("makeAbsoluteURL(%q) = %q, want %q", tt.href, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneralSearchLinkFilter(t *testing.T) {
+	const searchURL = "https://www.mathsisfun.com/search/search.html?query=limits"
+	tests := []struct {
+		name string
+		link string
+		want bool
+	}{
+		{"same site", "https://mathsisfun.com/calculus/limits.html", true},
+		{"educational domain", "https://tutorial.math.lamar.edu/Classes/CalcI/Limits.aspx", true},
+		{"educational subdomain", "https://en.wikipedia.org/wiki/Limit_(mathematics)", true},
+		{"off-site ad", "https://ads.example.com/click?id=1", false},
+		{"lookalike domain", "https://notwikipedia.org/limits", false},
+	}
+
+	s := &EducationalWebScraper{educationalDomains: []string{"lamar.edu", "wikipedia.org"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameHost(tt.link, searchURL) || s.isEducationalDomain(tt.link); got != tt.want {
+				t.Errorf("kept %q = %v, want %v", tt.link, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// youtubeAPIBaseURL is the YouTube Data API v3
+const youtubeAPIBaseURL = "https://www.googleapis.com/youtube/v3"
+
+// youtubeAPISearchResults is how many videos one API search asks for at least; the page
+// scraper sees about as many before filtering
+const youtubeAPISearchResults = 10
+
+// youtubeSearchSize is how many videos to request for a search keeping up to limit of
+// them, within the API's page size of 50
+func youtubeSearchSize(limit int) int {
+	if limit < youtubeAPISearchResults {
+		return youtubeAPISearchResults
+	}
+	if limit > 50 {
+		return 50
+	}
+	return limit
+}
+
+// iso8601DurationPattern matches the video durations of the Data API, e.g. "PT1H2M3S"
+var iso8601DurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// youtubeAPIThumbnails are a video's thumbnails by size
+type youtubeAPIThumbnails struct {
+	Default *struct {
+		URL string `json:"url"`
+	} `json:"default"`
+	Medium *struct {
+		URL string `json:"url"`
+	} `json:"medium"`
+	High *struct {
+		URL string `json:"url"`
+	} `json:"high"`
+}
+
+// best returns the largest thumbnail available
+func (t youtubeAPIThumbnails) best() string {
+	switch {
+	case t.High != nil:
+		return t.High.URL
+	case t.Medium != nil:
+		return t.Medium.URL
+	case t.Default != nil:
+		return t.Default.URL
+	}
+	return ""
+}
+
+// youtubeAPISearchResponse is the part of a /search response used
+type youtubeAPISearchResponse struct {
+	Items []struct {
+		ID struct {
+			VideoID string `json:"videoId"`
+		} `json:"id"`
+	} `json:"items"`
+}
+
+// youtubeAPIVideosResponse is the part of a /videos response used
+type youtubeAPIVideosResponse struct {
+	Items []struct {
+		ID      string `json:"id"`
+		Snippet struct {
+			Title        string               `json:"title"`
+			Description  string               `json:"description"`
+			ChannelTitle string               `json:"channelTitle"`
+			PublishedAt  string               `json:"publishedAt"`
+			Thumbnails   youtubeAPIThumbnails `json:"thumbnails"`
+		} `json:"snippet"`
+		ContentDetails struct {
+			Duration string `json:"duration"`
+		} `json:"contentDetails"`
+		Statistics struct {
+			// The API encodes counts as strings
+			ViewCount string `json:"viewCount"`
+		} `json:"statistics"`
+	} `json:"items"`
+}
+
+// youtubeAPIError is the error body of the Data API
+type youtubeAPIError struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// searchYouTubeAPI searches YouTube through the Data API: /search finds the videos and
+// /videos adds their durations and view counts. Videos are mapped into the same
+// YouTubeVideoData the page scraper produces, so the resources are built and scored
+// the same way.
+func (s *EducationalWebScraper) searchYouTubeAPI(ctx context.Context, searchTerm, conceptID, conceptName string) ([]EducationalResource, error) {
+	params := url.Values{
+		"part":              {"id"},
+		"type":              {"video"},
+		"q":                 {searchTerm},
+		"maxResults":        {strconv.Itoa(youtubeSearchSize(s.maxResults("youtube")))},
+		"relevanceLanguage": {"en"},
+	}
+	var search youtubeAPISearchResponse
+	if err := s.callYouTubeAPI(ctx, "search", params, &search); err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, item := range search.Items {
+		if item.ID.VideoID != "" {
+			ids = append(ids, item.ID.VideoID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	params = url.Values{
+		"part": {"snippet,contentDetails,statistics"},
+		"id":   {strings.Join(ids, ",")},
+	}
+	var details youtubeAPIVideosResponse
+	if err := s.callYouTubeAPI(ctx, "videos", params, &details); err != nil {
+		return nil, err
+	}
+
+	// /videos does not promise the order of the ids, so keep the search ranking
+	byID := make(map[string]YouTubeVideoData, len(details.Items))
+	for _, item := range details.Items {
+		video := YouTubeVideoData{
+			VideoID: item.ID,
+			// Snippet text comes HTML-escaped
+			Title:         html.UnescapeString(item.Snippet.Title),
+			Description:   html.UnescapeString(item.Snippet.Description),
+			Duration:      spokenDuration(parseISO8601Duration(item.ContentDetails.Duration)),
+			Channel:       item.Snippet.ChannelTitle,
+			ThumbnailURL:  item.Snippet.Thumbnails.best(),
+			PublishedTime: item.Snippet.PublishedAt,
+		}
+		if item.Statistics.ViewCount != "" {
+			video.ViewCount = item.Statistics.ViewCount + " views"
+		}
+		byID[item.ID] = video
+	}
+
+	videos := make([]YouTubeVideoData, 0, len(byID))
+	for _, id := range ids {
+		if video, ok := byID[id]; ok && video.Title != "" {
+			videos = append(videos, video)
+		}
+	}
+
+	return s.videoResources(videos, conceptID, conceptName), nil
+}
+
+// callYouTubeAPI GETs a Data API endpoint and decodes the response into out. The key is
+// sent as a header so it never appears in a logged URL.
+func (s *EducationalWebScraper) callYouTubeAPI(ctx context.Context, endpoint string, params url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", youtubeAPIBaseURL+"/"+endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", s.config.UserAgent)
+	req.Header.Set("X-Goog-Api-Key", s.config.YouTubeAPIKey)
+
+	resp, err := s.doWithRetry(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr youtubeAPIError
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("YouTube Data API %s returned status %d: %s", endpoint, resp.StatusCode, apiErr.Error.Message)
+		}
+		return fmt.Errorf("YouTube Data API %s returned status %d", endpoint, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode YouTube Data API %s response: %w", endpoint, err)
+	}
+	return nil
+}
+
+// parseISO8601Duration parses a Data API duration such as "PT12M34S"; it returns 0
+// when unrecognized
+func parseISO8601Duration(value string) time.Duration {
+	match := iso8601DurationPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0
+	}
+
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var total time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, _ := strconv.Atoi(match[i+1])
+		total += time.Duration(n) * unit
+	}
+	return total
+}
+
+// spokenDuration formats a duration like the accessibility label of the results page
+// ("12 minutes, 34 seconds"), the form the page scraper stores
+func spokenDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, unit := range []struct {
+		name string
+		size time.Duration
+	}{{"hour", time.Hour}, {"minute", time.Minute}, {"second", time.Second}} {
+		n := int(d / unit.size)
+		d -= time.Duration(n) * unit.size
+		switch {
+		case n == 1:
+			parts = append(parts, "1 "+unit.name)
+		case n > 1:
+			parts = append(parts, fmt.Sprintf("%d %ss", n, unit.name))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
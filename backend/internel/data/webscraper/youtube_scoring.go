@@ -0,0 +1,137 @@
+package scraper
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// YouTubeScoringWeights tunes the default quality scorer. A video starts at BaseScore
+// and earns each bonus whose condition it meets; the total is capped at 1.0.
+type YouTubeScoringWeights struct {
+	BaseScore float64 `json:"base_score"`
+	// ChannelBonus is added for a video from one of the reputable channels
+	ChannelBonus float64 `json:"channel_bonus"`
+	// LongTitleBonus is added for titles longer than LongTitleLength characters
+	LongTitleBonus  float64 `json:"long_title_bonus"`
+	LongTitleLength int     `json:"long_title_length"`
+	// TutorialTitleBonus is added for titles containing "explained" or "tutorial"
+	TutorialTitleBonus float64 `json:"tutorial_title_bonus"`
+	// ViewBonus is added for videos with more than ViewThreshold views
+	ViewThreshold int64   `json:"view_threshold"`
+	ViewBonus     float64 `json:"view_bonus"`
+	// DurationBonus is added for videos between MinPreferredDuration and
+	// MaxPreferredDuration long
+	DurationBonus        float64       `json:"duration_bonus"`
+	MinPreferredDuration time.Duration `json:"min_preferred_duration"`
+	MaxPreferredDuration time.Duration `json:"max_preferred_duration"`
+}
+
+// DefaultYouTubeScoringWeights are used when no weights are configured
+var DefaultYouTubeScoringWeights = YouTubeScoringWeights{
+	BaseScore:            0.5,
+	ChannelBonus:         0.3,
+	LongTitleBonus:       0.1,
+	LongTitleLength:      20,
+	TutorialTitleBonus:   0.1,
+	ViewThreshold:        10000,
+	ViewBonus:            0.1,
+	DurationBonus:        0.1,
+	MinPreferredDuration: 10 * time.Minute,
+	MaxPreferredDuration: 30 * time.Minute,
+}
+
+// reputableChannels are matched case-insensitively as substrings of the channel name
+var reputableChannels = []string{
+	"khan academy", "patrickjmt", "professor leonard",
+	"organic chemistry tutor", "mathologer", "3blue1brown",
+}
+
+// QualityScorer computes the quality score (0-1) of a scraped YouTube video. resource is
+// the video as it will be stored, without its score; raw is the video as scraped.
+type QualityScorer interface {
+	Score(resource EducationalResource, raw YouTubeVideoData) float64
+}
+
+// defaultQualityScorer scores a video by channel reputation, title, length and views
+type defaultQualityScorer struct {
+	weights YouTubeScoringWeights
+}
+
+func (d defaultQualityScorer) Score(resource EducationalResource, raw YouTubeVideoData) float64 {
+	weights := d.weights
+	score := weights.BaseScore
+
+	// Channel reputation
+	channel := strings.ToLower(raw.Channel)
+	for _, reputableChannel := range reputableChannels {
+		if strings.Contains(channel, reputableChannel) {
+			score += weights.ChannelBonus
+			break
+		}
+	}
+
+	// Title quality
+	title := strings.ToLower(raw.Title)
+	if len(raw.Title) > weights.LongTitleLength {
+		score += weights.LongTitleBonus
+	}
+	if strings.Contains(title, "explained") || strings.Contains(title, "tutorial") {
+		score += weights.TutorialTitleBonus
+	}
+
+	// Duration preference (10-30 minutes for tutorials by default)
+	if duration := parseVideoDuration(raw.Duration); duration > 0 &&
+		duration >= weights.MinPreferredDuration && duration <= weights.MaxPreferredDuration {
+		score += weights.DurationBonus
+	}
+
+	// View count (if available)
+	if viewCount := parseViewCount(raw.ViewCount); viewCount > weights.ViewThreshold {
+		score += weights.ViewBonus
+	}
+
+	if score > 1.0 {
+		return 1.0
+	}
+	return score
+}
+
+// durationUnitPattern matches the parts of a spoken duration such as "12 minutes, 5 seconds"
+var durationUnitPattern = regexp.MustCompile(`(\d+)\s*(hour|minute|second)`)
+
+// parseVideoDuration parses a YouTube length as shown ("1:02:03", "12:34") or as its
+// accessibility label ("12 minutes, 34 seconds"); it returns 0 when unrecognized
+func parseVideoDuration(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if strings.Contains(value, ":") {
+		var total time.Duration
+		for _, part := range strings.Split(value, ":") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || n < 0 {
+				return 0
+			}
+			total = total*60 + time.Duration(n)*time.Second
+		}
+		return total
+	}
+
+	var total time.Duration
+	for _, match := range durationUnitPattern.FindAllStringSubmatch(strings.ToLower(value), -1) {
+		n, _ := strconv.Atoi(match[1])
+		switch match[2] {
+		case "hour":
+			total += time.Duration(n) * time.Hour
+		case "minute":
+			total += time.Duration(n) * time.Minute
+		case "second":
+			total += time.Duration(n) * time.Second
+		}
+	}
+	return total
+}
@@ -0,0 +1,97 @@
+package scraper
+
+import "testing"
+
+// fixedScorer is a QualityScorer returning score and recording what it was given
+type fixedScorer struct {
+	score float64
+	seen  []YouTubeVideoData
+}
+
+func (f *fixedScorer) Score(resource EducationalResource, raw YouTubeVideoData) float64 {
+	f.seen = append(f.seen, raw)
+	return f.score
+}
+
+func TestCustomScorerScoresVideos(t *testing.T) {
+	tests := []struct {
+		name   string
+		videos []YouTubeVideoData
+		want   []float64
+	}{
+		{
+			name: "every educational video is scored by the scorer",
+			videos: []YouTubeVideoData{
+				{VideoID: "a", Title: "Limits tutorial", Channel: "Khan Academy"},
+				{VideoID: "b", Title: "Derivatives lecture", Channel: "Someone"},
+			},
+			want: []float64{0.42, 0.42},
+		},
+		{
+			name:   "non-educational videos are not scored",
+			videos: []YouTubeVideoData{{VideoID: "c", Title: "Cat compilation", Channel: "Pets"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scorer := &fixedScorer{score: 0.42}
+			s := &EducationalWebScraper{scorer: scorer}
+
+			resources := s.videoResources(tt.videos, "limits", "Limits")
+			if len(resources) != len(tt.want) || len(scorer.seen) != len(tt.want) {
+				t.Fatalf("got %d resources and %d scorer calls, want %d", len(resources), len(scorer.seen), len(tt.want))
+			}
+			for i, resource := range resources {
+				if resource.QualityScore != tt.want[i] {
+					t.Errorf("resource %d score = %v, want %v", i, resource.QualityScore, tt.want[i])
+				}
+				if scorer.seen[i].VideoID != tt.videos[i].VideoID {
+					t.Errorf("scorer saw video %q, want %q", scorer.seen[i].VideoID, tt.videos[i].VideoID)
+				}
+			}
+		})
+	}
+}
+
+func TestRescoreVideoUsesScorer(t *testing.T) {
+	channel := "Khan Academy"
+	duration := "12:30"
+	views := int64(25000)
+
+	tests := []struct {
+		name    string
+		reports int
+		score   float64
+		want    float64
+	}{
+		{"scorer result kept", 0, 0.9, 0.9},
+		{"reported video capped", 3, 0.9, reportDemotedQualityScore},
+		{"low score below the cap kept", 3, 0.05, 0.05},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scorer := &fixedScorer{score: tt.score}
+			s := &EducationalWebScraper{scorer: scorer, config: ScraperConfig{ReportDemotionThreshold: 3}}
+
+			got := s.rescoreVideo(EducationalResource{
+				Title:         "Limits tutorial",
+				AuthorChannel: &channel,
+				Duration:      &duration,
+				ViewCount:     &views,
+				ReportCount:   tt.reports,
+			})
+			if got != tt.want {
+				t.Errorf("rescoreVideo() = %v, want %v", got, tt.want)
+			}
+			if len(scorer.seen) != 1 {
+				t.Fatalf("scorer called %d times, want 1", len(scorer.seen))
+			}
+			raw := scorer.seen[0]
+			if raw.Channel != channel || raw.Duration != duration || parseViewCount(raw.ViewCount) != views {
+				t.Errorf("scorer saw %+v", raw)
+			}
+		})
+	}
+}
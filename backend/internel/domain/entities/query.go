@@ -10,6 +10,12 @@ import (
 type Query struct {
 	ID                 string          `json:"id" bson:"_id"`
 	UserID             string          `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	SessionID          string          `json:"session_id,omitempty" bson:"session_id,omitempty"`
+	UserAgent          string          `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	IPAddress          string          `json:"ip_address,omitempty" bson:"ip_address,omitempty"`
+	AudienceLevel      string          `json:"audience_level,omitempty" bson:"audience_level,omitempty"`
+	OutputFormat       string          `json:"output_format,omitempty" bson:"output_format,omitempty"`
+	MaxTokens          int             `json:"max_tokens,omitempty" bson:"max_tokens,omitempty"`
 	Text               string          `json:"text" bson:"text"`
 	IdentifiedConcepts []string        `json:"identified_concepts" bson:"identified_concepts"`
 	PrerequisitePath   []types.Concept `json:"prerequisite_path" bson:"prerequisite_path"`
@@ -24,9 +30,16 @@ type Query struct {
 type QueryResponse struct {
 	Explanation      string   `json:"explanation" bson:"explanation"`
 	RetrievedContext []string `json:"retrieved_context" bson:"retrieved_context"`
-	LLMProvider      string   `json:"llm_provider" bson:"llm_provider"`
-	LLMModel         string   `json:"llm_model" bson:"llm_model"`
-	TokensUsed       int      `json:"tokens_used" bson:"tokens_used"`
+	// ContextSources parallels RetrievedContext; CitedContextIndices index into both
+	ContextSources      []types.ContextSource `json:"context_sources,omitempty" bson:"context_sources,omitempty"`
+	CitedContextIndices []int                 `json:"cited_context_indices,omitempty" bson:"cited_context_indices,omitempty"`
+	MaxOutputTokens     int                   `json:"max_output_tokens,omitempty" bson:"max_output_tokens,omitempty"`
+	Truncated           bool                  `json:"truncated,omitempty" bson:"truncated,omitempty"`
+	UnbalancedMath      bool                  `json:"unbalanced_math,omitempty" bson:"unbalanced_math,omitempty"`
+	FullExplanation     string                `json:"-" bson:"full_explanation,omitempty"`
+	LLMProvider         string                `json:"llm_provider" bson:"llm_provider"`
+	LLMModel            string                `json:"llm_model" bson:"llm_model"`
+	TokensUsed          int                   `json:"tokens_used" bson:"tokens_used"`
 }
 
 type QueryMetadata struct {
@@ -34,6 +47,27 @@ type QueryMetadata struct {
 	GraphHits       int              `json:"graph_hits" bson:"graph_hits"`
 	ProcessingSteps []ProcessingStep `json:"processing_steps" bson:"processing_steps"`
 	RequestID       string           `json:"request_id" bson:"request_id"`
+	// RetrievalStatus is succeeded, transient_failure (succeeded after retry) or degraded
+	RetrievalStatus   string `json:"retrieval_status,omitempty" bson:"retrieval_status,omitempty"`
+	RetrievalAttempts int    `json:"retrieval_attempts,omitempty" bson:"retrieval_attempts,omitempty"`
+	// ConceptSource is llm, or vector_fallback when concepts came from vector search chunks
+	ConceptSource string `json:"concept_source,omitempty" bson:"concept_source,omitempty"`
+	// LowCoverage is set when no retrieved chunk was relevant enough to ground the explanation
+	LowCoverage bool `json:"low_coverage,omitempty" bson:"low_coverage,omitempty"`
+	// StepRetries counts the retries of each pipeline step that needed one
+	StepRetries map[string]int `json:"step_retries,omitempty" bson:"step_retries,omitempty"`
+	// SuggestedPrerequisites are the LLM-suggested prerequisites of identified concepts the
+	// graph had none for, kept for curator review
+	SuggestedPrerequisites map[string][]string `json:"suggested_prerequisites,omitempty" bson:"suggested_prerequisites,omitempty"`
+	// Models maps each LLM operation the query ran (e.g. identify_concepts, explanation)
+	// to the model it ran on
+	Models map[string]string `json:"models,omitempty" bson:"models,omitempty"`
+	// ContextHash fingerprints the chunks the vector search returned for the identified
+	// concepts, so a cached explanation can be dropped once its grounding has changed;
+	// empty when retrieval failed
+	ContextHash string `json:"context_hash,omitempty" bson:"context_hash,omitempty"`
+	// UnresolvedConcepts are the identified concepts the knowledge graph does not have
+	UnresolvedConcepts []string `json:"unresolved_concepts,omitempty" bson:"unresolved_concepts,omitempty"`
 }
 
 type ProcessingStep struct {
@@ -43,6 +77,37 @@ type ProcessingStep struct {
 	Error    string        `json:"error,omitempty" bson:"error,omitempty"`
 }
 
+// Audience levels an explanation can be pitched at
+const (
+	AudienceMiddleSchool  = "middle_school"
+	AudienceHighSchool    = "high_school"
+	AudienceUndergraduate = "undergraduate"
+	AudienceGraduate      = "graduate"
+
+	DefaultAudienceLevel = AudienceUndergraduate
+)
+
+// IsValidAudienceLevel reports whether level is one of the supported audience levels
+func IsValidAudienceLevel(level string) bool {
+	switch level {
+	case AudienceMiddleSchool, AudienceHighSchool, AudienceUndergraduate, AudienceGraduate:
+		return true
+	}
+	return false
+}
+
+// Output formats an explanation can be rendered in
+const (
+	OutputFormatMarkdown = "markdown"
+
+	DefaultOutputFormat = OutputFormatMarkdown
+)
+
+// IsValidOutputFormat reports whether format is one of the supported output formats
+func IsValidOutputFormat(format string) bool {
+	return format == OutputFormatMarkdown
+}
+
 // Constructor functions
 func NewQuery(userID, text, requestID string) *Query {
 	return &Query{
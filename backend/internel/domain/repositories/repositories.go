@@ -2,18 +2,41 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"mathprereq/internel/domain/entities"
 	"mathprereq/internel/types"
 	"time"
 )
 
+// ErrConceptNotFound indicates a concept name could not be resolved in the knowledge graph
+var ErrConceptNotFound = errors.New("concept not found")
+
+// ErrBackendUnavailable is returned without contacting a backend whose circuit breaker
+// is open after repeated failures
+var ErrBackendUnavailable = errors.New("backend unavailable")
+
+// Errors returned by ConceptRepository.ExecuteReadQuery
+var (
+	ErrDiagnosticsDisabled = errors.New("diagnostic queries are disabled")
+	ErrWriteQuery          = errors.New("query is not read-only")
+	ErrInvalidQuery        = errors.New("invalid query")
+)
+
 type ConceptRepository interface {
 	FindByID(ctx context.Context, id string) (*types.Concept, error)
 	FindByName(ctx context.Context, name string) (*types.Concept, error)
+	ResolveIDs(ctx context.Context, names []string) (map[string]string, error)
 	GetAll(ctx context.Context) ([]types.Concept, error)
-	FindPrerequisitePath(ctx context.Context, targetConcepts []string) ([]types.Concept, error)
+	// FindPrerequisitePath also returns the target names not found in the graph
+	FindPrerequisitePath(ctx context.Context, targetConcepts []string) ([]types.Concept, []string, error)
+	GetPathGraph(ctx context.Context, targetConcepts []string) (*types.PathGraph, error)
 	GetConceptDetail(ctx context.Context, conceptID string) (*types.ConceptDetailResult, error)
+	GetNextConcepts(ctx context.Context, conceptID string, limit int) ([]types.Concept, error)
+	IsPrerequisiteOf(ctx context.Context, conceptA, conceptB string) (bool, []types.Concept, error)
+	FindWithoutDescription(ctx context.Context, limit int) ([]types.Concept, error)
+	SetDescriptionIfBlank(ctx context.Context, conceptID, description string) (bool, error)
 	GetStats(ctx context.Context) (*types.SystemStats, error)
+	ExecuteReadQuery(ctx context.Context, cypher string, params map[string]interface{}) ([]map[string]interface{}, error)
 	IsHealthy(ctx context.Context) bool
 }
 
@@ -21,11 +44,25 @@ type QueryRepository interface {
 	Save(ctx context.Context, query *entities.Query) error
 	FindByID(ctx context.Context, id string) (*entities.Query, error)
 	FindByUserID(ctx context.Context, userID string, limit int) ([]*entities.Query, error)
-	FindByConceptName(ctx context.Context, conceptName string) (*entities.Query, error)
+	FindByConceptName(ctx context.Context, conceptName, audienceLevel, outputFormat string) (*entities.Query, error)
+	// FindByPathConceptID lists the most recent successful queries whose prerequisite
+	// path contains the concept with the given ID, newest first
+	FindByPathConceptID(ctx context.Context, conceptID string, limit int) ([]*entities.Query, error)
 	GetAnalytics(ctx context.Context, filters AnalyticsFilter) (*QueryAnalytics, error)
 	GetPopularConcepts(ctx context.Context, limit int) ([]ConceptPopularity, error)
 	GetQueryTrends(ctx context.Context, days int) ([]QueryTrend, error)
+	GetUsage(ctx context.Context, since time.Time) ([]ModelUsage, error)
 	GetQueryStats(ctx context.Context) (*QueryStats, error)
+	GetRecentFailures(ctx context.Context, limit int) ([]FailedQuerySummary, error)
+	// GetPrerequisiteSuggestions lists the LLM-suggested prerequisites recorded on
+	// queries, most frequently suggested first
+	GetPrerequisiteSuggestions(ctx context.Context, limit int) ([]PrerequisiteSuggestion, error)
+	// GetCoStudiedConcepts lists the concepts identified alongside conceptName in
+	// successful queries, most frequent first, excluding conceptName itself
+	GetCoStudiedConcepts(ctx context.Context, conceptName string, limit int) ([]CoStudiedConcept, error)
+	// InvalidateCachedExplanations stops stored explanations from being served as cached
+	// answers; with names, only those identifying or passing through one of the concepts
+	InvalidateCachedExplanations(ctx context.Context, conceptNames []string) (int64, error)
 	IsHealthy(ctx context.Context) bool
 }
 
@@ -39,8 +76,15 @@ type ResourceRepository interface {
 
 type VectorRepository interface {
 	Search(ctx context.Context, query string, limit int) ([]types.VectorResult, error)
+	// BatchSearch returns results[i] for queries[i]; failed queries get empty results
+	BatchSearch(ctx context.Context, queries []string, limit int) ([][]types.VectorResult, error)
 	IsHealthy(ctx context.Context) bool
 	GetStats(ctx context.Context) (map[string]interface{}, error)
+	DeleteAll(ctx context.Context) error
+	AddContent(ctx context.Context, chunks []types.VectorContent) error
+	Count(ctx context.Context) (int64, error)
+	// CountByConcept returns chunk counts keyed by lowercased concept value
+	CountByConcept(ctx context.Context, concepts []string) (map[string]int64, error)
 }
 
 // Supporting types
@@ -49,7 +93,10 @@ type AnalyticsFilter struct {
 	EndTime   *time.Time
 	UserID    *string
 	Success   *bool
-	Limit     int
+	Provider  *string
+	// Text is a full-text search over the query text
+	Text  *string
+	Limit int
 }
 
 type QueryAnalytics struct {
@@ -66,9 +113,47 @@ type ConceptPopularity struct {
 }
 
 type QueryTrend struct {
-	Date        time.Time `json:"date"`
-	QueryCount  int64     `json:"query_count"`
-	SuccessRate float64   `json:"success_rate"`
+	Date            time.Time `json:"date"`
+	QueryCount      int64     `json:"query_count"`
+	SuccessRate     float64   `json:"success_rate"`
+	AvgResponseTime float64   `json:"avg_response_time_ms"`
+}
+
+// ModelUsage is the token usage of one LLM model on one day. Queries stored before
+// token tracking report zero tokens and are counted as untracked.
+type ModelUsage struct {
+	Date             time.Time `json:"date"`
+	Model            string    `json:"model"`
+	QueryCount       int64     `json:"query_count"`
+	UntrackedQueries int64     `json:"untracked_queries"`
+	TokensUsed       int64     `json:"tokens_used"`
+}
+
+// FailedQuerySummary describes one failed query. FailedStep is the last processing step
+// that did not succeed, empty if the failure happened outside a recorded step.
+type FailedQuerySummary struct {
+	ID           string    `json:"id"`
+	RequestID    string    `json:"request_id,omitempty"`
+	Text         string    `json:"text"`
+	Timestamp    time.Time `json:"timestamp"`
+	ErrorMessage string    `json:"error_message"`
+	FailedStep   string    `json:"failed_step,omitempty"`
+	StepError    string    `json:"step_error,omitempty"`
+}
+
+// PrerequisiteSuggestion is an LLM-suggested prerequisite edge awaiting curator review
+type PrerequisiteSuggestion struct {
+	Concept       string    `json:"concept"`
+	Prerequisite  string    `json:"prerequisite"`
+	Count         int64     `json:"count"`
+	LastSuggested time.Time `json:"last_suggested"`
+}
+
+// CoStudiedConcept is a concept and the number of successful queries that identified it
+// together with another concept
+type CoStudiedConcept struct {
+	ConceptName   string `json:"concept_name" bson:"_id"`
+	CoOccurrences int64  `json:"co_occurrences" bson:"count"`
 }
 
 type QueryStats struct {
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	scraper "mathprereq/internel/data/webscraper"
 	"mathprereq/internel/domain/entities"
 	"mathprereq/internel/domain/repositories"
@@ -9,23 +11,68 @@ import (
 	"time"
 )
 
+// ErrInvalidAudienceLevel is returned when a request names an unsupported audience level
+var ErrInvalidAudienceLevel = errors.New("invalid audience level")
+
+// ErrInvalidOutputFormat is returned when a request names an unsupported output format
+var ErrInvalidOutputFormat = errors.New("invalid output format")
+
+// ErrInvalidMaxTokens is returned when a request's token limit exceeds the configured ceiling
+var ErrInvalidMaxTokens = errors.New("invalid max tokens")
+
+// ErrUnknownConcept is returned when a concept name matches nothing in the graph
+var ErrUnknownConcept = errors.New("unknown concept")
+
+// UnknownConceptError carries the closest known concepts for a name that matched nothing
+type UnknownConceptError struct {
+	Concept     string
+	Suggestions []string
+}
+
+func (e *UnknownConceptError) Error() string {
+	return fmt.Sprintf("%s: %q", ErrUnknownConcept, e.Concept)
+}
+
+func (e *UnknownConceptError) Is(target error) bool {
+	return target == ErrUnknownConcept
+}
+
 type QueryService interface {
 	ProcessQuery(ctx context.Context, req *QueryRequest) (*QueryResult, error)
 	GetConceptDetail(ctx context.Context, conceptID string) (*types.ConceptDetailResult, error)
+	GetPathGraph(ctx context.Context, targetConcepts []string) (*types.PathGraph, error)
+	GetNextConcepts(ctx context.Context, conceptID string, limit int) ([]types.Concept, error)
+	GetMissingPrerequisites(ctx context.Context, targetConcept string, knownConcepts []string) ([]types.Concept, error)
+	GetStudyGuide(ctx context.Context, targetConcept string, resourcesPerConcept int) (*StudyGuide, error)
+	SearchConcepts(ctx context.Context, query string, limit int) ([]types.ConceptSearchResult, error)
+	CheckPrerequisiteRelationship(ctx context.Context, fromConcept, toConcept string) (*types.PrerequisiteRelationshipResult, error)
 	GetAllConcepts(ctx context.Context) ([]types.Concept, error)
 	GetQueryStats(ctx context.Context) (*repositories.QueryStats, error)
 	GetPopularConcepts(ctx context.Context, limit int) ([]repositories.ConceptPopularity, error)
 	GetQueryTrends(ctx context.Context, days int) ([]repositories.QueryTrend, error)
+	GetRecentFailures(ctx context.Context, limit int) ([]repositories.FailedQuerySummary, error)
+	GetPrerequisiteSuggestions(ctx context.Context, limit int) ([]repositories.PrerequisiteSuggestion, error)
+	GetCoStudiedConcepts(ctx context.Context, conceptName string, limit int) ([]repositories.CoStudiedConcept, error)
 	GetSystemStats(ctx context.Context) (*types.SystemStats, error)
+	GetStatsReport(ctx context.Context) (*StatsReport, error)
+	GetUsageSummary(ctx context.Context, since time.Time) (*UsageSummary, error)
 
 	// Resource-related methods for learning materials
 	GetResourcesForConcepts(ctx context.Context, conceptNames []string, limit int) ([]scraper.EducationalResource, error)
 
 	// Smart concept query - checks cache first, then processes if needed
-	SmartConceptQuery(ctx context.Context, conceptName, userID, requestID string) (*QueryResult, error)
+	SmartConceptQuery(ctx context.Context, conceptName, userID, requestID, audienceLevel, outputFormat string) (*QueryResult, error)
 
 	// Debug and maintenance methods
 	GetCachedConcepts(ctx context.Context, limit int) ([]entities.Query, error)
+	GenerateMissingConceptDescriptions(ctx context.Context, limit int) (*types.DescriptionFillResult, error)
+	RebuildVectorStore(ctx context.Context, content []types.VectorContent, triggeredBy string) (*types.VectorStoreRebuildResult, error)
+	InvalidateCaches(ctx context.Context, conceptID, triggeredBy string) (*types.CacheInvalidationResult, error)
+	GetVectorCoverageGaps(ctx context.Context) ([]string, error)
+	RunGraphDiagnosticQuery(ctx context.Context, cypher string, params map[string]interface{}, triggeredBy string) ([]map[string]interface{}, error)
+
+	// Drain waits for background work started by queries (saves, scrapes) to finish
+	Drain(ctx context.Context) error
 }
 
 type ResourceService interface {
@@ -37,6 +84,78 @@ type QueryRequest struct {
 	UserID    string `json:"user_id,omitempty" validate:"omitempty,uuid"`
 	Question  string `json:"question" validate:"required,min=3,max=1000"`
 	RequestID string `json:"request_id,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+	// AudienceLevel is one of middle_school, high_school, undergraduate (default) or graduate
+	AudienceLevel string `json:"audience_level,omitempty"`
+	// OutputFormat is the explanation format; markdown (default) is currently the only one
+	OutputFormat string `json:"output_format,omitempty"`
+	// MaxTokens lowers the explanation's output token limit, e.g. for a brief answer;
+	// it may not exceed the configured limit (0 uses the configured limit)
+	MaxTokens int `json:"max_tokens,omitempty"`
+
+	// Client metadata captured by the HTTP layer, never read from the request body
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+// StudyGuide is the prerequisite path to a concept as an ordered checklist, ending with
+// the concept itself, with the best resources for each step
+type StudyGuide struct {
+	TargetConcept types.Concept    `json:"target_concept"`
+	Steps         []StudyGuideStep `json:"steps"`
+}
+
+// StudyGuideStep is one concept of a study guide; Resources is empty when none are stored
+type StudyGuideStep struct {
+	Step      int                           `json:"step"`
+	Concept   types.Concept                 `json:"concept"`
+	Resources []scraper.EducationalResource `json:"resources"`
+}
+
+// StatsReport aggregates stats from every backend. A backend that failed or timed out
+// has no section and is listed in Errors instead; Partial is set when that happens.
+type StatsReport struct {
+	Graph       *types.SystemStats       `json:"graph,omitempty"`
+	VectorStore map[string]interface{}   `json:"vector_store,omitempty"`
+	Queries     *repositories.QueryStats `json:"queries,omitempty"`
+	Resources   map[string]interface{}   `json:"resources,omitempty"`
+	Errors      map[string]string        `json:"errors,omitempty"`
+	Partial     bool                     `json:"partial"`
+	GeneratedAt time.Time                `json:"generated_at"`
+}
+
+// UsageSummary reports LLM token usage and estimated cost since a point in time.
+// Cost is only estimated for models with a configured price; Priced is false if any
+// model with tokens has no price.
+type UsageSummary struct {
+	Since            time.Time        `json:"since"`
+	Currency         string           `json:"currency"`
+	TotalQueries     int64            `json:"total_queries"`
+	UntrackedQueries int64            `json:"untracked_queries"`
+	TotalTokens      int64            `json:"total_tokens"`
+	EstimatedCost    float64          `json:"estimated_cost"`
+	Priced           bool             `json:"priced"`
+	ByModel          []ModelUsageCost `json:"by_model"`
+	Daily            []DailyUsage     `json:"daily"`
+}
+
+// ModelUsageCost is the usage of a single model over the summary period
+type ModelUsageCost struct {
+	Model            string  `json:"model"`
+	QueryCount       int64   `json:"query_count"`
+	UntrackedQueries int64   `json:"untracked_queries"`
+	TokensUsed       int64   `json:"tokens_used"`
+	EstimatedCost    float64 `json:"estimated_cost"`
+	Priced           bool    `json:"priced"`
+}
+
+// DailyUsage is the usage across all models on one day
+type DailyUsage struct {
+	Date             time.Time `json:"date"`
+	QueryCount       int64     `json:"query_count"`
+	UntrackedQueries int64     `json:"untracked_queries"`
+	TokensUsed       int64     `json:"tokens_used"`
+	EstimatedCost    float64   `json:"estimated_cost"`
 }
 
 type QueryResult struct {
@@ -45,8 +164,25 @@ type QueryResult struct {
 	PrerequisitePath   []types.Concept `json:"prerequisite_path"`
 	Explanation        string          `json:"explanation"`
 	RetrievedContext   []string        `json:"retrieved_context"`
-	ProcessingTime     time.Duration   `json:"processing_time"`
-	RequestID          string          `json:"request_id"`
+	// ExplanationBlocks is Explanation split into text and LaTeX math segments;
+	// UnbalancedMath is set when a math delimiter was left unclosed
+	ExplanationBlocks []types.ExplanationBlock `json:"explanation_blocks"`
+	UnbalancedMath    bool                     `json:"unbalanced_math"`
+	// ContextSources describes each RetrievedContext chunk; CitedContextIndices are the
+	// positions of the chunks the explanation says it used
+	ContextSources      []types.ContextSource `json:"context_sources"`
+	CitedContextIndices []int                 `json:"cited_context_indices"`
+	ProcessingTime      time.Duration         `json:"processing_time"`
+	RequestID           string                `json:"request_id"`
+	// Degraded is set when concepts were derived from the vector store because the LLM
+	// could not identify them, or when no relevant context grounded the explanation
+	Degraded bool `json:"degraded"`
+	// SuggestedPrerequisites are model-suggested, not graph-verified, prerequisites of
+	// identified concepts the graph has none for
+	SuggestedPrerequisites map[string][]string `json:"suggested_prerequisites,omitempty"`
+	// UnresolvedConcepts are the identified concepts not in the knowledge graph, so
+	// clients can say they are not covered yet
+	UnresolvedConcepts []string `json:"unresolved_concepts,omitempty"`
 }
 
 type ResourceRequest struct {
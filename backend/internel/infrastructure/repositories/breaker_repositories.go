@@ -0,0 +1,455 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mathprereq/internel/domain/entities"
+	"mathprereq/internel/domain/repositories"
+	"mathprereq/internel/types"
+	"mathprereq/pkg/breaker"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IsBackendFailure reports whether a repository error counts against its backend's
+// circuit breaker: cancelled calls, calls that outlived the caller's own deadline and
+// errors about the request itself (unknown concept or document, rejected query) say
+// nothing about the backend's health
+func IsBackendFailure(err error) bool {
+	var done *callerDoneError
+	switch {
+	case errors.As(err, &done),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, mongo.ErrNoDocuments),
+		errors.Is(err, repositories.ErrConceptNotFound),
+		errors.Is(err, repositories.ErrDiagnosticsDisabled),
+		errors.Is(err, repositories.ErrWriteQuery),
+		errors.Is(err, repositories.ErrInvalidQuery):
+		return false
+	}
+	return true
+}
+
+// callerDoneError marks an error returned after the caller's context ended, so a
+// deadline the caller chose is not blamed on the backend
+type callerDoneError struct {
+	err error
+}
+
+func (e *callerDoneError) Error() string { return e.err.Error() }
+func (e *callerDoneError) Unwrap() error { return e.err }
+
+// guard runs fn through the breaker, translating an open breaker into the domain sentinel
+func guard(ctx context.Context, b *breaker.Breaker, fn func() error) error {
+	err := b.Do(func() error {
+		err := fn()
+		if err != nil && ctx.Err() != nil {
+			return &callerDoneError{err: err}
+		}
+		return err
+	})
+
+	var done *callerDoneError
+	switch {
+	case errors.As(err, &done):
+		return done.err
+	case errors.Is(err, breaker.ErrOpen):
+		return fmt.Errorf("%w: %s circuit breaker is open", repositories.ErrBackendUnavailable, b.Name())
+	}
+	return err
+}
+
+// breakerConceptRepository fails fast while the graph backend's breaker is open.
+// IsHealthy bypasses the breaker so health checks report the backend itself.
+type breakerConceptRepository struct {
+	next    repositories.ConceptRepository
+	breaker *breaker.Breaker
+}
+
+// NewBreakerConceptRepository wraps a concept repository in a circuit breaker
+func NewBreakerConceptRepository(next repositories.ConceptRepository, b *breaker.Breaker) repositories.ConceptRepository {
+	return &breakerConceptRepository{next: next, breaker: b}
+}
+
+func (r *breakerConceptRepository) FindByID(ctx context.Context, id string) (*types.Concept, error) {
+	var concept *types.Concept
+	err := guard(ctx, r.breaker, func() (err error) {
+		concept, err = r.next.FindByID(ctx, id)
+		return err
+	})
+	return concept, err
+}
+
+func (r *breakerConceptRepository) FindByName(ctx context.Context, name string) (*types.Concept, error) {
+	var concept *types.Concept
+	err := guard(ctx, r.breaker, func() (err error) {
+		concept, err = r.next.FindByName(ctx, name)
+		return err
+	})
+	return concept, err
+}
+
+func (r *breakerConceptRepository) ResolveIDs(ctx context.Context, names []string) (map[string]string, error) {
+	var ids map[string]string
+	err := guard(ctx, r.breaker, func() (err error) {
+		ids, err = r.next.ResolveIDs(ctx, names)
+		return err
+	})
+	return ids, err
+}
+
+func (r *breakerConceptRepository) GetAll(ctx context.Context) ([]types.Concept, error) {
+	var concepts []types.Concept
+	err := guard(ctx, r.breaker, func() (err error) {
+		concepts, err = r.next.GetAll(ctx)
+		return err
+	})
+	return concepts, err
+}
+
+func (r *breakerConceptRepository) FindPrerequisitePath(ctx context.Context, targetConcepts []string) ([]types.Concept, []string, error) {
+	var concepts []types.Concept
+	var unresolved []string
+	err := guard(ctx, r.breaker, func() (err error) {
+		concepts, unresolved, err = r.next.FindPrerequisitePath(ctx, targetConcepts)
+		return err
+	})
+	return concepts, unresolved, err
+}
+
+func (r *breakerConceptRepository) GetPathGraph(ctx context.Context, targetConcepts []string) (*types.PathGraph, error) {
+	var graph *types.PathGraph
+	err := guard(ctx, r.breaker, func() (err error) {
+		graph, err = r.next.GetPathGraph(ctx, targetConcepts)
+		return err
+	})
+	return graph, err
+}
+
+func (r *breakerConceptRepository) GetConceptDetail(ctx context.Context, conceptID string) (*types.ConceptDetailResult, error) {
+	var detail *types.ConceptDetailResult
+	err := guard(ctx, r.breaker, func() (err error) {
+		detail, err = r.next.GetConceptDetail(ctx, conceptID)
+		return err
+	})
+	return detail, err
+}
+
+func (r *breakerConceptRepository) GetNextConcepts(ctx context.Context, conceptID string, limit int) ([]types.Concept, error) {
+	var concepts []types.Concept
+	err := guard(ctx, r.breaker, func() (err error) {
+		concepts, err = r.next.GetNextConcepts(ctx, conceptID, limit)
+		return err
+	})
+	return concepts, err
+}
+
+func (r *breakerConceptRepository) IsPrerequisiteOf(ctx context.Context, conceptA, conceptB string) (bool, []types.Concept, error) {
+	var (
+		isPrerequisite bool
+		path           []types.Concept
+	)
+	err := guard(ctx, r.breaker, func() (err error) {
+		isPrerequisite, path, err = r.next.IsPrerequisiteOf(ctx, conceptA, conceptB)
+		return err
+	})
+	return isPrerequisite, path, err
+}
+
+func (r *breakerConceptRepository) FindWithoutDescription(ctx context.Context, limit int) ([]types.Concept, error) {
+	var concepts []types.Concept
+	err := guard(ctx, r.breaker, func() (err error) {
+		concepts, err = r.next.FindWithoutDescription(ctx, limit)
+		return err
+	})
+	return concepts, err
+}
+
+func (r *breakerConceptRepository) SetDescriptionIfBlank(ctx context.Context, conceptID, description string) (bool, error) {
+	var updated bool
+	err := guard(ctx, r.breaker, func() (err error) {
+		updated, err = r.next.SetDescriptionIfBlank(ctx, conceptID, description)
+		return err
+	})
+	return updated, err
+}
+
+func (r *breakerConceptRepository) GetStats(ctx context.Context) (*types.SystemStats, error) {
+	var stats *types.SystemStats
+	err := guard(ctx, r.breaker, func() (err error) {
+		stats, err = r.next.GetStats(ctx)
+		return err
+	})
+	return stats, err
+}
+
+func (r *breakerConceptRepository) ExecuteReadQuery(ctx context.Context, cypher string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	err := guard(ctx, r.breaker, func() (err error) {
+		rows, err = r.next.ExecuteReadQuery(ctx, cypher, params)
+		return err
+	})
+	return rows, err
+}
+
+func (r *breakerConceptRepository) IsHealthy(ctx context.Context) bool {
+	return r.next.IsHealthy(ctx)
+}
+
+// breakerQueryRepository fails fast while the document store's breaker is open
+type breakerQueryRepository struct {
+	next    repositories.QueryRepository
+	breaker *breaker.Breaker
+}
+
+// NewBreakerQueryRepository wraps a query repository in a circuit breaker
+func NewBreakerQueryRepository(next repositories.QueryRepository, b *breaker.Breaker) repositories.QueryRepository {
+	return &breakerQueryRepository{next: next, breaker: b}
+}
+
+func (r *breakerQueryRepository) Save(ctx context.Context, query *entities.Query) error {
+	return guard(ctx, r.breaker, func() error {
+		return r.next.Save(ctx, query)
+	})
+}
+
+func (r *breakerQueryRepository) FindByID(ctx context.Context, id string) (*entities.Query, error) {
+	var query *entities.Query
+	err := guard(ctx, r.breaker, func() (err error) {
+		query, err = r.next.FindByID(ctx, id)
+		return err
+	})
+	return query, err
+}
+
+func (r *breakerQueryRepository) FindByUserID(ctx context.Context, userID string, limit int) ([]*entities.Query, error) {
+	var queries []*entities.Query
+	err := guard(ctx, r.breaker, func() (err error) {
+		queries, err = r.next.FindByUserID(ctx, userID, limit)
+		return err
+	})
+	return queries, err
+}
+
+func (r *breakerQueryRepository) FindByConceptName(ctx context.Context, conceptName, audienceLevel, outputFormat string) (*entities.Query, error) {
+	var query *entities.Query
+	err := guard(ctx, r.breaker, func() (err error) {
+		query, err = r.next.FindByConceptName(ctx, conceptName, audienceLevel, outputFormat)
+		return err
+	})
+	return query, err
+}
+
+func (r *breakerQueryRepository) FindByPathConceptID(ctx context.Context, conceptID string, limit int) ([]*entities.Query, error) {
+	var queries []*entities.Query
+	err := guard(ctx, r.breaker, func() (err error) {
+		queries, err = r.next.FindByPathConceptID(ctx, conceptID, limit)
+		return err
+	})
+	return queries, err
+}
+
+func (r *breakerQueryRepository) GetAnalytics(ctx context.Context, filters repositories.AnalyticsFilter) (*repositories.QueryAnalytics, error) {
+	var analytics *repositories.QueryAnalytics
+	err := guard(ctx, r.breaker, func() (err error) {
+		analytics, err = r.next.GetAnalytics(ctx, filters)
+		return err
+	})
+	return analytics, err
+}
+
+func (r *breakerQueryRepository) GetPopularConcepts(ctx context.Context, limit int) ([]repositories.ConceptPopularity, error) {
+	var concepts []repositories.ConceptPopularity
+	err := guard(ctx, r.breaker, func() (err error) {
+		concepts, err = r.next.GetPopularConcepts(ctx, limit)
+		return err
+	})
+	return concepts, err
+}
+
+func (r *breakerQueryRepository) GetQueryTrends(ctx context.Context, days int) ([]repositories.QueryTrend, error) {
+	var trends []repositories.QueryTrend
+	err := guard(ctx, r.breaker, func() (err error) {
+		trends, err = r.next.GetQueryTrends(ctx, days)
+		return err
+	})
+	return trends, err
+}
+
+func (r *breakerQueryRepository) GetUsage(ctx context.Context, since time.Time) ([]repositories.ModelUsage, error) {
+	var usage []repositories.ModelUsage
+	err := guard(ctx, r.breaker, func() (err error) {
+		usage, err = r.next.GetUsage(ctx, since)
+		return err
+	})
+	return usage, err
+}
+
+func (r *breakerQueryRepository) GetQueryStats(ctx context.Context) (*repositories.QueryStats, error) {
+	var stats *repositories.QueryStats
+	err := guard(ctx, r.breaker, func() (err error) {
+		stats, err = r.next.GetQueryStats(ctx)
+		return err
+	})
+	return stats, err
+}
+
+func (r *breakerQueryRepository) GetRecentFailures(ctx context.Context, limit int) ([]repositories.FailedQuerySummary, error) {
+	var failures []repositories.FailedQuerySummary
+	err := guard(ctx, r.breaker, func() (err error) {
+		failures, err = r.next.GetRecentFailures(ctx, limit)
+		return err
+	})
+	return failures, err
+}
+
+func (r *breakerQueryRepository) GetPrerequisiteSuggestions(ctx context.Context, limit int) ([]repositories.PrerequisiteSuggestion, error) {
+	var suggestions []repositories.PrerequisiteSuggestion
+	err := guard(ctx, r.breaker, func() (err error) {
+		suggestions, err = r.next.GetPrerequisiteSuggestions(ctx, limit)
+		return err
+	})
+	return suggestions, err
+}
+
+func (r *breakerQueryRepository) GetCoStudiedConcepts(ctx context.Context, conceptName string, limit int) ([]repositories.CoStudiedConcept, error) {
+	var concepts []repositories.CoStudiedConcept
+	err := guard(ctx, r.breaker, func() (err error) {
+		concepts, err = r.next.GetCoStudiedConcepts(ctx, conceptName, limit)
+		return err
+	})
+	return concepts, err
+}
+
+func (r *breakerQueryRepository) InvalidateCachedExplanations(ctx context.Context, conceptNames []string) (int64, error) {
+	var invalidated int64
+	err := guard(ctx, r.breaker, func() (err error) {
+		invalidated, err = r.next.InvalidateCachedExplanations(ctx, conceptNames)
+		return err
+	})
+	return invalidated, err
+}
+
+func (r *breakerQueryRepository) IsHealthy(ctx context.Context) bool {
+	return r.next.IsHealthy(ctx)
+}
+
+// breakerResourceRepository fails fast while the document store's breaker is open
+type breakerResourceRepository struct {
+	next    repositories.ResourceRepository
+	breaker *breaker.Breaker
+}
+
+// NewBreakerResourceRepository wraps a resource repository in a circuit breaker
+func NewBreakerResourceRepository(next repositories.ResourceRepository, b *breaker.Breaker) repositories.ResourceRepository {
+	return &breakerResourceRepository{next: next, breaker: b}
+}
+
+func (r *breakerResourceRepository) Save(ctx context.Context, resource *entities.LearningResource) error {
+	return guard(ctx, r.breaker, func() error {
+		return r.next.Save(ctx, resource)
+	})
+}
+
+func (r *breakerResourceRepository) SaveBatch(ctx context.Context, resources []*entities.LearningResource) error {
+	return guard(ctx, r.breaker, func() error {
+		return r.next.SaveBatch(ctx, resources)
+	})
+}
+
+func (r *breakerResourceRepository) FindByConceptID(ctx context.Context, conceptID string, limit int) ([]*entities.LearningResource, error) {
+	var resources []*entities.LearningResource
+	err := guard(ctx, r.breaker, func() (err error) {
+		resources, err = r.next.FindByConceptID(ctx, conceptID, limit)
+		return err
+	})
+	return resources, err
+}
+
+func (r *breakerResourceRepository) Search(ctx context.Context, query string, filters repositories.ResourceFilter) ([]*entities.LearningResource, error) {
+	var resources []*entities.LearningResource
+	err := guard(ctx, r.breaker, func() (err error) {
+		resources, err = r.next.Search(ctx, query, filters)
+		return err
+	})
+	return resources, err
+}
+
+func (r *breakerResourceRepository) IsHealthy(ctx context.Context) bool {
+	return r.next.IsHealthy(ctx)
+}
+
+// breakerVectorRepository fails fast while the vector store's breaker is open, so
+// retrieval degrades to no context at once instead of after a timeout
+type breakerVectorRepository struct {
+	next    repositories.VectorRepository
+	breaker *breaker.Breaker
+}
+
+// NewBreakerVectorRepository wraps a vector repository in a circuit breaker
+func NewBreakerVectorRepository(next repositories.VectorRepository, b *breaker.Breaker) repositories.VectorRepository {
+	return &breakerVectorRepository{next: next, breaker: b}
+}
+
+func (r *breakerVectorRepository) Search(ctx context.Context, query string, limit int) ([]types.VectorResult, error) {
+	var results []types.VectorResult
+	err := guard(ctx, r.breaker, func() (err error) {
+		results, err = r.next.Search(ctx, query, limit)
+		return err
+	})
+	return results, err
+}
+
+func (r *breakerVectorRepository) BatchSearch(ctx context.Context, queries []string, limit int) ([][]types.VectorResult, error) {
+	var results [][]types.VectorResult
+	err := guard(ctx, r.breaker, func() (err error) {
+		results, err = r.next.BatchSearch(ctx, queries, limit)
+		return err
+	})
+	return results, err
+}
+
+func (r *breakerVectorRepository) IsHealthy(ctx context.Context) bool {
+	return r.next.IsHealthy(ctx)
+}
+
+func (r *breakerVectorRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	var stats map[string]interface{}
+	err := guard(ctx, r.breaker, func() (err error) {
+		stats, err = r.next.GetStats(ctx)
+		return err
+	})
+	return stats, err
+}
+
+func (r *breakerVectorRepository) DeleteAll(ctx context.Context) error {
+	return guard(ctx, r.breaker, func() error {
+		return r.next.DeleteAll(ctx)
+	})
+}
+
+func (r *breakerVectorRepository) AddContent(ctx context.Context, chunks []types.VectorContent) error {
+	return guard(ctx, r.breaker, func() error {
+		return r.next.AddContent(ctx, chunks)
+	})
+}
+
+func (r *breakerVectorRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := guard(ctx, r.breaker, func() (err error) {
+		count, err = r.next.Count(ctx)
+		return err
+	})
+	return count, err
+}
+
+func (r *breakerVectorRepository) CountByConcept(ctx context.Context, concepts []string) (map[string]int64, error) {
+	var counts map[string]int64
+	err := guard(ctx, r.breaker, func() (err error) {
+		counts, err = r.next.CountByConcept(ctx, concepts)
+		return err
+	})
+	return counts, err
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"mathprereq/internel/domain/repositories"
+	"mathprereq/pkg/breaker"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestIsBackendFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection error", errors.New("connection refused"), true},
+		{"backend deadline", fmt.Errorf("failed to find query: %w", context.DeadlineExceeded), true},
+		{"cancelled", fmt.Errorf("failed to find query: %w", context.Canceled), false},
+		{"caller deadline", &callerDoneError{err: context.DeadlineExceeded}, false},
+		{"no documents", fmt.Errorf("failed to find query: %w", mongo.ErrNoDocuments), false},
+		{"concept not found", repositories.ErrConceptNotFound, false},
+		{"write query", repositories.ErrWriteQuery, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBackendFailure(tt.err); got != tt.want {
+				t.Errorf("IsBackendFailure(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuardIgnoresCallerDeadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		expired  bool
+		wantOpen bool
+	}{
+		{"caller deadline passed", true, false},
+		{"backend timed out on its own", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := breaker.New("test", breaker.Config{FailureThreshold: 1, OpenTimeout: time.Minute}, IsBackendFailure)
+
+			ctx := context.Background()
+			if tt.expired {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, -time.Second)
+				defer cancel()
+			}
+
+			err := guard(ctx, b, func() error {
+				return fmt.Errorf("failed to find query: %w", context.DeadlineExceeded)
+			})
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("guard returned %v, want the call's error", err)
+			}
+			if open := b.State() == breaker.StateOpen; open != tt.wantOpen {
+				t.Errorf("breaker open = %v, want %v", open, tt.wantOpen)
+			}
+		})
+	}
+}
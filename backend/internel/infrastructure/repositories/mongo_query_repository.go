@@ -7,6 +7,7 @@ import (
 	"mathprereq/internel/domain/repositories"
 	"mathprereq/internel/types"
 	"regexp"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -49,8 +50,9 @@ func (r *mongoQueryRepository) Save(ctx context.Context, query *entities.Query)
 	return nil
 }
 
-// FindByConceptName finds a successful query that contains the specified concept
-func (r *mongoQueryRepository) FindByConceptName(ctx context.Context, conceptName string) (*entities.Query, error) {
+// FindByConceptName finds a successful query that contains the specified concept and was
+// answered for the given audience level and output format
+func (r *mongoQueryRepository) FindByConceptName(ctx context.Context, conceptName, audienceLevel, outputFormat string) (*entities.Query, error) {
 	collection := r.database.Collection("queries")
 
 	// Create filter to find successful queries with the concept in identified_concepts
@@ -79,6 +81,11 @@ func (r *mongoQueryRepository) FindByConceptName(ctx context.Context, conceptNam
 			{
 				"success": true,
 			},
+			{
+				"cache_invalidated": bson.M{"$ne": true},
+			},
+			variantFilter("audience_level", audienceLevel, entities.DefaultAudienceLevel),
+			variantFilter("output_format", outputFormat, entities.DefaultOutputFormat),
 			{
 				"response.explanation": bson.M{
 					"$exists": true,
@@ -109,12 +116,92 @@ func (r *mongoQueryRepository) FindByConceptName(ctx context.Context, conceptNam
 	return query, nil
 }
 
+func (r *mongoQueryRepository) FindByPathConceptID(ctx context.Context, conceptID string, limit int) ([]*entities.Query, error) {
+	filter := bson.M{
+		"prerequisite_path.id": conceptID,
+		"success":              true,
+	}
+	opts := options.Find().SetLimit(int64(limit)).SetSort(bson.D{{"timestamp", -1}})
+
+	cursor, err := r.database.Collection("queries").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find queries by path concept ID: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var queries []*entities.Query
+	for cursor.Next(ctx) {
+		var result bson.M
+		if err := cursor.Decode(&result); err != nil {
+			continue
+		}
+		query, err := r.bsonToQuery(result)
+		if err != nil {
+			continue
+		}
+		queries = append(queries, query)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read queries by path concept ID: %w", err)
+	}
+
+	return queries, nil
+}
+
+func (r *mongoQueryRepository) InvalidateCachedExplanations(ctx context.Context, conceptNames []string) (int64, error) {
+	filter := bson.M{
+		"success":           true,
+		"cache_invalidated": bson.M{"$ne": true},
+	}
+
+	if len(conceptNames) > 0 {
+		var matches []bson.M
+		for _, name := range conceptNames {
+			pattern := primitive.Regex{Pattern: fmt.Sprintf("^%s$", regexp.QuoteMeta(name)), Options: "i"}
+			matches = append(matches,
+				bson.M{"identified_concepts": pattern},
+				bson.M{"prerequisite_path.id": name},
+				bson.M{"prerequisite_path.name": pattern},
+			)
+		}
+		filter["$or"] = matches
+	}
+
+	update := bson.M{"$set": bson.M{
+		"cache_invalidated":    true,
+		"cache_invalidated_at": time.Now(),
+	}}
+
+	result, err := r.database.Collection("queries").UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate cached explanations: %w", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+// variantFilter matches queries whose explanation variant field (audience level, output
+// format) equals value. Queries saved before the field existed used the default value.
+func variantFilter(field, value, defaultValue string) bson.M {
+	if value == "" || value == defaultValue {
+		return bson.M{"$or": []bson.M{
+			{field: defaultValue},
+			{field: bson.M{"$exists": false}},
+		}}
+	}
+	return bson.M{field: value}
+}
+
 // bsonToQuery converts a BSON document to a Query entity
 func (r *mongoQueryRepository) bsonToQuery(doc bson.M) (*entities.Query, error) {
 	// Extract basic fields
 	id, _ := doc["_id"].(string)
 	text, _ := doc["text"].(string)
 	userID, _ := doc["user_id"].(string)
+	sessionID, _ := doc["session_id"].(string)
+	userAgent, _ := doc["user_agent"].(string)
+	ipAddress, _ := doc["ip_address"].(string)
+	audienceLevel, _ := doc["audience_level"].(string)
+	outputFormat, _ := doc["output_format"].(string)
 
 	// Handle identified_concepts
 	var identifiedConcepts []string
@@ -139,6 +226,13 @@ func (r *mongoQueryRepository) bsonToQuery(doc bson.M) (*entities.Query, error)
 				if conceptType, ok := pathDoc["type"].(string); ok {
 					concept.Type = conceptType
 				}
+				if roles, ok := pathDoc["roles"].(bson.A); ok {
+					for _, role := range roles {
+						if r, ok := role.(string); ok {
+							concept.Roles = append(concept.Roles, r)
+						}
+					}
+				}
 				prereqPath = append(prereqPath, concept)
 			}
 		}
@@ -163,6 +257,42 @@ func (r *mongoQueryRepository) bsonToQuery(doc bson.M) (*entities.Query, error)
 				}
 			}
 		}
+		if sources, ok := resp["context_sources"].(bson.A); ok {
+			for _, s := range sources {
+				var source types.ContextSource
+				if raw, err := bson.Marshal(s); err == nil && bson.Unmarshal(raw, &source) == nil {
+					response.ContextSources = append(response.ContextSources, source)
+				}
+			}
+		}
+		if cited, ok := resp["cited_context_indices"].(bson.A); ok {
+			for _, c := range cited {
+				switch index := c.(type) {
+				case int32:
+					response.CitedContextIndices = append(response.CitedContextIndices, int(index))
+				case int64:
+					response.CitedContextIndices = append(response.CitedContextIndices, int(index))
+				}
+			}
+		}
+		if maxTokens, ok := resp["max_output_tokens"].(int32); ok {
+			response.MaxOutputTokens = int(maxTokens)
+		}
+		response.Truncated, _ = resp["truncated"].(bool)
+		response.UnbalancedMath, _ = resp["unbalanced_math"].(bool)
+		response.FullExplanation, _ = resp["full_explanation"].(string)
+	}
+
+	// Handle metadata; cache checks read the context hash and unresolved concepts from it
+	var metadata entities.QueryMetadata
+	if meta, ok := doc["metadata"].(bson.M); ok {
+		raw, err := bson.Marshal(meta)
+		if err == nil {
+			err = bson.Unmarshal(raw, &metadata)
+		}
+		if err != nil {
+			r.logger.Warn("Failed to decode query metadata", zap.String("id", id), zap.Error(err))
+		}
 	}
 
 	// Handle timestamp
@@ -179,9 +309,15 @@ func (r *mongoQueryRepository) bsonToQuery(doc bson.M) (*entities.Query, error)
 		ID:                 id,
 		Text:               text,
 		UserID:             userID,
+		SessionID:          sessionID,
+		AudienceLevel:      audienceLevel,
+		OutputFormat:       outputFormat,
+		UserAgent:          userAgent,
+		IPAddress:          ipAddress,
 		IdentifiedConcepts: identifiedConcepts,
 		PrerequisitePath:   prereqPath,
 		Response:           response,
+		Metadata:           metadata,
 		Timestamp:          timestamp,
 		Success:            success,
 	}
@@ -269,6 +405,144 @@ func (r *mongoQueryRepository) GetQueryStats(ctx context.Context) (*repositories
 	}, nil
 }
 
+func (r *mongoQueryRepository) GetRecentFailures(ctx context.Context, limit int) ([]repositories.FailedQuerySummary, error) {
+	filter := bson.M{"success": false}
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"timestamp": -1}).
+		SetProjection(bson.M{
+			"text":                      1,
+			"timestamp":                 1,
+			"error_message":             1,
+			"metadata.request_id":       1,
+			"metadata.processing_steps": 1,
+		})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find failed queries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	failures := []repositories.FailedQuerySummary{}
+	for cursor.Next(ctx) {
+		var query entities.Query
+		if err := cursor.Decode(&query); err != nil {
+			continue
+		}
+
+		failure := repositories.FailedQuerySummary{
+			ID:           query.ID,
+			RequestID:    query.Metadata.RequestID,
+			Text:         query.Text,
+			Timestamp:    query.Timestamp,
+			ErrorMessage: query.ErrorMessage,
+		}
+		for i := len(query.Metadata.ProcessingSteps) - 1; i >= 0; i-- {
+			if step := query.Metadata.ProcessingSteps[i]; !step.Success {
+				failure.FailedStep = step.Name
+				failure.StepError = step.Error
+				break
+			}
+		}
+		failures = append(failures, failure)
+	}
+
+	return failures, nil
+}
+
+func (r *mongoQueryRepository) GetPrerequisiteSuggestions(ctx context.Context, limit int) ([]repositories.PrerequisiteSuggestion, error) {
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.M{"metadata.suggested_prerequisites": bson.M{"$exists": true}}}},
+		{{"$project", bson.M{
+			"timestamp":   1,
+			"suggestions": bson.M{"$objectToArray": "$metadata.suggested_prerequisites"},
+		}}},
+		{{"$unwind", "$suggestions"}},
+		{{"$unwind", "$suggestions.v"}},
+		{{"$group", bson.D{
+			{"_id", bson.D{{"concept", "$suggestions.k"}, {"prerequisite", "$suggestions.v"}}},
+			{"count", bson.D{{"$sum", 1}}},
+			{"last_suggested", bson.D{{"$max", "$timestamp"}}},
+		}}},
+		{{"$sort", bson.D{{"count", -1}, {"last_suggested", -1}}}},
+		{{"$limit", int64(limit)}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate prerequisite suggestions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ID struct {
+			Concept      string `bson:"concept"`
+			Prerequisite string `bson:"prerequisite"`
+		} `bson:"_id"`
+		Count         int64     `bson:"count"`
+		LastSuggested time.Time `bson:"last_suggested"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode prerequisite suggestions: %w", err)
+	}
+
+	suggestions := make([]repositories.PrerequisiteSuggestion, len(rows))
+	for i, row := range rows {
+		suggestions[i] = repositories.PrerequisiteSuggestion{
+			Concept:       row.ID.Concept,
+			Prerequisite:  row.ID.Prerequisite,
+			Count:         row.Count,
+			LastSuggested: row.LastSuggested,
+		}
+	}
+
+	return suggestions, nil
+}
+
+// GetCoStudiedConcepts counts each query once per co-occurring concept, comparing
+// concept names case-insensitively
+func (r *mongoQueryRepository) GetCoStudiedConcepts(ctx context.Context, conceptName string, limit int) ([]repositories.CoStudiedConcept, error) {
+	name := strings.ToLower(strings.TrimSpace(conceptName))
+
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.M{
+			"success": true,
+			"identified_concepts": bson.M{
+				"$regex": fmt.Sprintf("(?i)^%s$", regexp.QuoteMeta(name)),
+			},
+		}}},
+		// Lowercase and de-duplicate so a query counts once per concept
+		{{"$project", bson.M{
+			"concepts": bson.M{"$setUnion": bson.A{bson.M{"$map": bson.M{
+				"input": "$identified_concepts",
+				"in":    bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$$this"}}},
+			}}}},
+		}}},
+		{{"$unwind", "$concepts"}},
+		{{"$match", bson.M{"concepts": bson.M{"$nin": bson.A{name, ""}}}}},
+		{{"$group", bson.D{
+			{"_id", "$concepts"},
+			{"count", bson.D{{"$sum", 1}}},
+		}}},
+		{{"$sort", bson.D{{"count", -1}, {"_id", 1}}}},
+		{{"$limit", int64(limit)}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate co-studied concepts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	concepts := []repositories.CoStudiedConcept{}
+	if err := cursor.All(ctx, &concepts); err != nil {
+		return nil, fmt.Errorf("failed to decode co-studied concepts: %w", err)
+	}
+
+	return concepts, nil
+}
+
 func (r *mongoQueryRepository) GetPopularConcepts(ctx context.Context, limit int) ([]repositories.ConceptPopularity, error) {
 	collection := r.collection
 
@@ -309,6 +583,67 @@ func (r *mongoQueryRepository) GetPopularConcepts(ctx context.Context, limit int
 	return concepts, nil
 }
 
+func (r *mongoQueryRepository) GetUsage(ctx context.Context, since time.Time) ([]repositories.ModelUsage, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"timestamp": bson.M{"$gte": since}}},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"year":  bson.M{"$year": "$timestamp"},
+					"month": bson.M{"$month": "$timestamp"},
+					"day":   bson.M{"$dayOfMonth": "$timestamp"},
+					"model": "$response.llm_model",
+				},
+				"query_count": bson.M{"$sum": 1},
+				"untracked_queries": bson.M{
+					"$sum": bson.M{"$cond": bson.M{
+						"if":   bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$response.tokens_used", 0}}, 0}},
+						"then": 0,
+						"else": 1,
+					}},
+				},
+				"tokens_used": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$response.tokens_used", 0}}},
+			},
+		},
+		{"$sort": bson.M{"_id": 1}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var usage []repositories.ModelUsage
+	for cursor.Next(ctx) {
+		var result struct {
+			ID struct {
+				Year  int    `bson:"year"`
+				Month int    `bson:"month"`
+				Day   int    `bson:"day"`
+				Model string `bson:"model"`
+			} `bson:"_id"`
+			QueryCount       int64 `bson:"query_count"`
+			UntrackedQueries int64 `bson:"untracked_queries"`
+			TokensUsed       int64 `bson:"tokens_used"`
+		}
+
+		if err := cursor.Decode(&result); err != nil {
+			continue
+		}
+
+		usage = append(usage, repositories.ModelUsage{
+			Date:             time.Date(result.ID.Year, time.Month(result.ID.Month), result.ID.Day, 0, 0, 0, 0, time.UTC),
+			Model:            result.ID.Model,
+			QueryCount:       result.QueryCount,
+			UntrackedQueries: result.UntrackedQueries,
+			TokensUsed:       result.TokensUsed,
+		})
+	}
+
+	return usage, cursor.Err()
+}
+
 func (r *mongoQueryRepository) GetQueryTrends(ctx context.Context, days int) ([]repositories.QueryTrend, error) {
 	collection := r.collection
 
@@ -335,6 +670,7 @@ func (r *mongoQueryRepository) GetQueryTrends(ctx context.Context, days int) ([]
 				"successful_queries": bson.M{
 					"$sum": bson.M{"$cond": bson.M{"if": "$success", "then": 1, "else": 0}},
 				},
+				"avg_processing_time": bson.M{"$avg": "$processing_time_ms"},
 			},
 		},
 		{"$sort": bson.M{"_id": 1}},
@@ -354,8 +690,9 @@ func (r *mongoQueryRepository) GetQueryTrends(ctx context.Context, days int) ([]
 				Month int `bson:"month"`
 				Day   int `bson:"day"`
 			} `bson:"_id"`
-			QueryCount        int64 `bson:"query_count"`
-			SuccessfulQueries int64 `bson:"successful_queries"`
+			QueryCount        int64   `bson:"query_count"`
+			SuccessfulQueries int64   `bson:"successful_queries"`
+			AvgProcessingTime float64 `bson:"avg_processing_time"`
 		}
 
 		if err := cursor.Decode(&result); err != nil {
@@ -368,9 +705,10 @@ func (r *mongoQueryRepository) GetQueryTrends(ctx context.Context, days int) ([]
 		}
 
 		trends = append(trends, repositories.QueryTrend{
-			Date:        time.Date(result.ID.Year, time.Month(result.ID.Month), result.ID.Day, 0, 0, 0, 0, time.UTC),
-			QueryCount:  result.QueryCount,
-			SuccessRate: successRate,
+			Date:            time.Date(result.ID.Year, time.Month(result.ID.Month), result.ID.Day, 0, 0, 0, 0, time.UTC),
+			QueryCount:      result.QueryCount,
+			SuccessRate:     successRate,
+			AvgResponseTime: result.AvgProcessingTime,
 		})
 	}
 
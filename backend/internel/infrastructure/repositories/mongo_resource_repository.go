@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"mathprereq/internel/domain/entities"
+	"mathprereq/internel/domain/repositories"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// LearningResourcesCollection holds the domain's LearningResource documents, one per
+// (concept_id, url) pair
+const LearningResourcesCollection = "learning_resources"
+
+type mongoResourceRepository struct {
+	client     *mongo.Client
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+// resourceKeyIndexName is the unique (concept_id, url) index upserts rely on to never
+// store the same resource twice under a concept
+const resourceKeyIndexName = "concept_id_url_unique"
+
+func NewMongoResourceRepository(client *mongo.Client, dbName string, logger *zap.Logger) repositories.ResourceRepository {
+	repo := &mongoResourceRepository{
+		client:     client,
+		collection: client.Database(dbName).Collection(LearningResourcesCollection),
+		logger:     logger,
+	}
+
+	if err := repo.createIndexes(context.Background()); err != nil {
+		logger.Warn("Failed to create learning resource indexes", zap.Error(err))
+	}
+	return repo
+}
+
+// createIndexes creates the resource key index; without it concurrent upserts of a new
+// resource can both insert it
+func (r *mongoResourceRepository) createIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"concept_id", 1}, {"url", 1}},
+		Options: options.Index().SetUnique(true).SetName(resourceKeyIndexName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s index: %w", resourceKeyIndexName, err)
+	}
+	return nil
+}
+
+// upsertModel updates the resource stored for the same concept and URL, keeping its
+// original ID and creation time, or inserts it
+func upsertModel(resource *entities.LearningResource) *mongo.UpdateOneModel {
+	return mongo.NewUpdateOneModel().
+		SetFilter(bson.M{"concept_id": resource.ConceptID, "url": resource.URL}).
+		SetUpdate(bson.M{
+			"$set": bson.M{
+				"title":       resource.Title,
+				"type":        resource.Type,
+				"difficulty":  resource.Difficulty,
+				"quality":     resource.Quality,
+				"source":      resource.Source,
+				"description": resource.Description,
+				"tags":        resource.Tags,
+				"duration":    resource.Duration,
+				"updated_at":  time.Now(),
+			},
+			"$setOnInsert": bson.M{
+				"_id":        resource.ID,
+				"created_at": resource.CreatedAt,
+			},
+		}).
+		SetUpsert(true)
+}
+
+func (r *mongoResourceRepository) Save(ctx context.Context, resource *entities.LearningResource) error {
+	return r.SaveBatch(ctx, []*entities.LearningResource{resource})
+}
+
+func (r *mongoResourceRepository) SaveBatch(ctx context.Context, resources []*entities.LearningResource) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(resources))
+	for _, resource := range resources {
+		if resource != nil {
+			writes = append(writes, upsertModel(resource))
+		}
+	}
+
+	if _, err := r.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to save learning resources: %w", err)
+	}
+	return nil
+}
+
+func (r *mongoResourceRepository) FindByConceptID(ctx context.Context, conceptID string, limit int) ([]*entities.LearningResource, error) {
+	opts := options.Find().SetSort(bson.D{{"quality", -1}, {"updated_at", -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	return r.find(ctx, bson.M{"concept_id": conceptID}, opts)
+}
+
+// Search matches query case-insensitively against title, description and tags
+func (r *mongoResourceRepository) Search(ctx context.Context, query string, filters repositories.ResourceFilter) ([]*entities.LearningResource, error) {
+	filter := bson.M{}
+	if query != "" {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(query), "$options": "i"}
+		filter["$or"] = bson.A{
+			bson.M{"title": pattern},
+			bson.M{"description": pattern},
+			bson.M{"tags": pattern},
+		}
+	}
+	if filters.Type != nil {
+		filter["type"] = *filters.Type
+	}
+	if filters.Difficulty != nil {
+		filter["difficulty"] = *filters.Difficulty
+	}
+	if filters.MinQuality != nil {
+		filter["quality"] = bson.M{"$gte": *filters.MinQuality}
+	}
+
+	opts := options.Find().SetSort(bson.D{{"quality", -1}})
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	return r.find(ctx, filter, opts)
+}
+
+func (r *mongoResourceRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*entities.LearningResource, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find learning resources: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	resources := []*entities.LearningResource{}
+	if err := cursor.All(ctx, &resources); err != nil {
+		return nil, fmt.Errorf("failed to decode learning resources: %w", err)
+	}
+	return resources, nil
+}
+
+func (r *mongoResourceRepository) IsHealthy(ctx context.Context) bool {
+	err := r.client.Ping(ctx, nil)
+	return err == nil
+}
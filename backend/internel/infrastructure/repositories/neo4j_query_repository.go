@@ -2,11 +2,12 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"mathprereq/internel/data/neo4j"
 	"mathprereq/internel/domain/repositories"
 	"mathprereq/internel/types"
-	"time"
+	"strings"
 
 	"go.uber.org/zap"
 )
@@ -37,11 +38,19 @@ func (r *neo4jConceptRepository) FindByName(ctx context.Context, name string) (*
 		return nil, fmt.Errorf("failed to find concept by name: %w", err)
 	}
 	if conceptID == nil {
-		return nil, fmt.Errorf("concept not found: %s", name)
+		return nil, fmt.Errorf("%w: %s", repositories.ErrConceptNotFound, name)
 	}
 	return r.FindByID(ctx, *conceptID)
 }
 
+func (r *neo4jConceptRepository) ResolveIDs(ctx context.Context, names []string) (map[string]string, error) {
+	ids, err := r.client.FindConceptIDs(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve concept IDs: %w", err)
+	}
+	return ids, nil
+}
+
 func (r *neo4jConceptRepository) GetAll(ctx context.Context) ([]types.Concept, error) {
 	concepts, err := r.client.GetAllConcepts(ctx)
 	if err != nil {
@@ -55,22 +64,55 @@ func (r *neo4jConceptRepository) GetAll(ctx context.Context) ([]types.Concept, e
 	return result, nil
 }
 
-func (r *neo4jConceptRepository) FindPrerequisitePath(ctx context.Context, targetConcepts []string) ([]types.Concept, error) {
-	concepts, err := r.client.FindPrerequisitePath(ctx, targetConcepts)
+func (r *neo4jConceptRepository) FindPrerequisitePath(ctx context.Context, targetConcepts []string) ([]types.Concept, []string, error) {
+	concepts, unresolved, err := r.client.FindPrerequisitePath(ctx, targetConcepts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find prerequisite path: %w", err)
+		return nil, nil, fmt.Errorf("failed to find prerequisite path: %w", err)
 	}
 
 	result := make([]types.Concept, len(concepts))
 	for i, concept := range concepts {
 		result[i] = *r.convertToEntity(&concept)
 	}
-	return result, nil
+	return result, unresolved, nil
+}
+
+func (r *neo4jConceptRepository) GetPathGraph(ctx context.Context, targetConcepts []string) (*types.PathGraph, error) {
+	nodes, _, err := r.FindPrerequisitePath(ctx, targetConcepts)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID
+	}
+
+	edges, err := r.client.GetPrerequisiteEdges(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get path graph edges: %w", err)
+	}
+
+	graph := &types.PathGraph{
+		Nodes: nodes,
+		Edges: make([]types.ConceptEdge, len(edges)),
+	}
+	for i, edge := range edges {
+		graph.Edges[i] = types.ConceptEdge{
+			Source: edge.Source,
+			Target: edge.Target,
+			Type:   "PREREQUISITE_FOR",
+		}
+	}
+	return graph, nil
 }
 
 func (r *neo4jConceptRepository) GetConceptDetail(ctx context.Context, conceptID string) (*types.ConceptDetailResult, error) {
 	detail, err := r.client.GetConceptInfo(ctx, conceptID)
 	if err != nil {
+		if errors.Is(err, neo4j.ErrConceptNotFound) {
+			return nil, fmt.Errorf("%w: %s", repositories.ErrConceptNotFound, conceptID)
+		}
 		return nil, fmt.Errorf("failed to get concept detail: %w", err)
 	}
 
@@ -92,6 +134,55 @@ func (r *neo4jConceptRepository) GetConceptDetail(ctx context.Context, conceptID
 	}, nil
 }
 
+func (r *neo4jConceptRepository) GetNextConcepts(ctx context.Context, conceptID string, limit int) ([]types.Concept, error) {
+	concepts, err := r.client.GetNextConcepts(ctx, conceptID, limit)
+	if err != nil {
+		if errors.Is(err, neo4j.ErrConceptNotFound) {
+			return nil, fmt.Errorf("%w: %s", repositories.ErrConceptNotFound, conceptID)
+		}
+		return nil, err
+	}
+
+	result := make([]types.Concept, len(concepts))
+	for i, concept := range concepts {
+		result[i] = *r.convertToEntity(&concept)
+	}
+	return result, nil
+}
+
+func (r *neo4jConceptRepository) IsPrerequisiteOf(ctx context.Context, conceptA, conceptB string) (bool, []types.Concept, error) {
+	isPrereq, path, err := r.client.IsPrerequisiteOf(ctx, conceptA, conceptB)
+	if err != nil {
+		if errors.Is(err, neo4j.ErrConceptNotFound) {
+			return false, nil, translateNotFound(err)
+		}
+		return false, nil, fmt.Errorf("failed to check prerequisite relationship: %w", err)
+	}
+
+	result := make([]types.Concept, len(path))
+	for i, concept := range path {
+		result[i] = *r.convertToEntity(&concept)
+	}
+	return isPrereq, result, nil
+}
+
+func (r *neo4jConceptRepository) FindWithoutDescription(ctx context.Context, limit int) ([]types.Concept, error) {
+	concepts, err := r.client.GetConceptsWithoutDescription(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find concepts without description: %w", err)
+	}
+
+	result := make([]types.Concept, len(concepts))
+	for i, concept := range concepts {
+		result[i] = *r.convertToEntity(&concept)
+	}
+	return result, nil
+}
+
+func (r *neo4jConceptRepository) SetDescriptionIfBlank(ctx context.Context, conceptID, description string) (bool, error) {
+	return r.client.SetDescriptionIfBlank(ctx, conceptID, description)
+}
+
 func (r *neo4jConceptRepository) GetStats(ctx context.Context) (*types.SystemStats, error) {
 	stats, err := r.client.GetStats(ctx)
 	if err != nil {
@@ -115,14 +206,38 @@ func (r *neo4jConceptRepository) IsHealthy(ctx context.Context) bool {
 
 // Helper function to convert neo4j.Concept to types.Concept
 func (r *neo4jConceptRepository) convertToEntity(neo4jConcept *neo4j.Concept) *types.Concept {
-	return &types.Concept{
+	concept := &types.Concept{
 		ID:          neo4jConcept.ID,
 		Name:        neo4jConcept.Name,
 		Description: neo4jConcept.Description,
 		Type:        neo4jConcept.Type,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		CreatedAt:   neo4jConcept.CreatedAt,
+		UpdatedAt:   neo4jConcept.UpdatedAt,
+		Roles:       neo4jConcept.Roles,
+	}
+	// Type always names the primary role, whichever order the roles were collected in
+	concept.Type = concept.PrimaryRole()
+	return concept
+}
+
+func (r *neo4jConceptRepository) ExecuteReadQuery(ctx context.Context, cypher string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	rows, err := r.client.ExecuteReadQuery(ctx, cypher, params)
+	switch {
+	case errors.Is(err, neo4j.ErrDiagnosticsDisabled):
+		return nil, repositories.ErrDiagnosticsDisabled
+	case errors.Is(err, neo4j.ErrWriteQuery):
+		return nil, fmt.Errorf("%w%s", repositories.ErrWriteQuery, strings.TrimPrefix(err.Error(), neo4j.ErrWriteQuery.Error()))
+	case errors.Is(err, neo4j.ErrInvalidQuery):
+		return nil, fmt.Errorf("%w%s", repositories.ErrInvalidQuery, strings.TrimPrefix(err.Error(), neo4j.ErrInvalidQuery.Error()))
 	}
+	return rows, err
+}
+
+// translateNotFound maps the graph client's not-found error onto the domain sentinel,
+// keeping the unresolved concept name in the message
+func translateNotFound(err error) error {
+	detail := strings.TrimPrefix(err.Error(), neo4j.ErrConceptNotFound.Error())
+	return fmt.Errorf("%w%s", repositories.ErrConceptNotFound, detail)
 }
 
 // Helper functions
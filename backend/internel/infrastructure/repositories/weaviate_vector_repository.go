@@ -28,22 +28,117 @@ func (r *weaviateVectorRepository) Search(ctx context.Context, query string, lim
 		return nil, fmt.Errorf("vector search failed: %w", err)
 	}
 
+	return toVectorResults(results), nil
+}
+
+func (r *weaviateVectorRepository) BatchSearch(ctx context.Context, queries []string, limit int) ([][]types.VectorResult, error) {
+	batches, err := r.client.BatchSearch(ctx, queries, limit)
+	if err != nil {
+		return nil, fmt.Errorf("batch vector search failed: %w", err)
+	}
+
+	vectorResults := make([][]types.VectorResult, len(batches))
+	for i, results := range batches {
+		vectorResults[i] = toVectorResults(results)
+	}
+	return vectorResults, nil
+}
+
+func toVectorResults(results []weaviate.SearchResult) []types.VectorResult {
 	vectorResults := make([]types.VectorResult, len(results))
 	for i, result := range results {
 		vectorResults[i] = types.VectorResult{
 			Content:  result.Content,
+			Concept:  result.Concept,
 			Score:    float64(result.Score),
 			Metadata: result.Metadata,
 		}
 	}
-
-	return vectorResults, nil
+	return vectorResults
 }
 
 func (r *weaviateVectorRepository) IsHealthy(ctx context.Context) bool {
 	return r.client.IsHealthy(ctx)
 }
 
+// GetStats reports the default class's stats, with total_chunks counted over every class
 func (r *weaviateVectorRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
-	return r.client.GetStats(ctx)
+	stats, err := r.client.GetStats(ctx, "")
+	if err != nil || stats["status"] != "healthy" {
+		return stats, err
+	}
+
+	total, err := r.Count(ctx)
+	if err != nil {
+		return stats, nil
+	}
+	stats["total_chunks"] = total
+	stats["classes"] = r.client.Classes()
+	return stats, nil
+}
+
+// CountByConcept counts each concept's chunks in the class it is routed to
+func (r *weaviateVectorRepository) CountByConcept(ctx context.Context, concepts []string) (map[string]int64, error) {
+	byClass := make(map[string][]string)
+	for _, concept := range concepts {
+		class := r.client.ClassForConcept(concept)
+		byClass[class] = append(byClass[class], concept)
+	}
+
+	counts := make(map[string]int64, len(concepts))
+	for class, classConcepts := range byClass {
+		classCounts, err := r.client.CountByConcept(ctx, class, classConcepts)
+		if err != nil {
+			return nil, err
+		}
+		for concept, count := range classCounts {
+			counts[concept] = count
+		}
+	}
+	return counts, nil
+}
+
+// DeleteAll empties every configured class
+func (r *weaviateVectorRepository) DeleteAll(ctx context.Context) error {
+	for _, class := range r.client.Classes() {
+		if err := r.client.DeleteAll(ctx, class); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddContent inserts each chunk into the class its concept is routed to
+func (r *weaviateVectorRepository) AddContent(ctx context.Context, chunks []types.VectorContent) error {
+	byClass := make(map[string][]weaviate.ContentChunk)
+	for _, chunk := range chunks {
+		class := r.client.ClassForConcept(chunk.Concept)
+		byClass[class] = append(byClass[class], weaviate.ContentChunk{
+			Content:    chunk.Content,
+			Concept:    chunk.Concept,
+			Chapter:    chunk.Chapter,
+			Source:     weaviate.Source{Document: chunk.Source},
+			ChunkIndex: chunk.ChunkIndex,
+		})
+	}
+
+	for class, content := range byClass {
+		if err := r.client.AddContent(ctx, class, content); err != nil {
+			return fmt.Errorf("failed to add vector content: %w", err)
+		}
+	}
+	return nil
+}
+
+// Count returns the number of chunks over every configured class
+func (r *weaviateVectorRepository) Count(ctx context.Context) (int64, error) {
+	var total int64
+	for _, class := range r.client.Classes() {
+		count, err := r.client.Count(ctx, class)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
 }
@@ -10,6 +10,43 @@ type Concept struct {
 	Type        string    `json:"type" bson:"type"`
 	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`
+	// Roles are all the roles the concept plays in a result, e.g. a target that is also a
+	// prerequisite of another target; Type is the primary one
+	Roles []string `json:"roles,omitempty" bson:"roles,omitempty"`
+}
+
+// Roles of a concept in a graph query result
+const (
+	ConceptRoleTarget       = "target"
+	ConceptRolePrerequisite = "prerequisite"
+	ConceptRoleNext         = "next_concept"
+)
+
+// HasRole reports whether the concept plays role, falling back to Type for concepts
+// without Roles
+func (c Concept) HasRole(role string) bool {
+	if len(c.Roles) == 0 {
+		return c.Type == role
+	}
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// PrimaryRole is the single role to show for the concept: target wins over its other
+// roles, otherwise the first role, or Type for concepts without Roles
+func (c Concept) PrimaryRole() string {
+	switch {
+	case len(c.Roles) == 0:
+		return c.Type
+	case c.HasRole(ConceptRoleTarget):
+		return ConceptRoleTarget
+	default:
+		return c.Roles[0]
+	}
 }
 
 // Results from graph queries
@@ -18,12 +55,69 @@ type ConceptDetailResult struct {
 	Prerequisites       []Concept `json:"prerequisites"`
 	LeadsTo             []Concept `json:"leads_to"`
 	DetailedExplanation string    `json:"detailed_explanation"`
+	// Degraded is set when the graph was unavailable and the detail was assembled from a
+	// cached query: prerequisites are those on its stored path and LeadsTo is empty
+	Degraded       bool   `json:"degraded,omitempty"`
+	DegradedReason string `json:"degraded_reason,omitempty"`
+}
+
+// ConceptSearchResult is a concept matched by name (GraphScore) and/or by similarity to
+// vector store content tagged with it (VectorScore); Score blends the two
+type ConceptSearchResult struct {
+	Concept     Concept `json:"concept"`
+	Score       float64 `json:"score"`
+	GraphScore  float64 `json:"graph_score"`
+	VectorScore float64 `json:"vector_score"`
+	InGraph     bool    `json:"in_graph"`
 }
 
 type PrerequisitePathResult struct {
 	Concepts []Concept `json:"concepts"`
 }
 
+// Graph of a prerequisite path for rendering; edges only connect nodes in the graph
+type PathGraph struct {
+	Nodes []Concept     `json:"nodes"`
+	Edges []ConceptEdge `json:"edges"`
+}
+
+type ConceptEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+type PrerequisiteRelationshipResult struct {
+	From           string    `json:"from"`
+	To             string    `json:"to"`
+	IsPrerequisite bool      `json:"is_prerequisite"`
+	Path           []Concept `json:"path"`
+}
+
+type DescriptionFillResult struct {
+	Candidates int      `json:"candidates"`
+	Filled     int      `json:"filled"`
+	Skipped    int      `json:"skipped"`
+	Failed     []string `json:"failed,omitempty"`
+}
+
+type VectorStoreRebuildResult struct {
+	TriggeredBy string    `json:"triggered_by"`
+	StartedAt   time.Time `json:"started_at"`
+	Ingested    int       `json:"ingested"`
+	ObjectCount int64     `json:"object_count"`
+}
+
+// CacheInvalidationResult reports how many entries were invalidated in each cache,
+// for every concept when ConceptID is empty
+type CacheInvalidationResult struct {
+	ConceptID     string           `json:"concept_id,omitempty"`
+	MatchedNames  []string         `json:"matched_names,omitempty"`
+	TriggeredBy   string           `json:"triggered_by"`
+	Invalidated   map[string]int64 `json:"invalidated"`
+	InvalidatedAt time.Time        `json:"invalidated_at"`
+}
+
 type SystemStats struct {
 	TotalConcepts  int64  `json:"total_concepts"`
 	TotalChunks    int64  `json:"total_chunks"`
@@ -37,6 +131,39 @@ type SystemStats struct {
 // Vector search result
 type VectorResult struct {
 	Content  string                 `json:"content"`
+	Concept  string                 `json:"concept,omitempty"`
 	Score    float64                `json:"score"`
 	Metadata map[string]interface{} `json:"metadata"`
 }
+
+// ContextSource identifies where a retrieved context chunk came from in the vector store
+type ContextSource struct {
+	Concept    string  `json:"concept,omitempty" bson:"concept,omitempty"`
+	Chapter    string  `json:"chapter,omitempty" bson:"chapter,omitempty"`
+	Source     string  `json:"source,omitempty" bson:"source,omitempty"`
+	ChunkIndex int     `json:"chunk_index" bson:"chunk_index"`
+	Score      float64 `json:"score" bson:"score"`
+}
+
+// Explanation block types
+const (
+	BlockText = "text"
+	BlockMath = "math"
+)
+
+// ExplanationBlock is a run of an explanation, either plain text or a LaTeX math
+// segment. Math Content excludes its delimiters; Display marks block ($$) math.
+type ExplanationBlock struct {
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	Display bool   `json:"display,omitempty"`
+}
+
+// Content to be embedded into the vector store
+type VectorContent struct {
+	Content    string `json:"content" binding:"required"`
+	Concept    string `json:"concept"`
+	Chapter    string `json:"chapter"`
+	Source     string `json:"source"`
+	ChunkIndex int    `json:"chunk_index"`
+}
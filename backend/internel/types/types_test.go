@@ -0,0 +1,56 @@
+package types
+
+import "testing"
+
+func TestConceptRoles(t *testing.T) {
+	tests := []struct {
+		name        string
+		concept     Concept
+		wantPrimary string
+		has         map[string]bool
+	}{
+		{
+			name:        "legacy concept without roles falls back to type",
+			concept:     Concept{Type: ConceptRolePrerequisite},
+			wantPrimary: ConceptRolePrerequisite,
+			has:         map[string]bool{ConceptRolePrerequisite: true, ConceptRoleTarget: false},
+		},
+		{
+			name:        "single role",
+			concept:     Concept{Type: ConceptRoleNext, Roles: []string{ConceptRoleNext}},
+			wantPrimary: ConceptRoleNext,
+			has:         map[string]bool{ConceptRoleNext: true, ConceptRolePrerequisite: false},
+		},
+		{
+			name:        "target that is also a prerequisite",
+			concept:     Concept{Type: ConceptRoleTarget, Roles: []string{ConceptRoleTarget, ConceptRolePrerequisite}},
+			wantPrimary: ConceptRoleTarget,
+			has:         map[string]bool{ConceptRoleTarget: true, ConceptRolePrerequisite: true, ConceptRoleNext: false},
+		},
+		{
+			name:        "target wins even when listed last",
+			concept:     Concept{Roles: []string{ConceptRolePrerequisite, ConceptRoleTarget}},
+			wantPrimary: ConceptRoleTarget,
+			has:         map[string]bool{ConceptRoleTarget: true, ConceptRolePrerequisite: true},
+		},
+		{
+			name:        "roles override a stale type",
+			concept:     Concept{Type: ConceptRoleTarget, Roles: []string{ConceptRolePrerequisite, ConceptRoleNext}},
+			wantPrimary: ConceptRolePrerequisite,
+			has:         map[string]bool{ConceptRoleTarget: false, ConceptRoleNext: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.concept.PrimaryRole(); got != tt.wantPrimary {
+				t.Errorf("PrimaryRole() = %q, want %q", got, tt.wantPrimary)
+			}
+			for role, want := range tt.has {
+				if got := tt.concept.HasRole(role); got != want {
+					t.Errorf("HasRole(%q) = %v, want %v", role, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Package breaker implements a consecutive-failure circuit breaker for calls to a backend.
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned without calling the backend while the breaker is open
+var ErrOpen = errors.New("circuit breaker is open")
+
+// DefaultOpenTimeout is how long an open breaker fails fast when no timeout is configured
+const DefaultOpenTimeout = 30 * time.Second
+
+// State is the state of a breaker
+type State int
+
+const (
+	// StateClosed lets every call through
+	StateClosed State = iota
+	// StateOpen fails every call fast until the open timeout passes
+	StateOpen
+	// StateHalfOpen lets one probe call through; its outcome closes or reopens the breaker
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// Config sets when a breaker opens and for how long
+type Config struct {
+	// FailureThreshold consecutive failures open the breaker; 0 disables it
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before a probe is let through;
+	// it defaults to DefaultOpenTimeout
+	OpenTimeout time.Duration
+}
+
+// Breaker opens after FailureThreshold consecutive failed calls and then fails fast
+// with ErrOpen. Once OpenTimeout has passed one probe call is let through: success
+// closes the breaker, failure keeps it open for another OpenTimeout.
+type Breaker struct {
+	name   string
+	config Config
+	// isFailure decides which errors count against the backend; nil counts every error
+	isFailure func(error) bool
+	// onStateChange is called, outside the lock, on every transition
+	onStateChange func(name string, from, to State)
+	now           func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+	// generation counts transitions; a call only records its outcome if the breaker is
+	// still in the generation that let it through, so in half-open only the probe does
+	generation uint64
+}
+
+// New creates a closed breaker. isFailure filters the errors that count as backend
+// failures (e.g. not a "not found"); nil counts every error.
+func New(name string, config Config, isFailure func(error) bool) *Breaker {
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = DefaultOpenTimeout
+	}
+	return &Breaker{
+		name:      name,
+		config:    config,
+		isFailure: isFailure,
+		now:       time.Now,
+	}
+}
+
+// OnStateChange registers a callback for state transitions, e.g. for logging or metrics
+func (b *Breaker) OnStateChange(fn func(name string, from, to State)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onStateChange = fn
+}
+
+// Name returns the backend name the breaker was created with
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the current state; an open breaker whose timeout has passed reports
+// half-open, as the next call will be a probe
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Do runs fn unless the breaker is open, recording its outcome. While open, or while a
+// half-open probe is in flight, it returns an error wrapping ErrOpen without calling fn.
+func (b *Breaker) Do(fn func() error) error {
+	if b.config.FailureThreshold <= 0 {
+		return fn()
+	}
+
+	generation, err := b.allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	b.record(generation, err)
+	return err
+}
+
+// allow reserves a call, moving an open breaker past its timeout to half-open, and
+// returns the generation the call belongs to
+func (b *Breaker) allow() (uint64, error) {
+	b.mu.Lock()
+	var transition func()
+	defer func() {
+		b.mu.Unlock()
+		if transition != nil {
+			transition()
+		}
+	}()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.config.OpenTimeout {
+			return 0, fmt.Errorf("%s: %w", b.name, ErrOpen)
+		}
+		transition = b.setState(StateHalfOpen)
+		b.probing = true
+	case StateHalfOpen:
+		if b.probing {
+			return 0, fmt.Errorf("%s: %w", b.name, ErrOpen)
+		}
+		b.probing = true
+	}
+	return b.generation, nil
+}
+
+// record applies the outcome of a call let through by allow in the given generation
+func (b *Breaker) record(generation uint64, err error) {
+	failed := err != nil && (b.isFailure == nil || b.isFailure(err))
+
+	b.mu.Lock()
+	var transition func()
+	defer func() {
+		b.mu.Unlock()
+		if transition != nil {
+			transition()
+		}
+	}()
+
+	// A slow call started before the last transition, e.g. before the breaker opened,
+	// neither closes nor reopens it, nor frees the probe slot
+	if generation != b.generation {
+		return
+	}
+	if b.state == StateHalfOpen {
+		b.probing = false
+	}
+
+	if !failed {
+		b.failures = 0
+		if b.state == StateHalfOpen {
+			transition = b.setState(StateClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || (b.state == StateClosed && b.failures >= b.config.FailureThreshold) {
+		b.openedAt = b.now()
+		transition = b.setState(StateOpen)
+	}
+}
+
+// setState changes the state under the lock and returns the callback to run after it
+func (b *Breaker) setState(to State) func() {
+	from := b.state
+	if from != to {
+		b.generation++
+	}
+	b.state = to
+	if b.onStateChange == nil || from == to {
+		return nil
+	}
+	fn, name := b.onStateChange, b.name
+	return func() { fn(name, from, to) }
+}
@@ -0,0 +1,168 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errBackend = errors.New("backend down")
+
+// fakeClock is a manually advanced clock for a breaker's open timeout
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestBreaker(threshold int) (*Breaker, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := New("test", Config{FailureThreshold: threshold, OpenTimeout: time.Minute}, nil)
+	b.now = clock.Now
+	return b, clock
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	// step is one call through the breaker after advancing the clock
+	type step struct {
+		advance   time.Duration
+		err       error
+		wantOpen  bool
+		wantState State
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "closed until the threshold",
+			steps: []step{
+				{err: errBackend, wantState: StateClosed},
+				{err: errBackend, wantState: StateClosed},
+				{err: errBackend, wantState: StateOpen},
+				{wantOpen: true, wantState: StateOpen},
+			},
+		},
+		{
+			name: "success resets the failure count",
+			steps: []step{
+				{err: errBackend, wantState: StateClosed},
+				{err: errBackend, wantState: StateClosed},
+				{wantState: StateClosed},
+				{err: errBackend, wantState: StateClosed},
+				{err: errBackend, wantState: StateClosed},
+			},
+		},
+		{
+			name: "open until the timeout passes",
+			steps: []step{
+				{err: errBackend},
+				{err: errBackend},
+				{err: errBackend, wantState: StateOpen},
+				{advance: 59 * time.Second, wantOpen: true, wantState: StateOpen},
+				{advance: time.Second, wantState: StateClosed},
+			},
+		},
+		{
+			name: "probe success closes",
+			steps: []step{
+				{err: errBackend},
+				{err: errBackend},
+				{err: errBackend, wantState: StateOpen},
+				{advance: time.Minute, wantState: StateClosed},
+				{err: errBackend, wantState: StateClosed},
+			},
+		},
+		{
+			name: "probe failure reopens for another timeout",
+			steps: []step{
+				{err: errBackend},
+				{err: errBackend},
+				{err: errBackend, wantState: StateOpen},
+				{advance: time.Minute, err: errBackend, wantState: StateOpen},
+				{advance: 30 * time.Second, wantOpen: true, wantState: StateOpen},
+				{advance: 30 * time.Second, wantState: StateClosed},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, clock := newTestBreaker(3)
+			for i, s := range tt.steps {
+				clock.now = clock.now.Add(s.advance)
+				called := false
+				err := b.Do(func() error {
+					called = true
+					return s.err
+				})
+				if gotOpen := errors.Is(err, ErrOpen); gotOpen != s.wantOpen {
+					t.Fatalf("step %d: Do() error = %v, want open %v", i, err, s.wantOpen)
+				}
+				if called == s.wantOpen {
+					t.Fatalf("step %d: fn called = %v with open %v", i, called, s.wantOpen)
+				}
+				if got := b.State(); got != s.wantState {
+					t.Fatalf("step %d: State() = %v, want %v", i, got, s.wantState)
+				}
+			}
+		})
+	}
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	tests := []struct {
+		name string
+		// staleErr is the outcome of a call started before the breaker opened that
+		// finishes while the probe is in flight
+		staleErr  error
+		probeErr  error
+		wantState State
+	}{
+		{name: "probe success closes", probeErr: nil, wantState: StateClosed},
+		{name: "probe failure reopens", probeErr: errBackend, wantState: StateOpen},
+		{name: "stale success leaves the probe in charge", staleErr: nil, probeErr: errBackend, wantState: StateOpen},
+		{name: "stale failure leaves the probe in charge", staleErr: errBackend, probeErr: nil, wantState: StateClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, clock := newTestBreaker(1)
+
+			staleGeneration, err := b.allow()
+			if err != nil {
+				t.Fatalf("allow() error = %v", err)
+			}
+			if err := b.Do(func() error { return errBackend }); !errors.Is(err, errBackend) {
+				t.Fatalf("Do() error = %v, want %v", err, errBackend)
+			}
+			clock.now = clock.now.Add(time.Minute)
+
+			probeGeneration, err := b.allow()
+			if err != nil {
+				t.Fatalf("probe allow() error = %v", err)
+			}
+			if got := b.State(); got != StateHalfOpen {
+				t.Fatalf("State() = %v, want %v", got, StateHalfOpen)
+			}
+
+			b.record(staleGeneration, tt.staleErr)
+			if got := b.State(); got != StateHalfOpen {
+				t.Fatalf("State() after stale call = %v, want %v", got, StateHalfOpen)
+			}
+
+			called := false
+			if err := b.Do(func() error { called = true; return nil }); !errors.Is(err, ErrOpen) || called {
+				t.Fatalf("second call while probing: Do() error = %v, called %v; want ErrOpen without a call", err, called)
+			}
+
+			b.record(probeGeneration, tt.probeErr)
+			if got := b.State(); got != tt.wantState {
+				t.Fatalf("State() after probe = %v, want %v", got, tt.wantState)
+			}
+		})
+	}
+}
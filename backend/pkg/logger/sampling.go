@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"context"
+	"hash/fnv"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Request log sampling: with a sample rate of N, 1 in N requests writes its high-volume
+// info logs and the rest only write warnings and errors. The decision hashes the request
+// ID, so a sampled-in request logs consistently end to end, including background work
+// it starts.
+
+var sampleRate atomic.Int64
+
+func init() {
+	sampleRate.Store(1)
+}
+
+// SetSampleRate sets N for request log sampling; 1 or less logs every request in full
+func SetSampleRate(rate int) {
+	if rate < 1 {
+		rate = 1
+	}
+	sampleRate.Store(int64(rate))
+}
+
+type sampledInKey struct{}
+
+// WithRequestSampling records on ctx whether the request with requestID is sampled in.
+// A decision already on ctx is kept, so nested calls agree with the outermost one.
+func WithRequestSampling(ctx context.Context, requestID string) context.Context {
+	if _, decided := ctx.Value(sampledInKey{}).(bool); decided {
+		return ctx
+	}
+	rate := sampleRate.Load()
+	if rate <= 1 || requestID == "" {
+		return ctx
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(requestID))
+	return context.WithValue(ctx, sampledInKey{}, int64(hash.Sum32())%rate == 0)
+}
+
+// WithSamplingFrom copies the sampling decision of src onto dst, for work a request
+// hands to a context of its own
+func WithSamplingFrom(dst, src context.Context) context.Context {
+	if sampledIn, decided := src.Value(sampledInKey{}).(bool); decided {
+		return context.WithValue(dst, sampledInKey{}, sampledIn)
+	}
+	return dst
+}
+
+// Sampled returns l for a request that is sampled in or undecided, and l restricted to
+// warnings and errors for one that is sampled out
+func Sampled(ctx context.Context, l *zap.Logger) *zap.Logger {
+	if sampledIn, decided := ctx.Value(sampledInKey{}).(bool); !decided || sampledIn {
+		return l
+	}
+	if !l.Core().Enabled(zapcore.InfoLevel) {
+		return l
+	}
+	return l.WithOptions(zap.IncreaseLevel(zapcore.WarnLevel))
+}
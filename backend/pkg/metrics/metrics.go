@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Vector retrieval outcomes
+const (
+	RetrievalSucceeded        = "succeeded"
+	RetrievalTransientFailure = "transient_failure"
+	RetrievalDegraded         = "degraded"
+)
+
+// Sources of the concepts a query was processed with
+const (
+	ConceptSourceLLM            = "llm"
+	ConceptSourceVectorFallback = "vector_fallback"
+	ConceptSourceFailed         = "failed"
+)
+
+// Outcomes of background scrape jobs triggered by queries
+const (
+	ScrapeJobStarted = "started"
+	ScrapeJobSkipped = "skipped"
+)
+
+// Vector store search modes; keyword is the BM25 fallback used when the vectorizer fails
+const (
+	SearchModeVector  = "vector"
+	SearchModeKeyword = "keyword"
+)
+
+// Prompt budget actions taken before calling the LLM
+const (
+	PromptTrimmed  = "trimmed"
+	PromptRejected = "rejected"
+)
+
+var (
+	// VectorRetrievals counts vector search outcomes in the query pipeline
+	VectorRetrievals = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mathprereq",
+		Subsystem: "query",
+		Name:      "vector_retrievals_total",
+		Help:      "Vector retrievals by outcome (succeeded, transient_failure, degraded).",
+	}, []string{"outcome"})
+
+	// ConceptExtractions counts how concepts were identified for each query
+	ConceptExtractions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mathprereq",
+		Subsystem: "query",
+		Name:      "concept_extractions_total",
+		Help:      "Concept extractions by source (llm, vector_fallback, failed).",
+	}, []string{"source"})
+
+	// PipelineStepRetries counts query pipeline steps re-run after a failure
+	PipelineStepRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mathprereq",
+		Subsystem: "query",
+		Name:      "step_retries_total",
+		Help:      "Query pipeline step retries by step name.",
+	}, []string{"step"})
+
+	// QueryStepDuration records how long each recorded query pipeline step took
+	QueryStepDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "mathprereq",
+		Subsystem: "query",
+		Name:      "step_duration_seconds",
+		Help:      "Duration of query pipeline steps by step name and outcome.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"step", "success"})
+
+	// QueryDuration records end-to-end query latency
+	QueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "mathprereq",
+		Subsystem: "query",
+		Name:      "duration_seconds",
+		Help:      "End-to-end query processing latency by outcome.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"success"})
+
+	// BackgroundScrapeJobs counts query-triggered scrape jobs started or skipped at the limit
+	BackgroundScrapeJobs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mathprereq",
+		Subsystem: "scraper",
+		Name:      "background_jobs_total",
+		Help:      "Query-triggered background scrape jobs by outcome (started, skipped) and trigger.",
+	}, []string{"outcome", "trigger"})
+
+	// BackgroundScrapesInFlight tracks query-triggered scrape jobs currently running
+	BackgroundScrapesInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "mathprereq",
+		Subsystem: "scraper",
+		Name:      "background_jobs_in_flight",
+		Help:      "Query-triggered background scrape jobs currently running.",
+	})
+
+	// LLMPromptTokens records the estimated prompt size sent to the LLM
+	LLMPromptTokens = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "mathprereq",
+		Subsystem: "llm",
+		Name:      "prompt_tokens_estimated",
+		Help:      "Estimated prompt tokens per LLM call by operation.",
+		Buckets:   prometheus.ExponentialBuckets(256, 2, 12),
+	}, []string{"operation"})
+
+	// LLMPromptBudgetActions counts prompts trimmed or rejected for exceeding the context window
+	LLMPromptBudgetActions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mathprereq",
+		Subsystem: "llm",
+		Name:      "prompt_budget_actions_total",
+		Help:      "Prompts trimmed or rejected to fit the model context window.",
+	}, []string{"action"})
+
+	// VectorizerFailures counts Weaviate operations that failed in the vectorizer upstream
+	VectorizerFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mathprereq",
+		Subsystem: "weaviate",
+		Name:      "vectorizer_failures_total",
+		Help:      "Operations failed by the vectorizer upstream, by operation (search, ingest).",
+	}, []string{"operation"})
+
+	// VectorSearches counts semantic searches by the mode that served them
+	VectorSearches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mathprereq",
+		Subsystem: "weaviate",
+		Name:      "searches_total",
+		Help:      "Searches by mode (vector, keyword) and success.",
+	}, []string{"mode", "success"})
+
+	// PendingIngestChunks tracks chunks queued for re-ingestion after a vectorizer failure
+	PendingIngestChunks = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "mathprereq",
+		Subsystem: "weaviate",
+		Name:      "pending_ingest_chunks",
+		Help:      "Content chunks queued for re-ingestion after a vectorizer failure.",
+	})
+
+	// BreakerState tracks each backend's circuit breaker: 0 closed, 1 open, 2 half-open
+	BreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "mathprereq",
+		Subsystem: "backend",
+		Name:      "circuit_breaker_state",
+		Help:      "Circuit breaker state per backend: 0 closed, 1 open, 2 half-open.",
+	}, []string{"backend"})
+
+	// BreakerTransitions counts circuit breaker state changes by backend and new state
+	BreakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mathprereq",
+		Subsystem: "backend",
+		Name:      "circuit_breaker_transitions_total",
+		Help:      "Circuit breaker state changes by backend and new state.",
+	}, []string{"backend", "state"})
+)
@@ -25,6 +25,8 @@ export interface Concept {
   name: string;
   description: string;
   type: ConceptType;
+  // Every role in a result, e.g. a target that is also another target's prerequisite
+  roles?: ConceptType[];
   difficulty_level?: DifficultyLevel;
   subject_area?: string;
   tags?: string[];
@@ -37,7 +39,7 @@ export interface Concept {
   };
 }
 
-export type ConceptType = 'prerequisite' | 'target' | 'related' | 'foundation' | 'advanced';
+export type ConceptType = 'prerequisite' | 'target' | 'next_concept' | 'concept' | 'related' | 'foundation' | 'advanced';
 export type DifficultyLevel = 'beginner' | 'intermediate' | 'advanced' | 'expert';
 
 export interface LearningPath {